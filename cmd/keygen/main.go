@@ -0,0 +1,70 @@
+// Command keygen membuat key baru untuk signing JWT di JWT_KEYS_DIR.
+//
+//	go run ./cmd/keygen -dir ./keys -alg EdDSA
+//
+// Nama file (tanpa .pem) menjadi kid. Lihat utils.KeyRing untuk prosedur
+// rotasi key.
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func main() {
+	dir := flag.String("dir", "keys", "directory to write the key to")
+	alg := flag.String("alg", "EdDSA", "signing algorithm: RS256 or EdDSA")
+	kid := flag.String("kid", "", "key ID (default: <alg>-<timestamp>)")
+	flag.Parse()
+
+	if *kid == "" {
+		*kid = fmt.Sprintf("%s-%s", *alg, time.Now().UTC().Format("20060102150405"))
+	}
+
+	var (
+		key any
+		err error
+	)
+	switch *alg {
+	case "RS256":
+		key, err = rsa.GenerateKey(rand.Reader, 3072)
+	case "EdDSA":
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		log.Fatalf("Unsupported algorithm %q", *alg)
+	}
+	if err != nil {
+		log.Fatalf("Failed to generate key: %v", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		log.Fatalf("Failed to encode key: %v", err)
+	}
+
+	if err := os.MkdirAll(*dir, 0o700); err != nil {
+		log.Fatalf("Failed to create key directory: %v", err)
+	}
+
+	path := filepath.Join(*dir, *kid+".pem")
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		log.Fatalf("Failed to create key file: %v", err)
+	}
+	defer file.Close()
+
+	if err := pem.Encode(file, &pem.Block{Type: "PRIVATE KEY", Bytes: der}); err != nil {
+		log.Fatalf("Failed to write key: %v", err)
+	}
+
+	fmt.Printf("Created %s (kid %s)\n", path, *kid)
+}
@@ -1,8 +1,12 @@
 package main
 
 import (
+	"context"
+
 	"github.com/anieswahdie1/ara-medika-api.git/internal/configs"
 	"github.com/anieswahdie1/ara-medika-api.git/internal/controllers"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/jobs"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/mailer"
 	"github.com/anieswahdie1/ara-medika-api.git/internal/repositories"
 	"github.com/anieswahdie1/ara-medika-api.git/internal/routes"
 	"github.com/anieswahdie1/ara-medika-api.git/internal/services"
@@ -29,22 +33,80 @@ func main() {
 		logger.Fatalf("Failed to connect to redis: %v", err)
 	}
 
+	// Load JWT signing keys
+	if err := utils.InitKeyRing(cfg); err != nil {
+		logger.Fatalf("Failed to load JWT keys: %v", err)
+	}
+
+	// Setup password hashing
+	if err := utils.InitPasswordHasher(cfg); err != nil {
+		logger.Fatalf("Failed to setup password hasher: %v", err)
+	}
+
+	// Setup mailer
+	mail, err := mailer.NewMailer(cfg)
+	if err != nil {
+		logger.Fatalf("Failed to setup mailer: %v", err)
+	}
+	if cfg.MailDriver != mailer.DriverSMTP {
+		logger.Warn("Mail driver is outbox: emails are written to disk instead of being sent")
+	}
+
 	// Auto migrate models
 	// db.AutoMigrate(&entities.User{}, &entities.MasterData{}, ...)
 
 	// Initialize repositories
 	userRepo := repositories.NewUserRepository(db)
+	mfaRepo := repositories.NewMFARepository(db)
+	apiKeyRepo := repositories.NewAPIKeyRepository(db)
+	auditLogRepo := repositories.NewAuditLogRepository(db)
+	invitationRepo := repositories.NewInvitationRepository(db)
+	passwordHistoryRepo := repositories.NewPasswordHistoryRepository(db)
+	roleRepo := repositories.NewRoleRepository(db)
+	menuRepo := repositories.NewMenuRepository(db)
+	staffProfileRepo := repositories.NewStaffProfileRepository(db)
 
 	// Initialize services
-	userService := services.NewUserService(userRepo, logger)
-	authService := services.NewAuthService(userRepo, redisClient, cfg, logger)
+	passwordPolicy, err := services.NewPasswordPolicyService(userRepo, passwordHistoryRepo, cfg, logger)
+	if err != nil {
+		logger.Fatalf("Failed to setup password policy: %v", err)
+	}
+	roleService := services.NewRoleService(roleRepo, redisClient, cfg, logger)
+	menuService := services.NewMenuService(menuRepo, roleRepo, redisClient, cfg, logger)
+	mfaService := services.NewMFAService(userRepo, mfaRepo, redisClient, cfg, logger)
+	loginLimiter := services.NewLoginLimiter(redisClient, cfg, logger)
+	authService := services.NewAuthService(userRepo, mfaService, loginLimiter, passwordPolicy, roleService, redisClient, cfg, logger)
+	userService := services.NewUserService(userRepo, passwordPolicy, authService, roleService, cfg, logger)
+	passwordResetService := services.NewPasswordResetService(userRepo, authService, passwordPolicy, mail, redisClient, cfg, logger)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo, userRepo, roleService, logger)
+	auditService := services.NewAuditService(auditLogRepo, logger)
+	accessPolicy, err := services.NewAccessPolicyService(auditService, roleService, cfg, logger)
+	if err != nil {
+		logger.Fatalf("Failed to load access policies: %v", err)
+	}
+	invitationService := services.NewInvitationService(invitationRepo, userRepo, passwordPolicy, roleService, mail, cfg, logger)
+	staffProfileService := services.NewStaffProfileService(staffProfileRepo, userRepo, roleRepo, mail, cfg, logger)
+	userImportService := services.NewUserImportService(userRepo, roleService, invitationService, redisClient, cfg, logger)
 
 	// Initialize controllers
-	userController := controllers.NewUserController(userService, logger)
-	authController := controllers.NewAuthController(authService, userService, logger)
+	userController := controllers.NewUserController(userService, passwordResetService, accessPolicy, auditService, logger)
+	userImportController := controllers.NewUserImportController(userImportService, logger)
+	authController := controllers.NewAuthController(authService, userService, menuService, accessPolicy, cfg, logger)
+	sessionController := controllers.NewSessionController(authService, userService, accessPolicy, logger)
+	mfaController := controllers.NewMFAController(mfaService, logger)
+	passwordController := controllers.NewPasswordController(passwordResetService, logger)
+	apiKeyController := controllers.NewAPIKeyController(apiKeyService, logger)
+	oauthController := controllers.NewOAuthController(authService, logger)
+	impersonationController := controllers.NewImpersonationController(authService, auditService, cfg, logger)
+	auditController := controllers.NewAuditController(auditService, logger)
+	invitationController := controllers.NewInvitationController(invitationService, logger)
+	roleController := controllers.NewRoleController(roleService, logger)
+	menuController := controllers.NewMenuController(menuService, logger)
+	staffProfileController := controllers.NewStaffProfileController(staffProfileService, cfg, logger)
 
 	// Initialize validator
 	validators.Init() // Ini akan menginisialisasi validators.Validate
+	validators.SetRoleValidator(roleService.RoleExists)
 
 	// Setup router
 	router := routes.InitRouter(
@@ -52,9 +114,34 @@ func main() {
 		redisClient,
 		logger,
 		userController,
+		userImportController,
 		authController,
+		sessionController,
+		mfaController,
+		passwordController,
+		apiKeyController,
+		oauthController,
+		impersonationController,
+		auditController,
+		invitationController,
+		roleController,
+		menuController,
+		staffProfileController,
+		apiKeyService,
+		roleService,
+		auditService,
 	)
 
+	// Start background jobs
+	scheduler := jobs.NewScheduler(redisClient, logger)
+	if cfg.UserPurgeRetention > 0 {
+		scheduler.Add(jobs.NewUserPurgeJob(userService, auditService, cfg, logger))
+	}
+	if cfg.LicenseExpiryWarningDays > 0 {
+		scheduler.Add(jobs.NewLicenseExpiryJob(staffProfileService, cfg, logger))
+	}
+	scheduler.Start(context.Background())
+
 	// Start server
 	logger.Infof("Server is running on port %s", cfg.AppPort)
 	if err := router.Run(":" + cfg.AppPort); err != nil {
@@ -3,6 +3,8 @@ package configs
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -24,6 +26,108 @@ type Config struct {
 	JWTSecret        string
 	JWTExpire        time.Duration
 	JWTRefreshExpire time.Duration
+
+	// Signing JWT asimetris (RS256/EdDSA). Jika JWTKeysDir kosong, JWTSecret
+	// dipakai untuk HS256.
+	JWTKeysDir           string
+	JWTActiveKID         string
+	JWTAcceptLegacyHS256 bool
+
+	// Client credentials (client_id -> client_secret) untuk service internal
+	// yang memakai /oauth/introspect dan /oauth/revoke
+	OAuthClients map[string]string
+
+	// Masa berlaku token impersonasi super admin
+	ImpersonationExpire time.Duration
+
+	// Batas jumlah session aktif per role. 0 berarti tidak dibatasi.
+	SessionMaxDefault int
+	SessionMaxPerRole map[string]int
+
+	// Lama permission per role disimpan di cache Redis
+	PermissionCacheExpire time.Duration
+
+	// Lama menu per role disimpan di cache Redis
+	MenuCacheExpire time.Duration
+
+	// File JSON berisi access policy tambahan selain policy bawaan di kode
+	// (lihat services.AccessPolicyService)
+	AccessPolicyFile string
+
+	// Two-factor authentication (TOTP)
+	MFAIssuer          string
+	MFAEncryptionKey   string
+	MFARequiredRoles   []string
+	MFAChallengeExpire time.Duration
+
+	// Alamat/CIDR reverse proxy yang boleh menentukan IP client lewat header
+	// X-Forwarded-For (TRUSTED_PROXIES, dipisah koma). Jika kosong, header
+	// tersebut diabaikan dan IP client diambil dari koneksi langsung. IP ini
+	// dipakai untuk limit login per IP, session, API key dan audit log.
+	TrustedProxies []string
+
+	// URL frontend, dipakai untuk membentuk tautan di email
+	AppBaseURL string
+
+	// Mailer
+	MailDriver    string
+	MailFrom      string
+	MailOutboxDir string
+	SMTPHost      string
+	SMTPPort      string
+	SMTPUsername  string
+	SMTPPassword  string
+
+	PasswordResetExpire time.Duration
+	InvitationExpire    time.Duration
+
+	// Import user massal dari CSV/XLSX. Laporan hasil import bisa diunduh
+	// selama UserImportReportExpire.
+	UserImportMaxRows      int
+	UserImportReportExpire time.Duration
+
+	// User yang dihapus masuk trash dan dihapus permanen setelah
+	// UserPurgeRetention. Pengecekan berjalan setiap UserPurgeInterval;
+	// retention 0 mematikan purge otomatis.
+	UserPurgeRetention time.Duration
+	UserPurgeInterval  time.Duration
+
+	// Admin diberi tahu lewat email saat STR/SIP tenaga kesehatan akan habis
+	// dalam LicenseExpiryWarningDays hari. Pengecekan berjalan setiap
+	// LicenseCheckInterval.
+	LicenseExpiryWarningDays int
+	LicenseCheckInterval     time.Duration
+
+	// Kebijakan password. PasswordMinClasses adalah jumlah jenis karakter
+	// (huruf besar, huruf kecil, angka, simbol) yang wajib ada. PasswordMaxAge 0
+	// berarti password tidak pernah kedaluwarsa. PasswordBlocklistFile berisi
+	// daftar password bocor tambahan (satu per baris) selain daftar bawaan.
+	PasswordMinLength     int
+	PasswordMinClasses    int
+	PasswordHistorySize   int
+	PasswordMaxAge        time.Duration
+	PasswordBlocklistFile string
+
+	// Algoritma hash password untuk hash baru (argon2id atau bcrypt). Hash lama
+	// dengan algoritma/parameter lain di-hash ulang saat user berhasil login.
+	// Argon2Memory dalam KiB.
+	PasswordHashAlgorithm string
+	Argon2Memory          int
+	Argon2Iterations      int
+	Argon2Parallelism     int
+	BcryptCost            int
+
+	// Proteksi brute-force login. Setelah LoginDelayAfter kegagalan, percobaan
+	// berikutnya harus menunggu LoginBaseDelay yang berlipat ganda setiap gagal
+	// (maksimal LoginMaxDelay). Setelah LoginMaxAttempts kegagalan dalam
+	// LoginAttemptWindow, email dikunci selama LoginLockoutDuration.
+	LoginMaxAttempts      int
+	LoginMaxAttemptsPerIP int
+	LoginAttemptWindow    time.Duration
+	LoginLockoutDuration  time.Duration
+	LoginDelayAfter       int
+	LoginBaseDelay        time.Duration
+	LoginMaxDelay         time.Duration
 }
 
 func LoadConfig() *Config {
@@ -33,6 +137,7 @@ func LoadConfig() *Config {
 
 	jwtExpire, _ := time.ParseDuration(os.Getenv("JWT_EXPIRE"))
 	jwtRefreshExpire, _ := time.ParseDuration(os.Getenv("JWT_REFRESH_EXPIRE"))
+	sessionMaxDefault, _ := strconv.Atoi(os.Getenv("SESSION_MAX_DEFAULT"))
 
 	return &Config{
 		AppPort:          os.Getenv("APP_PORT"),
@@ -50,5 +155,153 @@ func LoadConfig() *Config {
 		JWTSecret:        os.Getenv("JWT_SECRET"),
 		JWTExpire:        jwtExpire,
 		JWTRefreshExpire: jwtRefreshExpire,
+
+		JWTKeysDir:           os.Getenv("JWT_KEYS_DIR"),
+		JWTActiveKID:         os.Getenv("JWT_ACTIVE_KID"),
+		JWTAcceptLegacyHS256: os.Getenv("JWT_ACCEPT_LEGACY_HS256") == "true",
+
+		OAuthClients: parseClientCredentials(os.Getenv("OAUTH_CLIENTS")),
+
+		ImpersonationExpire: getEnvDuration("IMPERSONATION_EXPIRE", 15*time.Minute),
+
+		SessionMaxDefault: sessionMaxDefault,
+		SessionMaxPerRole: parseRoleLimits(os.Getenv("SESSION_MAX_PER_ROLE")),
+
+		PermissionCacheExpire: getEnvDuration("PERMISSION_CACHE_EXPIRE", 10*time.Minute),
+		MenuCacheExpire:       getEnvDuration("MENU_CACHE_EXPIRE", time.Hour),
+		AccessPolicyFile:      os.Getenv("ACCESS_POLICY_FILE"),
+
+		MFAIssuer:          getEnv("MFA_ISSUER", "Ara Medika"),
+		MFAEncryptionKey:   os.Getenv("MFA_ENCRYPTION_KEY"),
+		MFARequiredRoles:   parseList(os.Getenv("MFA_REQUIRED_ROLES")),
+		MFAChallengeExpire: getEnvDuration("MFA_CHALLENGE_EXPIRE", 5*time.Minute),
+
+		TrustedProxies: parseList(os.Getenv("TRUSTED_PROXIES")),
+
+		AppBaseURL: strings.TrimRight(os.Getenv("APP_BASE_URL"), "/"),
+
+		MailDriver:    os.Getenv("MAIL_DRIVER"),
+		MailFrom:      os.Getenv("MAIL_FROM"),
+		MailOutboxDir: os.Getenv("MAIL_OUTBOX_DIR"),
+		SMTPHost:      os.Getenv("SMTP_HOST"),
+		SMTPPort:      os.Getenv("SMTP_PORT"),
+		SMTPUsername:  os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:  os.Getenv("SMTP_PASSWORD"),
+
+		PasswordResetExpire: getEnvDuration("PASSWORD_RESET_EXPIRE", 30*time.Minute),
+		InvitationExpire:    getEnvDuration("INVITATION_EXPIRE", 72*time.Hour),
+
+		UserImportMaxRows:      getEnvInt("USER_IMPORT_MAX_ROWS", 500),
+		UserImportReportExpire: getEnvDuration("USER_IMPORT_REPORT_EXPIRE", 24*time.Hour),
+
+		UserPurgeRetention: getEnvDuration("USER_PURGE_RETENTION", 30*24*time.Hour),
+		UserPurgeInterval:  getEnvDuration("USER_PURGE_INTERVAL", 24*time.Hour),
+
+		LicenseExpiryWarningDays: getEnvInt("LICENSE_EXPIRY_WARNING_DAYS", 60),
+		LicenseCheckInterval:     getEnvDuration("LICENSE_CHECK_INTERVAL", 24*time.Hour),
+
+		PasswordMinLength:     getEnvInt("PASSWORD_MIN_LENGTH", 8),
+		PasswordMinClasses:    getEnvInt("PASSWORD_MIN_CLASSES", 4),
+		PasswordHistorySize:   getEnvInt("PASSWORD_HISTORY_SIZE", 5),
+		PasswordMaxAge:        getEnvDuration("PASSWORD_MAX_AGE", 0),
+		PasswordBlocklistFile: os.Getenv("PASSWORD_BLOCKLIST_FILE"),
+
+		PasswordHashAlgorithm: getEnv("PASSWORD_HASH_ALGORITHM", "argon2id"),
+		Argon2Memory:          getEnvInt("ARGON2_MEMORY", 64*1024),
+		Argon2Iterations:      getEnvInt("ARGON2_ITERATIONS", 3),
+		Argon2Parallelism:     getEnvInt("ARGON2_PARALLELISM", 2),
+		BcryptCost:            getEnvInt("BCRYPT_COST", 10),
+
+		LoginMaxAttempts:      getEnvInt("LOGIN_MAX_ATTEMPTS", 5),
+		LoginMaxAttemptsPerIP: getEnvInt("LOGIN_MAX_ATTEMPTS_PER_IP", 50),
+		LoginAttemptWindow:    getEnvDuration("LOGIN_ATTEMPT_WINDOW", 15*time.Minute),
+		LoginLockoutDuration:  getEnvDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+		LoginDelayAfter:       getEnvInt("LOGIN_DELAY_AFTER", 3),
+		LoginBaseDelay:        getEnvDuration("LOGIN_BASE_DELAY", time.Second),
+		LoginMaxDelay:         getEnvDuration("LOGIN_MAX_DELAY", 30*time.Second),
+	}
+}
+
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// IsMFARequired menentukan apakah role wajib memakai two-factor authentication
+func (c *Config) IsMFARequired(role string) bool {
+	for _, required := range c.MFARequiredRoles {
+		if required == role {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxSessionsForRole mengembalikan batas session aktif untuk role tertentu
+func (c *Config) MaxSessionsForRole(role string) int {
+	if max, ok := c.SessionMaxPerRole[role]; ok {
+		return max
+	}
+	return c.SessionMaxDefault
+}
+
+// parseList membaca daftar yang dipisahkan koma, misalnya "super_admin,admin"
+func parseList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseRoleLimits membaca format "super_admin=1,admin=3,user=5"
+func parseRoleLimits(value string) map[string]int {
+	limits := make(map[string]int)
+	for _, item := range strings.Split(value, ",") {
+		role, limit, found := strings.Cut(strings.TrimSpace(item), "=")
+		if !found {
+			continue
+		}
+
+		n, err := strconv.Atoi(strings.TrimSpace(limit))
+		if err != nil {
+			log.Printf("Invalid session limit for role %s: %v", role, err)
+			continue
+		}
+		limits[strings.TrimSpace(role)] = n
+	}
+	return limits
+}
+
+// parseClientCredentials membaca format "patient-portal:secret1,reporting:secret2"
+func parseClientCredentials(value string) map[string]string {
+	clients := make(map[string]string)
+	for _, item := range strings.Split(value, ",") {
+		clientID, secret, found := strings.Cut(strings.TrimSpace(item), ":")
+		if !found || clientID == "" || secret == "" {
+			continue
+		}
+		clients[clientID] = secret
 	}
+	return clients
 }
@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	stderrors "errors"
+	"net/http"
+	"strconv"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/errors"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/responses"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type APIKeyController struct {
+	apiKeyService services.APIKeyService
+	logger        *logrus.Logger
+}
+
+func NewAPIKeyController(apiKeyService services.APIKeyService, logger *logrus.Logger) *APIKeyController {
+	return &APIKeyController{
+		apiKeyService: apiKeyService,
+		logger:        logger,
+	}
+}
+
+// CreateAPIKey godoc
+// @Summary Create API key
+// @Description Create an API key acting on behalf of a (service) user. The owner must be the caller or a user whose role the caller may grant. The full key is only returned once.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body entities.APIKeyCreateRequest true "API key data"
+// @Success 201 {object} responses.APIKeyCreatedResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 403 {object} errors.APIError
+// @Failure 404 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /admin/api-keys [post]
+func (c *APIKeyController) CreateAPIKey(ctx *gin.Context) {
+	var req entities.APIKeyCreateRequest
+	if !bindAndValidate(ctx, &req) {
+		return
+	}
+
+	createdBy := ctx.MustGet("userID").(uint)
+	creatorRole := entities.Role(ctx.GetString("role"))
+
+	apiKey, rawKey, err := c.apiKeyService.Create(createdBy, creatorRole, req)
+	if err != nil {
+		switch {
+		case stderrors.Is(err, services.ErrInvalidAPIKeyScope):
+			ctx.Error(errors.NewBadRequestError(errors.CodeValidationFailed, err.Error(), entities.APIKeyScopes))
+		case err == services.ErrAPIKeyOwnerNotAllowed:
+			ctx.Error(errors.NewForbiddenError(errors.CodeForbidden, err.Error()))
+		case err.Error() == "user not found":
+			ctx.Error(errors.NewNotFoundError(errors.CodeNotFound, "User not found"))
+		case err == services.ErrAPIKeyExpiryInPast:
+			ctx.Error(errors.NewBadRequestError(errors.CodeValidationFailed, err.Error(), nil))
+		default:
+			ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, "Failed to create API key"))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, responses.Responses{
+		Code:        http.StatusCreated,
+		Description: "CREATED",
+		Data: responses.APIKeyCreatedResponse{
+			APIKeyResponse: toAPIKeyResponse(*apiKey),
+			Key:            rawKey,
+		},
+	})
+}
+
+// ListAPIKeys godoc
+// @Summary List API keys
+// @Description List API keys, optionally filtered by owner
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query int false "Owner user ID"
+// @Success 200 {array} responses.APIKeyResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /admin/api-keys [get]
+func (c *APIKeyController) ListAPIKeys(ctx *gin.Context) {
+	var userID uint
+	if value := ctx.Query("user_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			ctx.Error(errors.NewBadRequestError(errors.CodeInvalidRequest, "Invalid user ID", nil))
+			return
+		}
+		userID = uint(id)
+	}
+
+	apiKeys, err := c.apiKeyService.List(userID)
+	if err != nil {
+		ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, "Failed to list API keys"))
+		return
+	}
+
+	data := make([]responses.APIKeyResponse, 0, len(apiKeys))
+	for _, apiKey := range apiKeys {
+		data = append(data, toAPIKeyResponse(apiKey))
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data:        data,
+	})
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke API key
+// @Description Revoke an API key immediately
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "API key ID"
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 404 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /admin/api-keys/{id} [delete]
+func (c *APIKeyController) RevokeAPIKey(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(errors.NewBadRequestError(errors.CodeInvalidRequest, "Invalid API key ID", nil))
+		return
+	}
+
+	if err := c.apiKeyService.Revoke(uint(id)); err != nil {
+		if err == services.ErrAPIKeyNotFound {
+			ctx.Error(errors.NewNotFoundError(errors.CodeNotFound, err.Error()))
+			return
+		}
+		ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, "Failed to revoke API key"))
+		return
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"api_key_id": id,
+		"revoked_by": ctx.MustGet("userID"),
+	}).Info("API key revoked by admin")
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data: responses.SuccessResponse{
+			Message: "API key revoked",
+		},
+	})
+}
+
+func toAPIKeyResponse(apiKey entities.APIKey) responses.APIKeyResponse {
+	return responses.APIKeyResponse{
+		ID:         apiKey.ID,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		UserID:     apiKey.UserID,
+		Scopes:     apiKey.ScopeList(),
+		ExpiresAt:  apiKey.ExpiresAt,
+		LastUsedAt: apiKey.LastUsedAt,
+		LastUsedIP: apiKey.LastUsedIP,
+		RevokedAt:  apiKey.RevokedAt,
+		CreatedBy:  apiKey.CreatedBy,
+		CreatedAt:  apiKey.CreatedAt,
+	}
+}
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/errors"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/requests"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/responses"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type AuditController struct {
+	auditService services.AuditService
+	logger       *logrus.Logger
+}
+
+func NewAuditController(auditService services.AuditService, logger *logrus.Logger) *AuditController {
+	return &AuditController{
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// ListAuditLogs godoc
+// @Summary List audit logs
+// @Description List audit trail entries, newest first
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page"
+// @Param limit query int false "Page size (max 100)"
+// @Param actor_id query int false "Actor user ID"
+// @Param impersonator_id query int false "Impersonator user ID"
+// @Param action query string false "Action, e.g. impersonation.start"
+// @Param target_type query string false "Target type"
+// @Param target_id query string false "Target ID"
+// @Param from query string false "From (RFC 3339)"
+// @Param to query string false "To (RFC 3339)"
+// @Success 200 {array} entities.AuditLog
+// @Failure 400 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /admin/audit-logs [get]
+func (c *AuditController) ListAuditLogs(ctx *gin.Context) {
+	var req requests.AuditLogListRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(errors.NewBadRequestError(errors.CodeInvalidRequest, "Invalid query parameters", err.Error()))
+		return
+	}
+
+	logs, err := c.auditService.List(req)
+	if err != nil {
+		ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, "Failed to list audit logs"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data:        logs,
+	})
+}
@@ -1,34 +1,47 @@
 package controllers
 
 import (
+	stderrors "errors"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 
+	"github.com/anieswahdie1/ara-medika-api.git/internal/configs"
 	"github.com/anieswahdie1/ara-medika-api.git/internal/errors"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
 	"github.com/anieswahdie1/ara-medika-api.git/internal/models/responses"
 	"github.com/anieswahdie1/ara-medika-api.git/internal/services"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
 
 type AuthController struct {
-	authService services.AuthService
-	userService services.UserService
-	logger      *logrus.Logger
+	authService  services.AuthService
+	userService  services.UserService
+	menuService  services.MenuService
+	accessPolicy services.AccessPolicyService
+	cfg          *configs.Config
+	logger       *logrus.Logger
 }
 
-func NewAuthController(authService services.AuthService, userService services.UserService, logger *logrus.Logger) *AuthController {
+func NewAuthController(authService services.AuthService, userService services.UserService, menuService services.MenuService, accessPolicy services.AccessPolicyService, cfg *configs.Config, logger *logrus.Logger) *AuthController {
 	return &AuthController{
-		authService: authService,
-		userService: userService,
-		logger:      logger,
+		authService:  authService,
+		userService:  userService,
+		menuService:  menuService,
+		accessPolicy: accessPolicy,
+		cfg:          cfg,
+		logger:       logger,
 	}
 }
 
 type LoginRequest struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=8"`
+	Email      string `json:"email" binding:"required,email"`
+	Password   string `json:"password" binding:"required,min=8"`
+	DeviceName string `json:"device_name" binding:"max=100"`
 }
 
 // @Summary Login user
@@ -38,8 +51,11 @@ type LoginRequest struct {
 // @Produce json
 // @Param input body LoginRequest true "Login credentials"
 // @Success 200 {object} responses.TokenResponse
+// @Success 202 {object} responses.MFAChallengeResponse
+// @Success 202 {object} responses.PasswordChangeRequiredResponse
 // @Failure 400 {object} responses.ErrorResponse
 // @Failure 401 {object} responses.ErrorResponse
+// @Failure 429 {object} errors.APIError
 // @Failure 500 {object} responses.ErrorResponse
 // @Router /auth/login [post]
 func (c *AuthController) Login(ctx *gin.Context) {
@@ -55,27 +71,229 @@ func (c *AuthController) Login(ctx *gin.Context) {
 		return
 	}
 
-	accessToken, refreshToken, err := c.authService.Login(req.Email, req.Password)
+	meta := entities.SessionMeta{
+		DeviceName: req.DeviceName,
+		IPAddress:  ctx.ClientIP(),
+		UserAgent:  ctx.Request.UserAgent(),
+	}
+
+	result, err := c.authService.Login(req.Email, req.Password, meta)
 	if err != nil {
 		c.logger.Warnf("Login failed for email %s: %v", req.Email, err)
+		if c.abortIfThrottled(ctx, err) {
+			return
+		}
+
 		ctx.JSON(http.StatusUnauthorized, responses.ErrorResponse{
 			Error: "Invalid email or password",
 		})
 		return
 	}
 
-	token := responses.TokenResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+	c.respondLoginResult(ctx, result)
+}
+
+type LoginMFARequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required,min=6,max=20"`
+}
+
+// LoginMFA godoc
+// @Summary Complete two-factor login
+// @Description Exchange the MFA token from /auth/login and a TOTP or recovery code for JWT tokens
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body LoginMFARequest true "MFA token and code"
+// @Success 200 {object} responses.TokenResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 401 {object} errors.APIError
+// @Failure 429 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /auth/login/mfa [post]
+func (c *AuthController) LoginMFA(ctx *gin.Context) {
+	var req LoginMFARequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(errors.NewBadRequestError(
+			errors.CodeInvalidRequest,
+			"Invalid request format",
+			err.Error(),
+		))
+		return
+	}
+
+	result, err := c.authService.VerifyLoginMFA(req.MFAToken, req.Code)
+	if err != nil {
+		if c.abortIfThrottled(ctx, err) {
+			return
+		}
+		ctx.Error(mfaError(err))
+		return
+	}
+
+	c.respondLoginResult(ctx, result)
+}
+
+type LoginMFASetupRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+}
+
+// LoginMFASetup godoc
+// @Summary Start two-factor enrollment during login
+// @Description For roles that require two-factor authentication, generate a TOTP secret using the MFA token from /auth/login
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body LoginMFASetupRequest true "MFA token"
+// @Success 200 {object} entities.MFAEnrollment
+// @Failure 400 {object} errors.APIError
+// @Failure 401 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /auth/login/mfa/setup [post]
+func (c *AuthController) LoginMFASetup(ctx *gin.Context) {
+	var req LoginMFASetupRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(errors.NewBadRequestError(
+			errors.CodeInvalidRequest,
+			"Invalid request format",
+			err.Error(),
+		))
+		return
+	}
+
+	enrollment, err := c.authService.BeginLoginMFAEnrollment(req.MFAToken)
+	if err != nil {
+		ctx.Error(mfaError(err))
+		return
 	}
 
 	ctx.JSON(http.StatusOK, responses.Responses{
 		Code:        http.StatusOK,
 		Description: "SUCCESS",
-		Data:        token,
+		Data:        enrollment,
 	})
 }
 
+// LoginPasswordChange godoc
+// @Summary Change an expired password during login
+// @Description Exchange the password change token from /auth/login and a new password that satisfies the password policy for JWT tokens
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body entities.LoginPasswordChangeRequest true "Password change token and new password"
+// @Success 200 {object} responses.TokenResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 401 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /auth/login/password-change [post]
+func (c *AuthController) LoginPasswordChange(ctx *gin.Context) {
+	var req entities.LoginPasswordChangeRequest
+	if !bindAndValidate(ctx, &req) {
+		return
+	}
+
+	result, err := c.authService.CompleteLoginPasswordChange(req.PasswordChangeToken, req.NewPassword)
+	if err != nil {
+		if abortIfPasswordRejected(ctx, err) {
+			return
+		}
+		if err == services.ErrLoginChallengeInvalid {
+			ctx.Error(errors.NewUnauthorizedError(errors.CodeUnauthorized, err.Error()))
+			return
+		}
+		c.logger.Errorf("Password change during login failed: %v", err)
+		ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, "Failed to change password"))
+		return
+	}
+
+	c.respondLoginResult(ctx, result)
+}
+
+// abortIfThrottled menulis respons 429 beserta header Retry-After jika login
+// ditolak oleh proteksi brute-force
+func (c *AuthController) abortIfThrottled(ctx *gin.Context, err error) bool {
+	var throttled *services.LoginThrottledError
+	if !stderrors.As(err, &throttled) {
+		return false
+	}
+
+	retryAfter := int64(math.Ceil(throttled.RetryAfter.Seconds()))
+	ctx.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
+
+	message := "Too many failed login attempts, please try again later"
+	if throttled.Locked {
+		message = "Account is temporarily locked due to too many failed login attempts"
+	}
+
+	ctx.Error(errors.NewTooManyRequestsError(
+		errors.CodeTooManyRequests,
+		message,
+		gin.H{"retry_after": retryAfter, "locked": throttled.Locked},
+	))
+	return true
+}
+
+func (c *AuthController) respondLoginResult(ctx *gin.Context, result *services.LoginResult) {
+	if result.MFARequired {
+		ctx.JSON(http.StatusAccepted, responses.Responses{
+			Code:        http.StatusAccepted,
+			Description: "MFA_REQUIRED",
+			Data: responses.MFAChallengeResponse{
+				MFARequired:        true,
+				EnrollmentRequired: result.MFAEnrollmentRequired,
+				MFAToken:           result.MFAToken,
+				ExpiresIn:          int64(c.cfg.MFAChallengeExpire.Seconds()),
+			},
+		})
+		return
+	}
+
+	if result.PasswordChangeRequired {
+		ctx.JSON(http.StatusAccepted, responses.Responses{
+			Code:        http.StatusAccepted,
+			Description: "PASSWORD_CHANGE_REQUIRED",
+			Data: responses.PasswordChangeRequiredResponse{
+				PasswordChangeRequired: true,
+				PasswordChangeToken:    result.PasswordChangeToken,
+				ExpiresIn:              int64(c.cfg.MFAChallengeExpire.Seconds()),
+				RecoveryCodes:          result.RecoveryCodes,
+			},
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data: responses.TokenResponse{
+			AccessToken:   result.AccessToken,
+			RefreshToken:  result.RefreshToken,
+			RecoveryCodes: result.RecoveryCodes,
+		},
+	})
+}
+
+// JWKS godoc
+// @Summary JSON Web Key Set
+// @Description Public keys used to verify access and refresh tokens, selected by the kid header
+// @Tags auth
+// @Produce json
+// @Success 200 {object} utils.JWKSet
+// @Failure 500 {object} errors.APIError
+// @Router /.well-known/jwks.json [get]
+func (c *AuthController) JWKS(ctx *gin.Context) {
+	jwks, err := utils.CurrentJWKS()
+	if err != nil {
+		c.logger.Errorf("Failed to build JWKS: %v", err)
+		ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, "Failed to load signing keys"))
+		return
+	}
+
+	// Format JWKS mengikuti RFC 7517, tidak dibungkus responses.Responses
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, jwks)
+}
+
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
 }
@@ -222,7 +440,7 @@ func (c *AuthController) GetCurrentUser(ctx *gin.Context) {
 		return
 	}
 
-	menus, err := c.userService.ListMenus(string(user.Role))
+	menus, err := c.menuService.ListForRole(string(user.Role))
 	if err != nil {
 		c.logger.Errorf("Failed to get menus: %v", err)
 
@@ -236,16 +454,73 @@ func (c *AuthController) GetCurrentUser(ctx *gin.Context) {
 	}
 
 	// Format response (tampilkan hanya data yang diperlukan)
+	data := responses.UserResponse{
+		ID:         user.ID,
+		Name:       user.Name,
+		Email:      user.Email,
+		Role:       string(user.Role),
+		AccessMenu: toMenuTree(menus, false),
+		CreatedAt:  user.CreatedAt,
+	}
+
+	if impersonatorID, ok := ctx.Get("impersonatorID"); ok {
+		data.Impersonated = true
+		data.ImpersonatedBy = &responses.ImpersonatorResponse{
+			ID:    impersonatorID.(uint),
+			Email: ctx.GetString("impersonatorEmail"),
+		}
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data:        data,
+	})
+}
+
+// UnlockUser godoc
+// @Summary Unlock user login
+// @Description Clear the brute-force lockout and failed login counter of a user whose role the caller may grant (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param userID path int true "User ID"
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 401 {object} errors.APIError
+// @Failure 403 {object} errors.APIError
+// @Failure 404 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /admin/users/{userID}/unlock [post]
+func (c *AuthController) UnlockUser(ctx *gin.Context) {
+	// Target diperiksa seperti /users/:id agar admin tidak bisa membuka
+	// lock akun dengan role lebih tinggi yang sedang diserang
+	user, ok := loadTargetUser(ctx, c.userService, c.accessPolicy, "userID", entities.PermissionUsersWrite)
+	if !ok {
+		return
+	}
+	userID := user.ID
+
+	if err := c.authService.UnlockAccount(userID); err != nil {
+		if err.Error() == "user not found" {
+			ctx.Error(errors.NewNotFoundError(errors.CodeNotFound, "User not found"))
+			return
+		}
+		c.logger.Errorf("Failed to unlock user %d: %v", userID, err)
+		ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, "Failed to unlock user"))
+		return
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"user_id":     userID,
+		"unlocked_by": ctx.MustGet("userID"),
+	}).Info("User login lock cleared by admin")
+
 	ctx.JSON(http.StatusOK, responses.Responses{
 		Code:        http.StatusOK,
 		Description: "SUCCESS",
-		Data: responses.UserResponse{
-			ID:         user.ID,
-			Name:       user.Name,
-			Email:      user.Email,
-			Role:       string(user.Role),
-			AccessMenu: menus,
-			CreatedAt:  user.CreatedAt,
+		Data: responses.SuccessResponse{
+			Message: "User unlocked",
 		},
 	})
 }
@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/configs"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/errors"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/middlewares"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/responses"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type ImpersonationController struct {
+	authService  services.AuthService
+	auditService services.AuditService
+	cfg          *configs.Config
+	logger       *logrus.Logger
+}
+
+func NewImpersonationController(authService services.AuthService, auditService services.AuditService, cfg *configs.Config, logger *logrus.Logger) *ImpersonationController {
+	return &ImpersonationController{
+		authService:  authService,
+		auditService: auditService,
+		cfg:          cfg,
+		logger:       logger,
+	}
+}
+
+type ImpersonateRequest struct {
+	Reason string `json:"reason" binding:"max=255"`
+}
+
+// Impersonate godoc
+// @Summary Impersonate a user
+// @Description Issue a short-lived access token that acts as another user (super_admin only). The token carries the original actor in the act claim and has no refresh token.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param userID path int true "User ID to impersonate"
+// @Param input body ImpersonateRequest false "Reason for impersonation"
+// @Success 200 {object} responses.ImpersonationResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 403 {object} errors.APIError
+// @Failure 404 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /admin/impersonate/{userID} [post]
+func (c *ImpersonationController) Impersonate(ctx *gin.Context) {
+	targetID, ok := parseUserIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	var req ImpersonateRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.Error(errors.NewBadRequestError(errors.CodeInvalidRequest, "Invalid request format", err.Error()))
+			return
+		}
+	}
+
+	actorID := ctx.MustGet("userID").(uint)
+	meta := entities.SessionMeta{
+		IPAddress: ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+	}
+
+	result, err := c.authService.Impersonate(actorID, targetID, meta)
+	if err != nil {
+		switch {
+		case err == services.ErrImpersonationNotAllowed:
+			ctx.Error(errors.NewForbiddenError(errors.CodeForbidden, err.Error()))
+		case err.Error() == "user not found":
+			ctx.Error(errors.NewNotFoundError(errors.CodeNotFound, "User not found"))
+		default:
+			c.logger.Errorf("Failed to impersonate user %d: %v", targetID, err)
+			ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, "Failed to start impersonation"))
+		}
+		return
+	}
+
+	log := middlewares.AuditLogFromContext(ctx, entities.AuditImpersonationStart)
+	log.TargetType = "user"
+	log.TargetID = strconv.FormatUint(uint64(targetID), 10)
+	log.StatusCode = http.StatusOK
+	c.auditService.Record(log, map[string]any{
+		"reason":     req.Reason,
+		"session_id": result.SessionID,
+		"expires_at": result.ExpiresAt,
+	})
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data: responses.ImpersonationResponse{
+			AccessToken: result.AccessToken,
+			TokenType:   "Bearer",
+			ExpiresIn:   int64(c.cfg.ImpersonationExpire.Seconds()),
+			ExpiresAt:   result.ExpiresAt,
+			UserID:      result.User.ID,
+			Name:        result.User.Name,
+			Email:       result.User.Email,
+			Role:        string(result.User.Role),
+		},
+	})
+}
+
+// EndImpersonation godoc
+// @Summary End impersonation
+// @Description Revoke the impersonation token used for this request
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 401 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /auth/impersonation/end [post]
+func (c *ImpersonationController) EndImpersonation(ctx *gin.Context) {
+	if !middlewares.IsImpersonating(ctx) {
+		ctx.Error(errors.NewBadRequestError(errors.CodeInvalidRequest, "Not impersonating", nil))
+		return
+	}
+
+	userID := ctx.MustGet("userID").(uint)
+	sessionID := ctx.GetString("sessionID")
+
+	if err := c.authService.EndImpersonation(userID, sessionID); err != nil {
+		ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, "Failed to end impersonation"))
+		return
+	}
+
+	log := middlewares.AuditLogFromContext(ctx, entities.AuditImpersonationEnd)
+	log.TargetType = "user"
+	log.TargetID = strconv.FormatUint(uint64(userID), 10)
+	log.StatusCode = http.StatusOK
+	c.auditService.Record(log, map[string]any{"session_id": sessionID})
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data: responses.SuccessResponse{
+			Message: "Impersonation ended",
+		},
+	})
+}
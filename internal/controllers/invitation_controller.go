@@ -0,0 +1,211 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/errors"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/responses"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type InvitationController struct {
+	invitationService services.InvitationService
+	logger            *logrus.Logger
+}
+
+func NewInvitationController(invitationService services.InvitationService, logger *logrus.Logger) *InvitationController {
+	return &InvitationController{
+		invitationService: invitationService,
+		logger:            logger,
+	}
+}
+
+// InviteUser godoc
+// @Summary Invite user
+// @Description Create an inactive user and email them a signed, expiring link to set their password
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body entities.InvitationCreateRequest true "Invitee data"
+// @Success 201 {object} responses.InvitationSentResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 403 {object} errors.APIError
+// @Failure 409 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /admin/invitations [post]
+func (c *InvitationController) InviteUser(ctx *gin.Context) {
+	var req entities.InvitationCreateRequest
+	if !bindAndValidate(ctx, &req) {
+		return
+	}
+
+	invitedBy := ctx.MustGet("userID").(uint)
+	inviterRole := entities.Role(ctx.GetString("role"))
+
+	result, err := c.invitationService.Invite(invitedBy, inviterRole, req)
+	if err != nil {
+		switch err {
+		case services.ErrInvitationRoleNotAllowed:
+			ctx.Error(errors.NewForbiddenError(errors.CodeForbidden, err.Error()))
+		case services.ErrEmailAlreadyRegistered:
+			ctx.Error(errors.NewConflictError(errors.CodeConflict, err.Error()))
+		default:
+			ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, "Failed to create invitation"))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, responses.Responses{
+		Code:        http.StatusCreated,
+		Description: "CREATED",
+		Data: responses.InvitationSentResponse{
+			InvitationResponse: toInvitationResponse(*result.Invitation),
+			EmailSent:          result.EmailSent,
+		},
+	})
+}
+
+// ListInvitations godoc
+// @Summary List invitations
+// @Description List invitations. Without a status, all invitations that have not been accepted are returned.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "pending, expired or accepted"
+// @Success 200 {array} responses.InvitationResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /admin/invitations [get]
+func (c *InvitationController) ListInvitations(ctx *gin.Context) {
+	status := ctx.Query("status")
+	switch status {
+	case "", entities.InvitationPending, entities.InvitationExpired, entities.InvitationAccepted:
+	default:
+		ctx.Error(errors.NewBadRequestError(errors.CodeInvalidRequest, "Invalid invitation status", nil))
+		return
+	}
+
+	invitations, err := c.invitationService.List(status)
+	if err != nil {
+		ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, "Failed to list invitations"))
+		return
+	}
+
+	data := make([]responses.InvitationResponse, 0, len(invitations))
+	for _, invitation := range invitations {
+		data = append(data, toInvitationResponse(invitation))
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data:        data,
+	})
+}
+
+// ResendInvitation godoc
+// @Summary Resend invitation
+// @Description Email a new invitation link with a fresh expiry. Previously sent links stop working.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Invitation ID"
+// @Success 200 {object} responses.InvitationSentResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 404 {object} errors.APIError
+// @Failure 409 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /admin/invitations/{id}/resend [post]
+func (c *InvitationController) ResendInvitation(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(errors.NewBadRequestError(errors.CodeInvalidRequest, "Invalid invitation ID", nil))
+		return
+	}
+
+	result, err := c.invitationService.Resend(uint(id))
+	if err != nil {
+		switch err {
+		case services.ErrInvitationNotFound:
+			ctx.Error(errors.NewNotFoundError(errors.CodeNotFound, err.Error()))
+		case services.ErrInvitationAlreadyAccepted:
+			ctx.Error(errors.NewConflictError(errors.CodeConflict, err.Error()))
+		default:
+			ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, "Failed to resend invitation"))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data: responses.InvitationSentResponse{
+			InvitationResponse: toInvitationResponse(*result.Invitation),
+			EmailSent:          result.EmailSent,
+		},
+	})
+}
+
+// AcceptInvitation godoc
+// @Summary Accept invitation
+// @Description Set the account password using an invitation link. This activates the account and verifies its email.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body entities.AcceptInvitationRequest true "Invitation token and password"
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /auth/invitations/accept [post]
+func (c *InvitationController) AcceptInvitation(ctx *gin.Context) {
+	var req entities.AcceptInvitationRequest
+	if !bindAndValidate(ctx, &req) {
+		return
+	}
+
+	if err := c.invitationService.Accept(req.Token, req.Password); err != nil {
+		if abortIfPasswordRejected(ctx, err) {
+			return
+		}
+		if err == services.ErrInvitationInvalid {
+			ctx.Error(errors.NewBadRequestError(errors.CodeInvalidRequest, err.Error(), nil))
+			return
+		}
+		c.logger.Errorf("Accepting invitation failed: %v", err)
+		ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, "Failed to accept invitation"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data: responses.SuccessResponse{
+			Message: "Account activated, you can now log in",
+		},
+	})
+}
+
+func toInvitationResponse(invitation entities.UserInvitation) responses.InvitationResponse {
+	response := responses.InvitationResponse{
+		ID:         invitation.ID,
+		UserID:     invitation.UserID,
+		Status:     invitation.Status(),
+		InvitedBy:  invitation.InvitedBy,
+		ExpiresAt:  invitation.ExpiresAt,
+		AcceptedAt: invitation.AcceptedAt,
+		SentCount:  invitation.SentCount,
+		LastSentAt: invitation.LastSentAt,
+		CreatedAt:  invitation.CreatedAt,
+	}
+	if invitation.User != nil {
+		response.Name = invitation.User.Name
+		response.Email = invitation.User.Email
+		response.Role = string(invitation.User.Role)
+	}
+	return response
+}
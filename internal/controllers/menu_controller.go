@@ -0,0 +1,284 @@
+package controllers
+
+import (
+	stderrors "errors"
+	"net/http"
+	"strconv"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/errors"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/responses"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type MenuController struct {
+	menuService services.MenuService
+	logger      *logrus.Logger
+}
+
+func NewMenuController(menuService services.MenuService, logger *logrus.Logger) *MenuController {
+	return &MenuController{
+		menuService: menuService,
+		logger:      logger,
+	}
+}
+
+// ListMenus godoc
+// @Summary List menus
+// @Description List all menus as a tree ordered by priority, including the roles that can see each menu
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} responses.MenuResponse
+// @Failure 500 {object} errors.APIError
+// @Router /admin/menus [get]
+func (c *MenuController) ListMenus(ctx *gin.Context) {
+	menus, err := c.menuService.List()
+	if err != nil {
+		ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, "Failed to list menus"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data:        toMenuTree(menus, true),
+	})
+}
+
+// GetMenu godoc
+// @Summary Get menu
+// @Description Get a single menu without its submenus
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Menu ID"
+// @Success 200 {object} responses.MenuResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 404 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /admin/menus/{id} [get]
+func (c *MenuController) GetMenu(ctx *gin.Context) {
+	id, ok := parseMenuIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	menu, err := c.menuService.Get(id)
+	if err != nil {
+		abortMenuError(ctx, err, "Failed to get menu")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data:        toMenuResponse(*menu, true),
+	})
+}
+
+// CreateMenu godoc
+// @Summary Create menu
+// @Description Create a menu or, with parentId, a submenu
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body entities.MenuRequest true "Menu data"
+// @Success 201 {object} responses.MenuResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 409 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /admin/menus [post]
+func (c *MenuController) CreateMenu(ctx *gin.Context) {
+	var req entities.MenuRequest
+	if !bindAndValidate(ctx, &req) {
+		return
+	}
+
+	menu, err := c.menuService.Create(req)
+	if err != nil {
+		abortMenuError(ctx, err, "Failed to create menu")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, responses.Responses{
+		Code:        http.StatusCreated,
+		Description: "CREATED",
+		Data:        toMenuResponse(*menu, true),
+	})
+}
+
+// UpdateMenu godoc
+// @Summary Update menu
+// @Description Replace a menu, including its parent and the roles that can see it
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Menu ID"
+// @Param input body entities.MenuRequest true "Menu data"
+// @Success 200 {object} responses.MenuResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 404 {object} errors.APIError
+// @Failure 409 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /admin/menus/{id} [put]
+func (c *MenuController) UpdateMenu(ctx *gin.Context) {
+	id, ok := parseMenuIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	var req entities.MenuRequest
+	if !bindAndValidate(ctx, &req) {
+		return
+	}
+
+	menu, err := c.menuService.Update(id, req)
+	if err != nil {
+		abortMenuError(ctx, err, "Failed to update menu")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data:        toMenuResponse(*menu, true),
+	})
+}
+
+// DeleteMenu godoc
+// @Summary Delete menu
+// @Description Delete a menu together with all of its submenus
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Menu ID"
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 404 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /admin/menus/{id} [delete]
+func (c *MenuController) DeleteMenu(ctx *gin.Context) {
+	id, ok := parseMenuIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.menuService.Delete(id); err != nil {
+		abortMenuError(ctx, err, "Failed to delete menu")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data: responses.SuccessResponse{
+			Message: "Menu deleted",
+		},
+	})
+}
+
+// ReorderMenus godoc
+// @Summary Reorder menus
+// @Description Set the priority of several menus at once. Menus are shown in ascending priority within their parent.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body entities.MenuReorderRequest true "New priorities"
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 404 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /admin/menus/reorder [put]
+func (c *MenuController) ReorderMenus(ctx *gin.Context) {
+	var req entities.MenuReorderRequest
+	if !bindAndValidate(ctx, &req) {
+		return
+	}
+
+	if err := c.menuService.Reorder(req); err != nil {
+		abortMenuError(ctx, err, "Failed to reorder menus")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data: responses.SuccessResponse{
+			Message: "Menus reordered",
+		},
+	})
+}
+
+func parseMenuIDParam(ctx *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(errors.NewBadRequestError(errors.CodeInvalidRequest, "Invalid menu ID", nil))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func abortMenuError(ctx *gin.Context, err error, message string) {
+	switch {
+	case err == services.ErrMenuNotFound:
+		ctx.Error(errors.NewNotFoundError(errors.CodeNotFound, err.Error()))
+	case err == services.ErrMenuCodeExists:
+		ctx.Error(errors.NewConflictError(errors.CodeConflict, err.Error()))
+	case err == services.ErrMenuParentNotFound, err == services.ErrMenuParentCycle,
+		stderrors.Is(err, services.ErrMenuUnknownRole):
+		ctx.Error(errors.NewBadRequestError(errors.CodeValidationFailed, err.Error(), nil))
+	default:
+		ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, message))
+	}
+}
+
+// toMenuTree menyusun daftar menu datar (urut priority) menjadi tree. Menu
+// yang induknya tidak ada di daftar, misalnya karena induknya tidak boleh
+// dilihat role tersebut, ikut dibuang.
+func toMenuTree(menus []entities.Menus, includeRoles bool) []responses.MenuResponse {
+	children := make(map[uint][]entities.Menus)
+	var roots []entities.Menus
+	for _, menu := range menus {
+		if menu.ParentID == nil {
+			roots = append(roots, menu)
+		} else {
+			children[*menu.ParentID] = append(children[*menu.ParentID], menu)
+		}
+	}
+
+	var build func(items []entities.Menus) []responses.MenuResponse
+	build = func(items []entities.Menus) []responses.MenuResponse {
+		nodes := make([]responses.MenuResponse, 0, len(items))
+		for _, item := range items {
+			node := toMenuResponse(item, includeRoles)
+			node.Children = build(children[item.ID])
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+
+	return build(roots)
+}
+
+func toMenuResponse(menu entities.Menus, includeRoles bool) responses.MenuResponse {
+	response := responses.MenuResponse{
+		ID:       menu.ID,
+		ParentID: menu.ParentID,
+		Code:     menu.Code,
+		Name:     menu.Name,
+		Path:     menu.Path,
+		Icon:     menu.Icon,
+		Priority: menu.Priority,
+		Children: []responses.MenuResponse{},
+	}
+	if includeRoles {
+		response.Roles = menu.RoleNames()
+	}
+	return response
+}
@@ -0,0 +1,200 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/errors"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/responses"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type MFAController struct {
+	mfaService services.MFAService
+	logger     *logrus.Logger
+}
+
+func NewMFAController(mfaService services.MFAService, logger *logrus.Logger) *MFAController {
+	return &MFAController{
+		mfaService: mfaService,
+		logger:     logger,
+	}
+}
+
+// Status godoc
+// @Summary Get two-factor status
+// @Description Show whether two-factor authentication is enabled or required for the authenticated user
+// @Tags mfa
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} services.MFAStatus
+// @Failure 401 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /auth/mfa [get]
+func (c *MFAController) Status(ctx *gin.Context) {
+	userID := ctx.MustGet("userID").(uint)
+
+	status, err := c.mfaService.Status(userID)
+	if err != nil {
+		ctx.Error(mfaError(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data:        status,
+	})
+}
+
+// Enroll godoc
+// @Summary Start two-factor enrollment
+// @Description Generate a new TOTP secret and provisioning URI (render it as a QR code)
+// @Tags mfa
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} entities.MFAEnrollment
+// @Failure 400 {object} errors.APIError
+// @Failure 401 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /auth/mfa/enroll [post]
+func (c *MFAController) Enroll(ctx *gin.Context) {
+	userID := ctx.MustGet("userID").(uint)
+
+	enrollment, err := c.mfaService.BeginEnrollment(userID)
+	if err != nil {
+		ctx.Error(mfaError(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data:        enrollment,
+	})
+}
+
+// ConfirmEnrollment godoc
+// @Summary Confirm two-factor enrollment
+// @Description Verify the first TOTP code, enable two-factor authentication and return recovery codes
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body entities.MFAVerifyRequest true "TOTP code"
+// @Success 200 {object} responses.RecoveryCodesResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 401 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /auth/mfa/enroll/verify [post]
+func (c *MFAController) ConfirmEnrollment(ctx *gin.Context) {
+	req, ok := bindMFAVerifyRequest(ctx)
+	if !ok {
+		return
+	}
+	userID := ctx.MustGet("userID").(uint)
+
+	codes, err := c.mfaService.ConfirmEnrollment(userID, req.Code)
+	if err != nil {
+		ctx.Error(mfaError(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data:        responses.RecoveryCodesResponse{RecoveryCodes: codes},
+	})
+}
+
+// Disable godoc
+// @Summary Disable two-factor authentication
+// @Description Disable two-factor authentication (not allowed for roles where it is enforced)
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body entities.MFAVerifyRequest true "TOTP or recovery code"
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 401 {object} errors.APIError
+// @Failure 403 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /auth/mfa/disable [post]
+func (c *MFAController) Disable(ctx *gin.Context) {
+	req, ok := bindMFAVerifyRequest(ctx)
+	if !ok {
+		return
+	}
+	userID := ctx.MustGet("userID").(uint)
+
+	if err := c.mfaService.Disable(userID, req.Code); err != nil {
+		ctx.Error(mfaError(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data: responses.SuccessResponse{
+			Message: "Two-factor authentication disabled",
+		},
+	})
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate recovery codes
+// @Description Invalidate all existing recovery codes and issue a new set
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body entities.MFAVerifyRequest true "TOTP or recovery code"
+// @Success 200 {object} responses.RecoveryCodesResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 401 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /auth/mfa/recovery-codes [post]
+func (c *MFAController) RegenerateRecoveryCodes(ctx *gin.Context) {
+	req, ok := bindMFAVerifyRequest(ctx)
+	if !ok {
+		return
+	}
+	userID := ctx.MustGet("userID").(uint)
+
+	codes, err := c.mfaService.RegenerateRecoveryCodes(userID, req.Code)
+	if err != nil {
+		ctx.Error(mfaError(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data:        responses.RecoveryCodesResponse{RecoveryCodes: codes},
+	})
+}
+
+func bindMFAVerifyRequest(ctx *gin.Context) (*entities.MFAVerifyRequest, bool) {
+	var req entities.MFAVerifyRequest
+	if !bindAndValidate(ctx, &req) {
+		return nil, false
+	}
+	return &req, true
+}
+
+// mfaError memetakan error dari MFA service dan login challenge ke APIError
+func mfaError(err error) *errors.APIError {
+	switch err {
+	case services.ErrInvalidMFACode, services.ErrLoginChallengeInvalid:
+		return errors.NewUnauthorizedError(errors.CodeUnauthorized, err.Error())
+	case services.ErrMFAAlreadyEnabled, services.ErrMFANotEnabled, services.ErrMFAEnrollmentNotStarted:
+		return errors.NewBadRequestError(errors.CodeInvalidRequest, err.Error(), nil)
+	case services.ErrMFARequiredForRole:
+		return errors.NewForbiddenError(errors.CodeForbidden, err.Error())
+	default:
+		return errors.NewInternalServerError(errors.CodeInternalError, "Two-factor authentication failed")
+	}
+}
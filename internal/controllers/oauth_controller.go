@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// OAuthController menyediakan endpoint standar OAuth2 untuk service internal.
+// Request dan respons mengikuti format RFC, bukan responses.Responses.
+type OAuthController struct {
+	authService services.AuthService
+	logger      *logrus.Logger
+}
+
+func NewOAuthController(authService services.AuthService, logger *logrus.Logger) *OAuthController {
+	return &OAuthController{
+		authService: authService,
+		logger:      logger,
+	}
+}
+
+// Introspect godoc
+// @Summary Token introspection (RFC 7662)
+// @Description Check whether an access or refresh token is active, including blacklist and session revocation
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Security BasicAuth
+// @Param token formData string true "Token to introspect"
+// @Param token_type_hint formData string false "access_token or refresh_token"
+// @Success 200 {object} services.TokenIntrospection
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /oauth/introspect [post]
+func (c *OAuthController) Introspect(ctx *gin.Context) {
+	token, ok := requireTokenParam(ctx)
+	if !ok {
+		return
+	}
+
+	result, err := c.authService.IntrospectToken(token, ctx.PostForm("token_type_hint"))
+	if err != nil {
+		c.logger.Errorf("Token introspection failed: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+		return
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"client_id": ctx.GetString("oauthClientID"),
+		"active":    result.Active,
+	}).Debug("Token introspected")
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, result)
+}
+
+// Revoke godoc
+// @Summary Token revocation (RFC 7009)
+// @Description Revoke an access token, or a refresh token together with its session
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Security BasicAuth
+// @Param token formData string true "Token to revoke"
+// @Param token_type_hint formData string false "access_token or refresh_token"
+// @Success 200
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /oauth/revoke [post]
+func (c *OAuthController) Revoke(ctx *gin.Context) {
+	token, ok := requireTokenParam(ctx)
+	if !ok {
+		return
+	}
+
+	// Token yang tidak valid tetap dijawab 200 (RFC 7009 bagian 2.2)
+	if err := c.authService.RevokeToken(token, ctx.PostForm("token_type_hint")); err != nil {
+		c.logger.Errorf("Token revocation failed: %v", err)
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "server_error"})
+		return
+	}
+
+	c.logger.WithField("client_id", ctx.GetString("oauthClientID")).Info("Token revoked via OAuth endpoint")
+	ctx.Status(http.StatusOK)
+}
+
+func requireTokenParam(ctx *gin.Context) (string, bool) {
+	token := ctx.PostForm("token")
+	if token == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":             "invalid_request",
+			"error_description": "token parameter is required",
+		})
+		return "", false
+	}
+	return token, true
+}
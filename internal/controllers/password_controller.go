@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	stderrors "errors"
+	"net/http"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/errors"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/responses"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/services"
+	"github.com/anieswahdie1/ara-medika-api.git/pkg/validators"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type PasswordController struct {
+	passwordResetService services.PasswordResetService
+	logger               *logrus.Logger
+}
+
+func NewPasswordController(passwordResetService services.PasswordResetService, logger *logrus.Logger) *PasswordController {
+	return &PasswordController{
+		passwordResetService: passwordResetService,
+		logger:               logger,
+	}
+}
+
+// ForgotPassword godoc
+// @Summary Request a password reset
+// @Description Send a single-use password reset link to the given email if it is registered
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body entities.ForgotPasswordRequest true "Account email"
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /auth/password/forgot [post]
+func (c *PasswordController) ForgotPassword(ctx *gin.Context) {
+	var req entities.ForgotPasswordRequest
+	if !bindAndValidate(ctx, &req) {
+		return
+	}
+
+	if err := c.passwordResetService.RequestReset(req.Email); err != nil {
+		c.logger.Errorf("Password reset request failed: %v", err)
+		ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, "Failed to process password reset"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data: responses.SuccessResponse{
+			Message: "If the email is registered, a password reset link has been sent",
+		},
+	})
+}
+
+// ResetPassword godoc
+// @Summary Reset password
+// @Description Set a new password using a reset token. All existing sessions are revoked.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body entities.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /auth/password/reset [post]
+func (c *PasswordController) ResetPassword(ctx *gin.Context) {
+	var req entities.ResetPasswordRequest
+	if !bindAndValidate(ctx, &req) {
+		return
+	}
+
+	if err := c.passwordResetService.ResetPassword(req.Token, req.NewPassword); err != nil {
+		if abortIfPasswordRejected(ctx, err) {
+			return
+		}
+		if err == services.ErrPasswordResetTokenInvalid {
+			ctx.Error(errors.NewBadRequestError(errors.CodeInvalidRequest, err.Error(), nil))
+			return
+		}
+		c.logger.Errorf("Password reset failed: %v", err)
+		ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, "Failed to reset password"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data: responses.SuccessResponse{
+			Message: "Password has been reset, please log in again",
+		},
+	})
+}
+
+// abortIfPasswordRejected menulis respons 400 beserta daftar aturan yang
+// dilanggar jika password baru ditolak kebijakan password
+func abortIfPasswordRejected(ctx *gin.Context, err error) bool {
+	var policyErr *services.PasswordPolicyError
+	if !stderrors.As(err, &policyErr) {
+		return false
+	}
+
+	ctx.Error(errors.NewBadRequestError(
+		errors.CodeValidationFailed,
+		"Password does not meet the password policy",
+		gin.H{"password": policyErr.Violations},
+	))
+	return true
+}
+
+// bindAndValidate mem-bind body JSON lalu menjalankan validators.Validate.
+// Error ditulis ke context dan fungsi mengembalikan false jika gagal.
+func bindAndValidate(ctx *gin.Context, req any) bool {
+	if err := ctx.ShouldBindJSON(req); err != nil {
+		ctx.Error(errors.NewBadRequestError(
+			errors.CodeInvalidRequest,
+			"Invalid request format",
+			err.Error(),
+		))
+		return false
+	}
+
+	if err := validators.Validate.Struct(req); err != nil {
+		ctx.Error(errors.NewBadRequestError(
+			errors.CodeValidationFailed,
+			"Validation failed",
+			err.Error(),
+		))
+		return false
+	}
+
+	return true
+}
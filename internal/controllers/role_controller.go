@@ -0,0 +1,308 @@
+package controllers
+
+import (
+	stderrors "errors"
+	"net/http"
+	"strconv"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/errors"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/responses"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type RoleController struct {
+	roleService services.RoleService
+	logger      *logrus.Logger
+}
+
+func NewRoleController(roleService services.RoleService, logger *logrus.Logger) *RoleController {
+	return &RoleController{
+		roleService: roleService,
+		logger:      logger,
+	}
+}
+
+// ListRoles godoc
+// @Summary List roles
+// @Description List all roles with their permissions
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} responses.RoleResponse
+// @Failure 500 {object} errors.APIError
+// @Router /admin/roles [get]
+func (c *RoleController) ListRoles(ctx *gin.Context) {
+	roles, err := c.roleService.List()
+	if err != nil {
+		ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, "Failed to list roles"))
+		return
+	}
+
+	data := make([]responses.RoleResponse, 0, len(roles))
+	for _, role := range roles {
+		data = append(data, toRoleResponse(role))
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data:        data,
+	})
+}
+
+// GetRole godoc
+// @Summary Get role
+// @Description Get a role with its permissions
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Role ID"
+// @Success 200 {object} responses.RoleResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 404 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /admin/roles/{id} [get]
+func (c *RoleController) GetRole(ctx *gin.Context) {
+	id, ok := parseRoleIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	role, err := c.roleService.Get(id)
+	if err != nil {
+		abortRoleError(ctx, err, "Failed to get role")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data:        toRoleResponse(*role),
+	})
+}
+
+// CreateRole godoc
+// @Summary Create role
+// @Description Create a role with an optional initial set of permissions. The name cannot be changed later.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body entities.RoleCreateRequest true "Role data"
+// @Success 201 {object} responses.RoleResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 409 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /admin/roles [post]
+func (c *RoleController) CreateRole(ctx *gin.Context) {
+	var req entities.RoleCreateRequest
+	if !bindAndValidate(ctx, &req) {
+		return
+	}
+
+	role, err := c.roleService.Create(req)
+	if err != nil {
+		abortRoleError(ctx, err, "Failed to create role")
+		return
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"role":       role.Name,
+		"created_by": ctx.MustGet("userID"),
+	}).Info("Role created by admin")
+
+	ctx.JSON(http.StatusCreated, responses.Responses{
+		Code:        http.StatusCreated,
+		Description: "CREATED",
+		Data:        toRoleResponse(*role),
+	})
+}
+
+// UpdateRole godoc
+// @Summary Update role
+// @Description Update the description of a role
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Role ID"
+// @Param input body entities.RoleUpdateRequest true "Role data"
+// @Success 200 {object} responses.RoleResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 404 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /admin/roles/{id} [put]
+func (c *RoleController) UpdateRole(ctx *gin.Context) {
+	id, ok := parseRoleIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	var req entities.RoleUpdateRequest
+	if !bindAndValidate(ctx, &req) {
+		return
+	}
+
+	role, err := c.roleService.Update(id, req)
+	if err != nil {
+		abortRoleError(ctx, err, "Failed to update role")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data:        toRoleResponse(*role),
+	})
+}
+
+// DeleteRole godoc
+// @Summary Delete role
+// @Description Delete a custom role. System roles and roles still assigned to users cannot be deleted.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Role ID"
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 404 {object} errors.APIError
+// @Failure 409 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /admin/roles/{id} [delete]
+func (c *RoleController) DeleteRole(ctx *gin.Context) {
+	id, ok := parseRoleIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.roleService.Delete(id); err != nil {
+		abortRoleError(ctx, err, "Failed to delete role")
+		return
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"role_id":    id,
+		"deleted_by": ctx.MustGet("userID"),
+	}).Info("Role deleted by admin")
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data: responses.SuccessResponse{
+			Message: "Role deleted",
+		},
+	})
+}
+
+// SetRolePermissions godoc
+// @Summary Set role permissions
+// @Description Replace all permissions of a role. Takes effect on the next request of users with this role.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Role ID"
+// @Param input body entities.RolePermissionsRequest true "Permission names"
+// @Success 200 {object} responses.RoleResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 404 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /admin/roles/{id}/permissions [put]
+func (c *RoleController) SetRolePermissions(ctx *gin.Context) {
+	id, ok := parseRoleIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	var req entities.RolePermissionsRequest
+	if !bindAndValidate(ctx, &req) {
+		return
+	}
+
+	role, err := c.roleService.SetPermissions(id, req.Permissions)
+	if err != nil {
+		abortRoleError(ctx, err, "Failed to update role permissions")
+		return
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"role":        role.Name,
+		"permissions": role.PermissionNames(),
+		"updated_by":  ctx.MustGet("userID"),
+	}).Info("Role permissions updated by admin")
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data:        toRoleResponse(*role),
+	})
+}
+
+// ListPermissions godoc
+// @Summary List permissions
+// @Description List all permissions that can be assigned to roles
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} responses.PermissionResponse
+// @Failure 500 {object} errors.APIError
+// @Router /admin/permissions [get]
+func (c *RoleController) ListPermissions(ctx *gin.Context) {
+	permissions, err := c.roleService.ListPermissions()
+	if err != nil {
+		ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, "Failed to list permissions"))
+		return
+	}
+
+	data := make([]responses.PermissionResponse, 0, len(permissions))
+	for _, permission := range permissions {
+		data = append(data, responses.PermissionResponse{
+			ID:          permission.ID,
+			Name:        permission.Name,
+			Description: permission.Description,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data:        data,
+	})
+}
+
+func parseRoleIDParam(ctx *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(errors.NewBadRequestError(errors.CodeInvalidRequest, "Invalid role ID", nil))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func abortRoleError(ctx *gin.Context, err error, message string) {
+	switch {
+	case err == services.ErrRoleNotFound:
+		ctx.Error(errors.NewNotFoundError(errors.CodeNotFound, err.Error()))
+	case err == services.ErrRoleAlreadyExists, err == services.ErrSystemRole, err == services.ErrRoleInUse:
+		ctx.Error(errors.NewConflictError(errors.CodeConflict, err.Error()))
+	case err == services.ErrInvalidRoleName, stderrors.Is(err, services.ErrUnknownPermission):
+		ctx.Error(errors.NewBadRequestError(errors.CodeValidationFailed, err.Error(), nil))
+	default:
+		ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, message))
+	}
+}
+
+func toRoleResponse(role entities.Roles) responses.RoleResponse {
+	return responses.RoleResponse{
+		ID:          role.ID,
+		Name:        role.Name,
+		Description: role.Description,
+		IsSystem:    role.IsSystem,
+		Permissions: role.PermissionNames(),
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+}
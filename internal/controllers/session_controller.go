@@ -0,0 +1,213 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/errors"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/responses"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type SessionController struct {
+	authService  services.AuthService
+	userService  services.UserService
+	accessPolicy services.AccessPolicyService
+	logger       *logrus.Logger
+}
+
+func NewSessionController(authService services.AuthService, userService services.UserService, accessPolicy services.AccessPolicyService, logger *logrus.Logger) *SessionController {
+	return &SessionController{
+		authService:  authService,
+		userService:  userService,
+		accessPolicy: accessPolicy,
+		logger:       logger,
+	}
+}
+
+// ListSessions godoc
+// @Summary List active sessions
+// @Description List all active sessions (devices) of the authenticated user
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} responses.SessionResponse
+// @Failure 401 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /auth/sessions [get]
+func (c *SessionController) ListSessions(ctx *gin.Context) {
+	userID := ctx.MustGet("userID").(uint)
+	c.listSessions(ctx, userID, ctx.GetString("sessionID"))
+}
+
+// RevokeSession godoc
+// @Summary Revoke a session
+// @Description Log out one of the authenticated user's sessions
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 401 {object} errors.APIError
+// @Failure 404 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /auth/sessions/{id} [delete]
+func (c *SessionController) RevokeSession(ctx *gin.Context) {
+	userID := ctx.MustGet("userID").(uint)
+	c.revokeSession(ctx, userID, ctx.Param("id"))
+}
+
+// ListUserSessions godoc
+// @Summary List sessions of a user
+// @Description List all active sessions of a user whose role the caller may grant (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param userID path int true "User ID"
+// @Success 200 {array} responses.SessionResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 403 {object} responses.ErrorResponse
+// @Failure 404 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /admin/users/{userID}/sessions [get]
+func (c *SessionController) ListUserSessions(ctx *gin.Context) {
+	user, ok := c.loadUserForSessions(ctx)
+	if !ok {
+		return
+	}
+	c.listSessions(ctx, user.ID, "")
+}
+
+// RevokeUserSession godoc
+// @Summary Revoke a session of a user
+// @Description Revoke one session of a user whose role the caller may grant (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param userID path int true "User ID"
+// @Param id path string true "Session ID"
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 403 {object} errors.APIError
+// @Failure 404 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /admin/users/{userID}/sessions/{id} [delete]
+func (c *SessionController) RevokeUserSession(ctx *gin.Context) {
+	user, ok := c.loadUserForSessions(ctx)
+	if !ok {
+		return
+	}
+	c.revokeSession(ctx, user.ID, ctx.Param("id"))
+}
+
+// RevokeAllUserSessions godoc
+// @Summary Revoke all sessions of a user
+// @Description Log a user whose role the caller may grant out of every device (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param userID path int true "User ID"
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 403 {object} errors.APIError
+// @Failure 404 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /admin/users/{userID}/sessions [delete]
+func (c *SessionController) RevokeAllUserSessions(ctx *gin.Context) {
+	user, ok := c.loadUserForSessions(ctx)
+	if !ok {
+		return
+	}
+	userID := user.ID
+
+	if err := c.authService.RevokeAllSessions(userID); err != nil {
+		c.logger.Errorf("Failed to revoke sessions of user %d: %v", userID, err)
+		ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, "Failed to revoke sessions"))
+		return
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"user_id":    userID,
+		"revoked_by": ctx.MustGet("userID"),
+	}).Info("All sessions revoked by admin")
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data: responses.SuccessResponse{
+			Message: "All sessions revoked",
+		},
+	})
+}
+
+// loadUserForSessions memuat user dari :userID dan memastikan admin boleh
+// mengelola sesinya, sehingga sesi akun dengan role lebih tinggi tidak bisa
+// dilihat atau dicabut
+func (c *SessionController) loadUserForSessions(ctx *gin.Context) (*entities.Users, bool) {
+	return loadTargetUser(ctx, c.userService, c.accessPolicy, "userID", entities.PermissionSessionsManage)
+}
+
+func (c *SessionController) listSessions(ctx *gin.Context, userID uint, currentSessionID string) {
+	sessions, err := c.authService.ListSessions(userID)
+	if err != nil {
+		c.logger.Errorf("Failed to list sessions of user %d: %v", userID, err)
+		ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, "Failed to list sessions"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data:        toSessionResponses(sessions, currentSessionID),
+	})
+}
+
+func (c *SessionController) revokeSession(ctx *gin.Context, userID uint, sessionID string) {
+	if err := c.authService.RevokeSession(userID, sessionID); err != nil {
+		if err == services.ErrSessionNotFound {
+			ctx.Error(errors.NewNotFoundError(errors.CodeNotFound, "Session not found"))
+			return
+		}
+		c.logger.Errorf("Failed to revoke session %s of user %d: %v", sessionID, userID, err)
+		ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, "Failed to revoke session"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data: responses.SuccessResponse{
+			Message: "Session revoked",
+		},
+	})
+}
+
+func toSessionResponses(sessions []entities.Session, currentSessionID string) []responses.SessionResponse {
+	result := make([]responses.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, responses.SessionResponse{
+			ID:         session.ID,
+			DeviceName: session.DeviceName,
+			IPAddress:  session.IPAddress,
+			UserAgent:  session.UserAgent,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.ID == currentSessionID,
+		})
+	}
+	return result
+}
+
+// parseUserIDParam membaca path param :userID dan menulis error 400 jika tidak valid
+func parseUserIDParam(ctx *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Param("userID"), 10, 32)
+	if err != nil {
+		ctx.Error(errors.NewBadRequestError(errors.CodeInvalidRequest, "Invalid user ID", nil))
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,379 @@
+package controllers
+
+import (
+	stderrors "errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/configs"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/errors"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/requests"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/responses"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type StaffProfileController struct {
+	staffProfileService services.StaffProfileService
+	cfg                 *configs.Config
+	logger              *logrus.Logger
+}
+
+func NewStaffProfileController(staffProfileService services.StaffProfileService, cfg *configs.Config, logger *logrus.Logger) *StaffProfileController {
+	return &StaffProfileController{
+		staffProfileService: staffProfileService,
+		cfg:                 cfg,
+		logger:              logger,
+	}
+}
+
+// ListStaffProfiles godoc
+// @Summary List staff profiles
+// @Description List professional profiles of healthcare staff ordered by name. license_status=expiring returns profiles with an STR or SIP expiring within the warning period, expired returns profiles with an expired STR or SIP.
+// @Tags staff-profiles
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page"
+// @Param limit query int false "Page size (max 100)"
+// @Param search query string false "Search in name, email and specialty"
+// @Param profession query string false "doctor, dentist, nurse, midwife or pharmacist"
+// @Param license_status query string false "expiring or expired"
+// @Success 200 {object} responses.PageResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 403 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /staff-profiles [get]
+func (c *StaffProfileController) ListStaffProfiles(ctx *gin.Context) {
+	var request requests.StaffProfileListRequest
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		ctx.Error(errors.NewBadRequestError(errors.CodeInvalidRequest, "Invalid query parameters", err.Error()))
+		return
+	}
+
+	result, err := c.staffProfileService.List(request)
+	if err != nil {
+		if stderrors.Is(err, services.ErrInvalidStaffProfileQuery) {
+			ctx.Error(errors.NewBadRequestError(errors.CodeInvalidRequest, err.Error(), nil))
+			return
+		}
+		ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, "Failed to list staff profiles"))
+		return
+	}
+
+	items := make([]responses.StaffProfileResponse, 0, len(result.Profiles))
+	for i := range result.Profiles {
+		items = append(items, c.toStaffProfileResponse(&result.Profiles[i]))
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data: responses.PageResponse{
+			Items:   items,
+			Total:   &result.Total,
+			Page:    result.Page,
+			Limit:   result.Limit,
+			HasNext: int64(result.Page*result.Limit) < result.Total,
+		},
+	})
+}
+
+// GetMyStaffProfile godoc
+// @Summary Get own staff profile
+// @Description Get the professional profile of the authenticated user
+// @Tags staff-profiles
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} responses.StaffProfileResponse
+// @Failure 404 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /staff-profiles/me [get]
+func (c *StaffProfileController) GetMyStaffProfile(ctx *gin.Context) {
+	c.getStaffProfile(ctx, ctx.MustGet("userID").(uint))
+}
+
+// GetStaffProfile godoc
+// @Summary Get staff profile
+// @Description Get the professional profile of a user
+// @Tags staff-profiles
+// @Produce json
+// @Security BearerAuth
+// @Param userID path int true "User ID"
+// @Success 200 {object} responses.StaffProfileResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 403 {object} errors.APIError
+// @Failure 404 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /staff-profiles/{userID} [get]
+func (c *StaffProfileController) GetStaffProfile(ctx *gin.Context) {
+	userID, ok := parseUserIDParam(ctx)
+	if !ok {
+		return
+	}
+	c.getStaffProfile(ctx, userID)
+}
+
+func (c *StaffProfileController) getStaffProfile(ctx *gin.Context, userID uint) {
+	profile, err := c.staffProfileService.Get(userID)
+	if err != nil {
+		abortStaffProfileError(ctx, err, "Failed to get staff profile")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data:        c.toStaffProfileResponse(profile),
+	})
+}
+
+// CreateStaffProfile godoc
+// @Summary Create staff profile
+// @Description Create the professional profile of a user. STR numbers have 16 digits, NIP 18 digits and phone numbers must be Indonesian numbers. A SIP requires an STR and an expiry date; an STR without expiry date is valid for life.
+// @Tags staff-profiles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param userID path int true "User ID"
+// @Param input body entities.StaffProfileRequest true "Staff profile data"
+// @Success 201 {object} responses.StaffProfileResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 403 {object} errors.APIError
+// @Failure 404 {object} errors.APIError
+// @Failure 409 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /staff-profiles/{userID} [post]
+func (c *StaffProfileController) CreateStaffProfile(ctx *gin.Context) {
+	userID, ok := parseUserIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	var req entities.StaffProfileRequest
+	if !bindAndValidate(ctx, &req) {
+		return
+	}
+
+	profile, err := c.staffProfileService.Create(userID, req)
+	if err != nil {
+		abortStaffProfileError(ctx, err, "Failed to create staff profile")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, responses.Responses{
+		Code:        http.StatusCreated,
+		Description: "SUCCESS",
+		Data:        c.toStaffProfileResponse(profile),
+	})
+}
+
+// UpdateStaffProfile godoc
+// @Summary Update staff profile
+// @Description Replace the professional profile of a user. The signature is not changed. Changing a license number or expiry date allows the license to be reported again when it is about to expire.
+// @Tags staff-profiles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param userID path int true "User ID"
+// @Param input body entities.StaffProfileRequest true "Staff profile data"
+// @Success 200 {object} responses.StaffProfileResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 403 {object} errors.APIError
+// @Failure 404 {object} errors.APIError
+// @Failure 409 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /staff-profiles/{userID} [put]
+func (c *StaffProfileController) UpdateStaffProfile(ctx *gin.Context) {
+	userID, ok := parseUserIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	var req entities.StaffProfileRequest
+	if !bindAndValidate(ctx, &req) {
+		return
+	}
+
+	profile, err := c.staffProfileService.Update(userID, req)
+	if err != nil {
+		abortStaffProfileError(ctx, err, "Failed to update staff profile")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data:        c.toStaffProfileResponse(profile),
+	})
+}
+
+// DeleteStaffProfile godoc
+// @Summary Delete staff profile
+// @Description Delete the professional profile and signature of a user. The user account is not changed.
+// @Tags staff-profiles
+// @Produce json
+// @Security BearerAuth
+// @Param userID path int true "User ID"
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 403 {object} errors.APIError
+// @Failure 404 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /staff-profiles/{userID} [delete]
+func (c *StaffProfileController) DeleteStaffProfile(ctx *gin.Context) {
+	userID, ok := parseUserIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.staffProfileService.Delete(userID); err != nil {
+		abortStaffProfileError(ctx, err, "Failed to delete staff profile")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data:        "Staff profile deleted successfully",
+	})
+}
+
+// UploadSignature godoc
+// @Summary Upload signature
+// @Description Replace the signature image of a staff profile. The file must be a PNG or JPEG image of at most 512 KB and 2000x2000 pixels.
+// @Tags staff-profiles
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param userID path int true "User ID"
+// @Param file formData file true "PNG or JPEG image"
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 403 {object} errors.APIError
+// @Failure 404 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /staff-profiles/{userID}/signature [put]
+func (c *StaffProfileController) UploadSignature(ctx *gin.Context) {
+	userID, ok := parseUserIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.Error(errors.NewBadRequestError(errors.CodeInvalidRequest, "File is required", err.Error()))
+		return
+	}
+	if fileHeader.Size > services.MaxSignatureSize {
+		ctx.Error(errors.NewBadRequestError(errors.CodeInvalidRequest, "File is too large", gin.H{"max_bytes": services.MaxSignatureSize}))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		ctx.Error(errors.NewBadRequestError(errors.CodeInvalidRequest, "File could not be read", nil))
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, services.MaxSignatureSize))
+	if err != nil {
+		ctx.Error(errors.NewBadRequestError(errors.CodeInvalidRequest, "File could not be read", nil))
+		return
+	}
+
+	if err := c.staffProfileService.SetSignature(userID, data); err != nil {
+		abortStaffProfileError(ctx, err, "Failed to save signature")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data:        "Signature saved successfully",
+	})
+}
+
+// GetSignature godoc
+// @Summary Get signature
+// @Description Download the signature image of a staff profile
+// @Tags staff-profiles
+// @Produce image/png
+// @Produce image/jpeg
+// @Security BearerAuth
+// @Param userID path int true "User ID"
+// @Success 200 {file} file
+// @Failure 400 {object} errors.APIError
+// @Failure 403 {object} errors.APIError
+// @Failure 404 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /staff-profiles/{userID}/signature [get]
+func (c *StaffProfileController) GetSignature(ctx *gin.Context) {
+	userID, ok := parseUserIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	data, contentType, err := c.staffProfileService.Signature(userID)
+	if err != nil {
+		abortStaffProfileError(ctx, err, "Failed to load signature")
+		return
+	}
+
+	// Tanda tangan tidak boleh disimpan di cache bersama
+	ctx.Header("Cache-Control", "private, no-store")
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	ctx.Data(http.StatusOK, contentType, data)
+}
+
+func (c *StaffProfileController) toStaffProfileResponse(profile *entities.StaffProfile) responses.StaffProfileResponse {
+	response := responses.StaffProfileResponse{
+		UserID:       profile.UserID,
+		Profession:   string(profile.Profession),
+		Specialty:    profile.Specialty,
+		STRNumber:    profile.STRNumber,
+		STRExpiresAt: formatLicenseDate(profile.STRExpiresAt),
+		STRStatus:    profile.STRStatus(c.cfg.LicenseExpiryWarningDays),
+		SIPNumber:    profile.SIPNumber,
+		SIPExpiresAt: formatLicenseDate(profile.SIPExpiresAt),
+		SIPStatus:    profile.SIPStatus(c.cfg.LicenseExpiryWarningDays),
+		NIP:          profile.NIP,
+		Phone:        profile.Phone,
+		HasSignature: profile.HasSignature(),
+		CreatedAt:    profile.CreatedAt,
+		UpdatedAt:    profile.UpdatedAt,
+	}
+	if profile.User != nil {
+		response.Name = profile.User.Name
+		response.Email = profile.User.Email
+		response.Role = string(profile.User.Role)
+	}
+	return response
+}
+
+func formatLicenseDate(date *time.Time) *string {
+	if date == nil {
+		return nil
+	}
+	value := date.Format("2006-01-02")
+	return &value
+}
+
+func abortStaffProfileError(ctx *gin.Context, err error, message string) {
+	switch {
+	case err == services.ErrStaffProfileNotFound,
+		err == services.ErrStaffUserNotFound,
+		err == services.ErrSignatureNotFound:
+		ctx.Error(errors.NewNotFoundError(errors.CodeNotFound, err.Error()))
+	case err == services.ErrStaffProfileExists,
+		stderrors.Is(err, services.ErrLicenseNumberTaken):
+		ctx.Error(errors.NewConflictError(errors.CodeConflict, err.Error()))
+	case err == services.ErrInvalidSignature:
+		ctx.Error(errors.NewBadRequestError(errors.CodeInvalidRequest, err.Error(), nil))
+	default:
+		ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, message))
+	}
+}
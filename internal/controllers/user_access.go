@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"strconv"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/errors"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/middlewares"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// loadTargetUser mengambil user dari path param lalu memastikan access policy
+// mengizinkan action terhadapnya. Dipakai semua endpoint yang bertindak atas
+// akun user lain agar hierarki role berlaku sama. Respons error sudah ditulis
+// jika hasilnya false.
+func loadTargetUser(ctx *gin.Context, userService services.UserService, accessPolicy services.AccessPolicyService, param, action string) (*entities.Users, bool) {
+	id, err := strconv.ParseUint(ctx.Param(param), 10, 32)
+	if err != nil {
+		ctx.Error(errors.NewBadRequestError(errors.CodeInvalidRequest, "Invalid user ID", nil))
+		return nil, false
+	}
+
+	user, err := userService.GetUserByID(uint(id))
+	if err != nil {
+		if err.Error() == "user not found" {
+			ctx.Error(errors.NewNotFoundError(errors.CodeNotFound, "User not found"))
+			return nil, false
+		}
+		ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, "Failed to get user"))
+		return nil, false
+	}
+
+	if !authorizeUserAccess(ctx, accessPolicy, action, user) {
+		return nil, false
+	}
+	return user, true
+}
+
+// authorizeUserAccess mengevaluasi access policy untuk aksi terhadap akun
+// user lain dan menulis respons 403 jika ditolak
+func authorizeUserAccess(ctx *gin.Context, accessPolicy services.AccessPolicyService, action string, user *entities.Users) bool {
+	resource := services.AccessResource{
+		Type: "user",
+		ID:   strconv.FormatUint(uint64(user.ID), 10),
+		Attributes: map[string]any{
+			"role":   string(user.Role),
+			"active": user.Active,
+		},
+	}
+
+	err := accessPolicy.Authorize(middlewares.AccessRequestFromContext(ctx, action, resource))
+	return !abortIfAccessDenied(ctx, err)
+}
@@ -1,32 +1,41 @@
 package controllers
 
 import (
-	"fmt"
+	stderrors "errors"
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/anieswahdie1/ara-medika-api.git/internal/errors"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/middlewares"
 	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
 	"github.com/anieswahdie1/ara-medika-api.git/internal/models/requests"
 	"github.com/anieswahdie1/ara-medika-api.git/internal/models/responses"
 	"github.com/anieswahdie1/ara-medika-api.git/internal/services"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/spreadsheet"
 	"github.com/anieswahdie1/ara-medika-api.git/pkg/validators"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/sirupsen/logrus"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type UserController struct {
-	userService services.UserService
-	logger      *logrus.Logger
-	validator   *validator.Validate
+	userService          services.UserService
+	passwordResetService services.PasswordResetService
+	accessPolicy         services.AccessPolicyService
+	auditService         services.AuditService
+	logger               *logrus.Logger
+	validator            *validator.Validate
 }
 
-func NewUserController(userService services.UserService, logger *logrus.Logger) *UserController {
+func NewUserController(userService services.UserService, passwordResetService services.PasswordResetService, accessPolicy services.AccessPolicyService, auditService services.AuditService, logger *logrus.Logger) *UserController {
 	return &UserController{
-		userService: userService,
-		logger:      logger,
-		validator:   &validator.Validate{},
+		userService:          userService,
+		passwordResetService: passwordResetService,
+		accessPolicy:         accessPolicy,
+		auditService:         auditService,
+		logger:               logger,
+		validator:            &validator.Validate{},
 	}
 }
 
@@ -39,6 +48,8 @@ func NewUserController(userService services.UserService, logger *logrus.Logger)
 // @Param input body entities.UserCreateRequest true "User data"
 // @Success 201 {object} responses.SuccessResponse
 // @Failure 400 {object} responses.ErrorResponse
+// @Failure 403 {object} errors.APIError
+// @Failure 409 {object} errors.APIError
 // @Failure 500 {object} responses.ErrorResponse
 // @Router /users [post]
 func (controller *UserController) CreateUser(ctx *gin.Context) {
@@ -62,28 +73,32 @@ func (controller *UserController) CreateUser(ctx *gin.Context) {
 		return
 	}
 
-	// Hash Password dengan cost yang sesuai
-	hashed, err := bcrypt.GenerateFromPassword(
-		[]byte(req.Password),
-		bcrypt.DefaultCost, // atau bisa juga dengan bcrypt.MinCost untuk development
-	)
-	if err != nil {
-		fmt.Errorf("hashing failed: %w", err)
-		return
-	}
-
+	// Password di-hash oleh UserService.CreateUser
 	user := entities.Users{
 		Name:     req.Name,
 		Email:    req.Email,
-		Password: string(hashed),
+		Password: req.Password,
 		Role:     req.Role,
 		Active:   true,
 	}
-	if err := controller.userService.CreateUser(&user); err != nil {
+	creatorRole := entities.Role(ctx.GetString("role"))
+	if err := controller.userService.CreateUser(&user, creatorRole); err != nil {
 		controller.logger.Errorf("Failed to create user: %v", err)
+		if abortIfPasswordRejected(ctx, err) {
+			return
+		}
+		if err == services.ErrRoleNotAllowed {
+			ctx.Error(errors.NewForbiddenError(errors.CodeForbidden, err.Error()))
+			return
+		}
+		if err == services.ErrEmailAlreadyRegistered {
+			ctx.Error(errors.NewConflictError(errors.CodeConflict, err.Error()))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, responses.ErrorResponse{
 			Error: err.Error(),
 		})
+		return
 	}
 
 	ctx.JSON(http.StatusCreated, responses.SuccessResponse{
@@ -91,45 +106,141 @@ func (controller *UserController) CreateUser(ctx *gin.Context) {
 	})
 }
 
+// GetListUser godoc
+// @Summary List users
+// @Description List users with filters and sorting. Pass next_cursor as cursor to fetch the next page with keyset pagination; total and page are omitted in cursor mode.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page (ignored when cursor is set)"
+// @Param limit query int false "Page size (max 100)"
+// @Param search query string false "Search in name and email"
+// @Param role query []string false "Role, repeatable or comma separated"
+// @Param status query string false "active or inactive"
+// @Param created_from query string false "Created at or after (RFC 3339)"
+// @Param created_to query string false "Created before (RFC 3339)"
+// @Param sort query string false "Comma separated fields (id, name, email, role, created_at, updated_at), prefix with - for descending. Default -created_at"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Success 200 {object} responses.PageResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /users [get]
 func (controller *UserController) GetListUser(ctx *gin.Context) {
-	var (
-		request  requests.BaseGetListRequest
-		listUser []responses.GetUsers = make([]responses.GetUsers, 0)
-	)
-
+	var request requests.UserListRequest
 	if err := ctx.ShouldBindQuery(&request); err != nil {
-		controller.logger.Errorf(
-			"Failed to bind request: %v", err,
-		)
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request payload",
-		})
+		ctx.Error(errors.NewBadRequestError(errors.CodeInvalidRequest, "Invalid query parameters", err.Error()))
 		return
 	}
 
-	users, err := controller.userService.ListUsers(request)
+	result, err := controller.userService.ListUsers(request)
 	if err != nil {
-		controller.logger.Errorf("Failed to get user: %v", err)
-		ctx.JSON(http.StatusInternalServerError, responses.ErrorResponse{
-			Error: err.Error(),
-		})
+		if stderrors.Is(err, services.ErrInvalidListQuery) {
+			ctx.Error(errors.NewBadRequestError(errors.CodeInvalidRequest, err.Error(), nil))
+			return
+		}
+		ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, "Failed to list users"))
 		return
 	}
 
-	if len(users) > 0 {
-		for _, user := range users {
-			listUser = append(listUser, responses.GetUsers{
-				Name:   user.Name,
-				Email:  user.Email,
-				Role:   string(user.Role),
-				Status: map[bool]string{true: "active", false: "deactive"}[user.Active],
-			})
-		}
+	listUser := make([]responses.GetUsers, 0, len(result.Users))
+	for _, user := range result.Users {
+		listUser = append(listUser, responses.GetUsers{
+			ID:          user.ID,
+			Name:        user.Name,
+			Email:       user.Email,
+			Role:        string(user.Role),
+			Status:      map[bool]string{true: "active", false: "deactive"}[user.Active],
+			LastLoginAt: user.LastLoginAt,
+			CreatedAt:   user.CreatedAt,
+		})
 	}
+
 	ctx.JSON(http.StatusOK, responses.Responses{
 		Code:        http.StatusOK,
 		Description: "SUCCESS",
-		Data:        listUser,
+		Data: responses.PageResponse{
+			Items:      listUser,
+			Total:      result.Total,
+			Page:       result.Page,
+			Limit:      result.Limit,
+			HasNext:    result.HasNext,
+			NextCursor: result.NextCursor,
+		},
+	})
+}
+
+// ExportUsers godoc
+// @Summary Export users
+// @Description Download all users matching the list filters as CSV, XLSX or PDF, including role, status and last login. The file is streamed while rows are read from the database.
+// @Tags users
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param format query string false "csv (default), xlsx or pdf"
+// @Param search query string false "Search in name and email"
+// @Param role query []string false "Role, repeatable or comma separated"
+// @Param status query string false "active or inactive"
+// @Param created_from query string false "Created at or after (RFC 3339)"
+// @Param created_to query string false "Created before (RFC 3339)"
+// @Param sort query string false "Same as the user list. Default -created_at"
+// @Success 200 {file} file
+// @Failure 400 {object} errors.APIError
+// @Failure 403 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /users/export [get]
+func (c *UserController) ExportUsers(ctx *gin.Context) {
+	var filter requests.UserListRequest
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.Error(errors.NewBadRequestError(errors.CodeInvalidRequest, "Invalid query parameters", err.Error()))
+		return
+	}
+
+	format := spreadsheet.FormatCSV
+	if raw := ctx.Query("format"); raw != "" {
+		var err error
+		if format, err = spreadsheet.ParseFormat(raw); err != nil {
+			ctx.Error(errors.NewBadRequestError(errors.CodeInvalidRequest, err.Error(), nil))
+			return
+		}
+	}
+
+	download := &downloadWriter{
+		ctx:         ctx,
+		contentType: format.ContentType(),
+		filename:    "users-" + time.Now().UTC().Format("20060102") + "." + string(format),
+	}
+	rows, err := c.userService.ExportUsers(services.UserExportRequest{
+		Filter:      filter,
+		Format:      format,
+		GeneratedBy: ctx.GetString("email"),
+	}, download)
+
+	if !download.started {
+		if stderrors.Is(err, services.ErrInvalidListQuery) {
+			ctx.Error(errors.NewBadRequestError(errors.CodeInvalidRequest, err.Error(), nil))
+			return
+		}
+		if err != nil {
+			c.logger.Errorf("Failed to export users: %v", err)
+			ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, "Failed to export users"))
+			return
+		}
+	}
+	// Setelah file mulai terkirim status tidak bisa diubah lagi; klien akan
+	// menerima file yang terpotong
+	if err != nil {
+		c.logger.Errorf("User export interrupted after %d rows: %v", rows, err)
+	}
+
+	log := middlewares.AuditLogFromContext(ctx, entities.AuditUsersExport)
+	log.TargetType = "user"
+	log.StatusCode = http.StatusOK
+	c.auditService.Record(log, map[string]any{
+		"format":    format,
+		"filter":    ctx.Request.URL.RawQuery,
+		"rows":      rows,
+		"completed": err == nil,
 	})
 }
 
@@ -137,102 +248,619 @@ func (controller *UserController) GetListUser(ctx *gin.Context) {
 // @Summary Get user by ID
 // @Description Get user details by user ID
 // @Tags users
-// @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param id path int true "User ID"
-// @Success 200 {object} entities.User
-// @Failure 400 {object} responses.ErrorResponse
-// @Failure 404 {object} responses.ErrorResponse
-// @Failure 500 {object} responses.ErrorResponse
+// @Success 200 {object} responses.UserProfileResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 403 {object} errors.APIError
+// @Failure 404 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
 // @Router /users/{id} [get]
 func (c *UserController) GetUserByID(ctx *gin.Context) {
-	idStr := ctx.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, responses.ErrorResponse{
-			Error: "Invalid user ID",
-		})
+	user, ok := c.loadUserForAccess(ctx, entities.PermissionUsersRead)
+	if !ok {
 		return
 	}
 
-	user, err := c.userService.GetUserByID(uint(id))
-	if err != nil {
-		if err.Error() == "user not found" {
-			ctx.JSON(http.StatusNotFound, responses.ErrorResponse{
-				Error: "User not found",
-			})
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, responses.ErrorResponse{
-			Error: "Failed to get user",
-		})
-		return
-	}
-
-	ctx.JSON(http.StatusOK, user)
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data:        toUserProfileResponse(user),
+	})
 }
 
 // UpdateUser godoc
 // @Summary Update user
-// @Description Update user details
+// @Description Update the name and/or email of a user. Only users allowed to grant the target user's role may update it.
 // @Tags users
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param id path int true "User ID"
 // @Param input body entities.UserUpdateRequest true "User data"
-// @Success 200 {object} responses.SuccessResponse
-// @Failure 400 {object} responses.ErrorResponse
-// @Failure 404 {object} responses.ErrorResponse
-// @Failure 500 {object} responses.ErrorResponse
+// @Success 200 {object} responses.UserProfileResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 403 {object} errors.APIError
+// @Failure 404 {object} errors.APIError
+// @Failure 409 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
 // @Router /users/{id} [put]
 func (c *UserController) UpdateUser(ctx *gin.Context) {
-	idStr := ctx.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, responses.ErrorResponse{
-			Error: "Invalid user ID",
-		})
-		return
-	}
-
 	var req entities.UserUpdateRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		c.logger.Errorf("Failed to bind user data: %v", err)
-		ctx.JSON(http.StatusBadRequest, responses.ErrorResponse{
-			Error: "Invalid request payload",
-		})
+	if !bindAndValidate(ctx, &req) {
 		return
 	}
 
-	// Validate input
-	if err := validators.Validate.Struct(req); err != nil {
-		c.logger.Errorf("Validation failed: %v", err)
-		ctx.JSON(http.StatusBadRequest, responses.ErrorResponse{
-			Error: "Validation failed",
-		})
+	existingUser, ok := c.loadUserForAccess(ctx, entities.PermissionUsersWrite)
+	if !ok {
 		return
 	}
 
 	user := &entities.Users{
-		Model: entities.Model{ID: uint(id)},
+		Model: entities.Model{ID: existingUser.ID},
 		Name:  req.Name,
 		Email: req.Email,
 	}
 
 	if err := c.userService.UpdateUser(user); err != nil {
+		switch {
+		case err == services.ErrEmailAlreadyRegistered:
+			ctx.Error(errors.NewConflictError(errors.CodeConflict, err.Error()))
+		case err.Error() == "user not found":
+			ctx.Error(errors.NewNotFoundError(errors.CodeNotFound, "User not found"))
+		default:
+			ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, "Failed to update user"))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data:        toUserProfileResponse(user),
+	})
+}
+
+// DeleteUser godoc
+// @Summary Delete user
+// @Description Move a user account to the trash and revoke all of its sessions. The user can be restored until it is purged. Users cannot delete themselves and the last active super_admin cannot be deleted.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 403 {object} errors.APIError
+// @Failure 404 {object} errors.APIError
+// @Failure 409 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /users/{id} [delete]
+func (c *UserController) DeleteUser(ctx *gin.Context) {
+	user, ok := c.loadUserForAccess(ctx, entities.PermissionUsersWrite)
+	if !ok {
+		return
+	}
+
+	if err := c.userService.DeleteUser(ctx.MustGet("userID").(uint), user.ID); err != nil {
+		abortUserStatusError(ctx, err, "Failed to delete user")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data: responses.SuccessResponse{
+			Message: "User moved to trash, all sessions have been signed out",
+		},
+	})
+}
+
+// ListDeletedUsers godoc
+// @Summary List deleted users
+// @Description List users in the trash, most recently deleted first. purge_at is when the user will be permanently deleted.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page"
+// @Param limit query int false "Page size (max 100)"
+// @Param search query string false "Search in name and email"
+// @Success 200 {object} responses.PageResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /users/trash [get]
+func (c *UserController) ListDeletedUsers(ctx *gin.Context) {
+	var request requests.BaseGetListRequest
+	if err := ctx.ShouldBindQuery(&request); err != nil {
+		ctx.Error(errors.NewBadRequestError(errors.CodeInvalidRequest, "Invalid query parameters", err.Error()))
+		return
+	}
+
+	result, err := c.userService.ListDeletedUsers(request)
+	if err != nil {
+		ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, "Failed to list deleted users"))
+		return
+	}
+
+	items := make([]responses.DeletedUserResponse, 0, len(result.Users))
+	for _, user := range result.Users {
+		item := responses.DeletedUserResponse{
+			ID:        user.ID,
+			Name:      user.Name,
+			Email:     user.Email,
+			Role:      string(user.Role),
+			Active:    user.Active,
+			DeletedAt: user.DeletedAt.Time,
+		}
+		if purgeAt, ok := result.PurgeAt[user.ID]; ok {
+			item.PurgeAt = &purgeAt
+		}
+		items = append(items, item)
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data: responses.PageResponse{
+			Items:   items,
+			Total:   &result.Total,
+			Page:    result.Page,
+			Limit:   result.Limit,
+			HasNext: int64(result.Page*result.Limit) < result.Total,
+		},
+	})
+}
+
+// RestoreUser godoc
+// @Summary Restore deleted user
+// @Description Restore a user from the trash with the active status it had before deletion. Fails if the email is now used by another user.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} responses.UserProfileResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 403 {object} errors.APIError
+// @Failure 404 {object} errors.APIError
+// @Failure 409 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /users/trash/{id}/restore [post]
+func (c *UserController) RestoreUser(ctx *gin.Context) {
+	user, ok := c.loadDeletedUserForAccess(ctx, entities.PermissionUsersWrite)
+	if !ok {
+		return
+	}
+
+	restored, err := c.userService.RestoreUser(ctx.MustGet("userID").(uint), user.ID)
+	if err != nil {
+		abortUserTrashError(ctx, err, "Failed to restore user")
+		return
+	}
+
+	log := middlewares.AuditLogFromContext(ctx, entities.AuditUserRestore)
+	log.TargetType = "user"
+	log.TargetID = strconv.FormatUint(uint64(user.ID), 10)
+	log.StatusCode = http.StatusOK
+	c.auditService.Record(log, map[string]any{
+		"email":      user.Email,
+		"deleted_at": user.DeletedAt.Time,
+	})
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data:        toUserProfileResponse(restored),
+	})
+}
+
+// PurgeUser godoc
+// @Summary Permanently delete user
+// @Description Permanently delete a user from the trash without waiting for the retention period. This cannot be undone.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 403 {object} errors.APIError
+// @Failure 404 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /users/trash/{id} [delete]
+func (c *UserController) PurgeUser(ctx *gin.Context) {
+	user, ok := c.loadDeletedUserForAccess(ctx, entities.PermissionUsersPurge)
+	if !ok {
+		return
+	}
+
+	if err := c.userService.PurgeUser(ctx.MustGet("userID").(uint), user.ID); err != nil {
+		abortUserTrashError(ctx, err, "Failed to purge user")
+		return
+	}
+
+	log := middlewares.AuditLogFromContext(ctx, entities.AuditUserPurge)
+	log.TargetType = "user"
+	log.TargetID = strconv.FormatUint(uint64(user.ID), 10)
+	log.StatusCode = http.StatusOK
+	c.auditService.Record(log, map[string]any{
+		"email":  user.Email,
+		"reason": "manual",
+	})
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data: responses.SuccessResponse{
+			Message: "User permanently deleted",
+		},
+	})
+}
+
+// DeactivateUser godoc
+// @Summary Deactivate user
+// @Description Deactivate a user account. The user can no longer sign in and all sessions are revoked.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} responses.UserProfileResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 403 {object} errors.APIError
+// @Failure 404 {object} errors.APIError
+// @Failure 409 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /users/{id}/deactivate [post]
+func (c *UserController) DeactivateUser(ctx *gin.Context) {
+	c.setUserActive(ctx, false)
+}
+
+// ReactivateUser godoc
+// @Summary Reactivate user
+// @Description Reactivate a deactivated user account so the user can sign in again
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} responses.UserProfileResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 403 {object} errors.APIError
+// @Failure 404 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /users/{id}/reactivate [post]
+func (c *UserController) ReactivateUser(ctx *gin.Context) {
+	c.setUserActive(ctx, true)
+}
+
+func (c *UserController) setUserActive(ctx *gin.Context, active bool) {
+	user, ok := c.loadUserForAccess(ctx, entities.PermissionUsersWrite)
+	if !ok {
+		return
+	}
+
+	user, err := c.userService.SetActive(ctx.MustGet("userID").(uint), user.ID, active)
+	if err != nil {
+		abortUserStatusError(ctx, err, "Failed to change user status")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data:        toUserProfileResponse(user),
+	})
+}
+
+// ResetUserPassword godoc
+// @Summary Reset user password
+// @Description Invalidate the current password of a user, revoke all sessions and email a password reset link
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 403 {object} errors.APIError
+// @Failure 404 {object} errors.APIError
+// @Failure 409 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /users/{id}/reset-password [post]
+func (c *UserController) ResetUserPassword(ctx *gin.Context) {
+	user, ok := c.loadUserForAccess(ctx, entities.PermissionUsersWrite)
+	if !ok {
+		return
+	}
+
+	emailSent, err := c.passwordResetService.ForceReset(user.ID)
+	if err != nil {
+		switch {
+		case err == services.ErrPasswordResetUserInactive:
+			ctx.Error(errors.NewConflictError(errors.CodeConflict, err.Error()))
+		case err.Error() == "user not found":
+			ctx.Error(errors.NewNotFoundError(errors.CodeNotFound, "User not found"))
+		default:
+			c.logger.Errorf("Password reset of user %d failed: %v", user.ID, err)
+			ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, "Failed to reset password"))
+		}
+		return
+	}
+
+	message := "Password reset, a reset link has been sent to the user"
+	if !emailSent {
+		message = "Password reset, but the reset link could not be emailed; the user can request a new one via forgot password"
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data: responses.SuccessResponse{
+			Message: message,
+		},
+	})
+}
+
+// GetMe godoc
+// @Summary Get own profile
+// @Description Get the profile of the authenticated user
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} responses.UserProfileResponse
+// @Failure 404 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /users/me [get]
+func (c *UserController) GetMe(ctx *gin.Context) {
+	user, err := c.userService.GetUserByID(ctx.MustGet("userID").(uint))
+	if err != nil {
 		if err.Error() == "user not found" {
-			ctx.JSON(http.StatusNotFound, responses.ErrorResponse{
-				Error: "User not found",
-			})
+			ctx.Error(errors.NewNotFoundError(errors.CodeNotFound, "User not found"))
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, responses.ErrorResponse{
-			Error: err.Error(),
-		})
+		ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, "Failed to get user"))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, responses.SuccessResponse{
-		Message: "User updated successfully",
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data:        toUserProfileResponse(user),
 	})
 }
+
+// UpdateMe godoc
+// @Summary Update own profile
+// @Description Update the name and/or email of the authenticated user. Changing the email requires current_password. Not available while impersonating.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body entities.ProfileUpdateRequest true "Profile data"
+// @Success 200 {object} responses.UserProfileResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 401 {object} errors.APIError
+// @Failure 403 {object} errors.APIError
+// @Failure 404 {object} errors.APIError
+// @Failure 409 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /users/me [put]
+func (c *UserController) UpdateMe(ctx *gin.Context) {
+	var req entities.ProfileUpdateRequest
+	if !bindAndValidate(ctx, &req) {
+		return
+	}
+
+	user, err := c.userService.UpdateProfile(ctx.MustGet("userID").(uint), req)
+	if err != nil {
+		switch {
+		case err == services.ErrPasswordRequired:
+			ctx.Error(errors.NewBadRequestError(errors.CodeValidationFailed, err.Error(), nil))
+		case err == services.ErrIncorrectCurrentPassword:
+			ctx.Error(errors.NewUnauthorizedError(errors.CodeUnauthorized, err.Error()))
+		case err == services.ErrEmailAlreadyRegistered:
+			ctx.Error(errors.NewConflictError(errors.CodeConflict, err.Error()))
+		case err.Error() == "user not found":
+			ctx.Error(errors.NewNotFoundError(errors.CodeNotFound, "User not found"))
+		default:
+			ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, "Failed to update user"))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data:        toUserProfileResponse(user),
+	})
+}
+
+// ChangeMyPassword godoc
+// @Summary Change own password
+// @Description Change the password of the authenticated user. The new password must satisfy the password policy; all other sessions are revoked.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body entities.ChangePasswordRequest true "Old and new password"
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 401 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /users/me/password [post]
+func (c *UserController) ChangeMyPassword(ctx *gin.Context) {
+	var req entities.ChangePasswordRequest
+	if !bindAndValidate(ctx, &req) {
+		return
+	}
+
+	userID := ctx.MustGet("userID").(uint)
+	sessionID := ctx.GetString("sessionID")
+
+	if err := c.userService.ChangePassword(userID, sessionID, req.OldPassword, req.NewPassword); err != nil {
+		if abortIfPasswordRejected(ctx, err) {
+			return
+		}
+		if err == services.ErrIncorrectPassword {
+			ctx.Error(errors.NewUnauthorizedError(errors.CodeUnauthorized, err.Error()))
+			return
+		}
+		c.logger.Errorf("Password change failed for user %d: %v", userID, err)
+		ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, "Failed to change password"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data: responses.SuccessResponse{
+			Message: "Password changed, other sessions have been signed out",
+		},
+	})
+}
+
+// ChangeUserRole godoc
+// @Summary Change user role
+// @Description Promote or demote a user. Admins can only manage accounts below admin and cannot grant admin; the last active super_admin cannot be demoted. All sessions of the user are revoked.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param userID path int true "User ID"
+// @Param input body entities.UserRoleUpdateRequest true "New role"
+// @Success 200 {object} responses.UserProfileResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 403 {object} errors.APIError
+// @Failure 404 {object} errors.APIError
+// @Failure 409 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /admin/users/{userID}/role [put]
+func (c *UserController) ChangeUserRole(ctx *gin.Context) {
+	userID, ok := parseUserIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	var req entities.UserRoleUpdateRequest
+	if !bindAndValidate(ctx, &req) {
+		return
+	}
+
+	actorID := ctx.MustGet("userID").(uint)
+	actorRole := entities.Role(ctx.GetString("role"))
+
+	user, err := c.userService.ChangeRole(actorID, actorRole, userID, req.Role)
+	if err != nil {
+		switch {
+		case err == services.ErrRoleNotAllowed, err == services.ErrRoleChangeNotAllowed, err == services.ErrCannotChangeOwnRole:
+			ctx.Error(errors.NewForbiddenError(errors.CodeForbidden, err.Error()))
+		case err == services.ErrLastSuperAdmin:
+			ctx.Error(errors.NewConflictError(errors.CodeConflict, err.Error()))
+		case err.Error() == "user not found":
+			ctx.Error(errors.NewNotFoundError(errors.CodeNotFound, "User not found"))
+		default:
+			ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, "Failed to change role"))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, responses.Responses{
+		Code:        http.StatusOK,
+		Description: "SUCCESS",
+		Data:        toUserProfileResponse(user),
+	})
+}
+
+// loadUserForAccess mengambil user dari parameter id lalu memastikan access
+// policy mengizinkan action terhadapnya. Respons error sudah ditulis jika
+// hasilnya false.
+func (c *UserController) loadUserForAccess(ctx *gin.Context, action string) (*entities.Users, bool) {
+	return loadTargetUser(ctx, c.userService, c.accessPolicy, "id", action)
+}
+
+// loadDeletedUserForAccess sama seperti loadUserForAccess untuk user di trash
+func (c *UserController) loadDeletedUserForAccess(ctx *gin.Context, action string) (*entities.Users, bool) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(errors.NewBadRequestError(errors.CodeInvalidRequest, "Invalid user ID", nil))
+		return nil, false
+	}
+
+	user, err := c.userService.GetDeletedUser(uint(id))
+	if err != nil {
+		abortUserTrashError(ctx, err, "Failed to get deleted user")
+		return nil, false
+	}
+
+	if !authorizeUserAccess(ctx, c.accessPolicy, action, user) {
+		return nil, false
+	}
+	return user, true
+}
+
+func abortUserTrashError(ctx *gin.Context, err error, message string) {
+	switch err {
+	case services.ErrDeletedUserNotFound:
+		ctx.Error(errors.NewNotFoundError(errors.CodeNotFound, "Deleted user not found"))
+	case services.ErrEmailTaken:
+		ctx.Error(errors.NewConflictError(errors.CodeConflict, err.Error()))
+	default:
+		ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, message))
+	}
+}
+
+func abortUserStatusError(ctx *gin.Context, err error, message string) {
+	switch {
+	case err == services.ErrCannotModifySelf:
+		ctx.Error(errors.NewForbiddenError(errors.CodeForbidden, err.Error()))
+	case err == services.ErrLastSuperAdmin:
+		ctx.Error(errors.NewConflictError(errors.CodeConflict, err.Error()))
+	case err.Error() == "user not found":
+		ctx.Error(errors.NewNotFoundError(errors.CodeNotFound, "User not found"))
+	default:
+		ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, message))
+	}
+}
+
+// abortIfAccessDenied menulis respons 403 beserta alasan penolakan jika access
+// policy menolak request
+func abortIfAccessDenied(ctx *gin.Context, err error) bool {
+	var deniedErr *services.AccessDeniedError
+	if !stderrors.As(err, &deniedErr) {
+		return false
+	}
+
+	ctx.Error(errors.NewForbiddenError(errors.CodeForbidden, deniedErr.Error()))
+	return true
+}
+
+// downloadWriter menunda header respons sampai byte pertama ditulis, sehingga
+// error yang terjadi sebelum itu masih bisa dikirim sebagai JSON
+type downloadWriter struct {
+	ctx         *gin.Context
+	contentType string
+	filename    string
+	started     bool
+}
+
+func (w *downloadWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.started = true
+		w.ctx.Header("Content-Type", w.contentType)
+		w.ctx.Header("Content-Disposition", `attachment; filename="`+w.filename+`"`)
+		w.ctx.Header("Cache-Control", "no-store")
+		w.ctx.Status(http.StatusOK)
+	}
+	return w.ctx.Writer.Write(p)
+}
+
+func toUserProfileResponse(user *entities.Users) responses.UserProfileResponse {
+	return responses.UserProfileResponse{
+		ID:              user.ID,
+		Name:            user.Name,
+		Email:           user.Email,
+		Role:            string(user.Role),
+		Active:          user.Active,
+		MFAEnabled:      user.MFAEnabled,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		LastLoginAt:     user.LastLoginAt,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
+	}
+}
@@ -0,0 +1,176 @@
+package controllers
+
+import (
+	stderrors "errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/errors"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/responses"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/services"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/spreadsheet"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type UserImportController struct {
+	userImportService services.UserImportService
+	logger            *logrus.Logger
+}
+
+func NewUserImportController(userImportService services.UserImportService, logger *logrus.Logger) *UserImportController {
+	return &UserImportController{
+		userImportService: userImportService,
+		logger:            logger,
+	}
+}
+
+// ImportUsers godoc
+// @Summary Import users
+// @Description Upload a CSV or XLSX file with the columns name, email and role. With dry_run=true every row is validated and nothing is created. Otherwise, if all rows are valid, the users are created in one transaction and invitation emails are sent in the background (email_queued); if any row is invalid nothing is created and the row results are returned in the error details.
+// @Tags users
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "CSV or XLSX file"
+// @Param dry_run query bool false "Only validate the file"
+// @Success 200 {object} responses.UserImportResponse
+// @Success 201 {object} responses.UserImportResponse
+// @Failure 400 {object} errors.APIError
+// @Failure 403 {object} errors.APIError
+// @Failure 409 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /users/import [post]
+func (c *UserImportController) ImportUsers(ctx *gin.Context) {
+	dryRun, err := strconv.ParseBool(ctx.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		ctx.Error(errors.NewBadRequestError(errors.CodeInvalidRequest, "Invalid dry_run value", nil))
+		return
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.Error(errors.NewBadRequestError(errors.CodeInvalidRequest, "File is required", err.Error()))
+		return
+	}
+	if fileHeader.Size > services.MaxUserImportFileSize {
+		ctx.Error(errors.NewBadRequestError(errors.CodeInvalidRequest, "File is too large", gin.H{"max_bytes": services.MaxUserImportFileSize}))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		ctx.Error(errors.NewBadRequestError(errors.CodeInvalidRequest, "File could not be read", nil))
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, services.MaxUserImportFileSize))
+	if err != nil {
+		ctx.Error(errors.NewBadRequestError(errors.CodeInvalidRequest, "File could not be read", nil))
+		return
+	}
+
+	actorID := ctx.MustGet("userID").(uint)
+	actorRole := entities.Role(ctx.GetString("role"))
+
+	result, err := c.userImportService.Import(actorID, actorRole, fileHeader.Filename, data, dryRun)
+	if err != nil {
+		switch {
+		case err == services.ErrImportHasInvalidRows:
+			ctx.Error(errors.NewBadRequestError(errors.CodeValidationFailed, err.Error(), toUserImportResponse(result)))
+		case err == spreadsheet.ErrUnsupportedFormat,
+			stderrors.Is(err, services.ErrImportFileInvalid),
+			stderrors.Is(err, services.ErrImportMissingColumns),
+			stderrors.Is(err, services.ErrImportTooManyRows),
+			err == services.ErrImportEmpty:
+			ctx.Error(errors.NewBadRequestError(errors.CodeInvalidRequest, err.Error(), nil))
+		case err == services.ErrInvitationRoleNotAllowed:
+			ctx.Error(errors.NewForbiddenError(errors.CodeForbidden, err.Error()))
+		case err == services.ErrEmailAlreadyRegistered:
+			// Email didaftarkan orang lain di antara validasi dan penyimpanan
+			ctx.Error(errors.NewConflictError(errors.CodeConflict, err.Error()))
+		default:
+			c.logger.Errorf("User import failed: %v", err)
+			ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, "Failed to import users"))
+		}
+		return
+	}
+
+	status, description := http.StatusOK, "SUCCESS"
+	if result.Committed {
+		status, description = http.StatusCreated, "CREATED"
+	}
+	ctx.JSON(status, responses.Responses{
+		Code:        int64(status),
+		Description: description,
+		Data:        toUserImportResponse(result),
+	})
+}
+
+// DownloadImportReport godoc
+// @Summary Download import report
+// @Description Download the CSV report of an import run by the current user
+// @Tags users
+// @Produce text/csv
+// @Security BearerAuth
+// @Param importID path string true "Import ID"
+// @Success 200 {file} file
+// @Failure 404 {object} errors.APIError
+// @Failure 500 {object} errors.APIError
+// @Router /users/import/{importID}/report [get]
+func (c *UserImportController) DownloadImportReport(ctx *gin.Context) {
+	importID := ctx.Param("importID")
+
+	report, err := c.userImportService.Report(ctx.MustGet("userID").(uint), importID)
+	if err != nil {
+		if err == services.ErrImportReportNotFound {
+			ctx.Error(errors.NewNotFoundError(errors.CodeNotFound, err.Error()))
+			return
+		}
+		c.logger.Errorf("Failed to load import report %s: %v", importID, err)
+		ctx.Error(errors.NewInternalServerError(errors.CodeInternalError, "Failed to load import report"))
+		return
+	}
+
+	ctx.Header("Content-Disposition", `attachment; filename="user-import-`+importID+`.csv"`)
+	ctx.Data(http.StatusOK, "text/csv; charset=utf-8", report)
+}
+
+func toUserImportResponse(result *services.UserImportResult) responses.UserImportResponse {
+	rows := make([]responses.UserImportRowResponse, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		response := responses.UserImportRowResponse{
+			Row:          row.Row,
+			Name:         row.Name,
+			Email:        row.Email,
+			Role:         row.Role,
+			Status:       row.Status,
+			Errors:       row.Errors,
+			UserID:       row.UserID,
+			InvitationID: row.InvitationID,
+		}
+		switch {
+		case row.Status != services.UserImportRowInvited:
+		case row.EmailQueued:
+			response.EmailQueued = true
+		default:
+			emailSent := row.EmailSent
+			response.EmailSent = &emailSent
+		}
+		rows = append(rows, response)
+	}
+
+	return responses.UserImportResponse{
+		ImportID:  result.ImportID,
+		DryRun:    result.DryRun,
+		Committed: result.Committed,
+		Total:     result.Total,
+		Valid:     result.Valid,
+		Invalid:   result.Invalid,
+		ReportURL: "/users/import/" + result.ImportID + "/report",
+		Rows:      rows,
+	}
+}
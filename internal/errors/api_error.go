@@ -35,6 +35,14 @@ func NewUnauthorizedError(code, message string) *APIError {
 	}
 }
 
+func NewForbiddenError(code, message string) *APIError {
+	return &APIError{
+		Status:    http.StatusForbidden,
+		ErrorCode: code,
+		Message:   message,
+	}
+}
+
 func NewNotFoundError(code, message string) *APIError {
 	return &APIError{
 		Status:    http.StatusNotFound,
@@ -43,6 +51,23 @@ func NewNotFoundError(code, message string) *APIError {
 	}
 }
 
+func NewConflictError(code, message string) *APIError {
+	return &APIError{
+		Status:    http.StatusConflict,
+		ErrorCode: code,
+		Message:   message,
+	}
+}
+
+func NewTooManyRequestsError(code, message string, details any) *APIError {
+	return &APIError{
+		Status:    http.StatusTooManyRequests,
+		ErrorCode: code,
+		Message:   message,
+		Details:   details,
+	}
+}
+
 func NewInternalServerError(code, message string) *APIError {
 	return &APIError{
 		Status:    http.StatusInternalServerError,
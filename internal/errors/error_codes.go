@@ -10,4 +10,5 @@ const (
 	CodeDatabaseError    = "database_error"
 	CodeTimeout          = "timeout"
 	CodeTooManyRequests  = "too_many_requests"
+	CodeConflict         = "conflict"
 )
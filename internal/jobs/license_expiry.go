@@ -0,0 +1,27 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/configs"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/services"
+	"github.com/sirupsen/logrus"
+)
+
+// NewLicenseExpiryJob memberi tahu admin lewat email tentang STR dan SIP yang
+// akan berakhir. Setiap lisensi hanya dilaporkan sekali sampai diperbarui.
+func NewLicenseExpiryJob(staffProfileService services.StaffProfileService, cfg *configs.Config, logger *logrus.Logger) Job {
+	return Job{
+		Name:     "license_expiry_check",
+		Interval: cfg.LicenseCheckInterval,
+		Timeout:  10 * time.Minute,
+		Run: func(ctx context.Context) error {
+			notified, err := staffProfileService.NotifyExpiringLicenses(ctx)
+			if notified > 0 {
+				logger.Infof("Notified admins about %d licenses expiring within %d days", notified, cfg.LicenseExpiryWarningDays)
+			}
+			return err
+		},
+	}
+}
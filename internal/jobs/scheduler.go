@@ -0,0 +1,133 @@
+// Package jobs menjalankan pekerjaan latar belakang secara berkala, misalnya
+// purge user di trash. Jadwal dikoordinasikan lewat Redis sehingga dengan
+// beberapa instance API pun setiap job hanya berjalan sekali per interval.
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// pollInterval adalah seberapa sering setiap instance memeriksa apakah
+	// job sudah waktunya dijalankan
+	pollInterval = time.Minute
+
+	// retryDelay adalah jeda sebelum job yang gagal dicoba lagi
+	retryDelay = 5 * time.Minute
+)
+
+// Job adalah pekerjaan yang dijalankan setiap Interval. Run menerima context
+// yang dibatalkan saat Timeout terlewati atau scheduler dihentikan.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Timeout  time.Duration
+	Run      func(ctx context.Context) error
+}
+
+type Scheduler interface {
+	Add(job Job)
+	// Start menjalankan semua job di goroutine masing-masing sampai ctx
+	// dibatalkan
+	Start(ctx context.Context)
+}
+
+type scheduler struct {
+	redisClient *redis.Client
+	logger      *logrus.Logger
+	jobs        []Job
+}
+
+func NewScheduler(redisClient *redis.Client, logger *logrus.Logger) Scheduler {
+	return &scheduler{
+		redisClient: redisClient,
+		logger:      logger,
+	}
+}
+
+func (s *scheduler) Add(job Job) {
+	if job.Interval <= 0 {
+		s.logger.Warnf("Job %s has no interval and will not run", job.Name)
+		return
+	}
+	s.jobs = append(s.jobs, job)
+}
+
+func (s *scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		go s.loop(ctx, job)
+	}
+}
+
+func (s *scheduler) loop(ctx context.Context, job Job) {
+	poll := pollInterval
+	if job.Interval < poll {
+		poll = job.Interval
+	}
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+
+	for {
+		s.runIfDue(ctx, job)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runIfDue menjalankan job jika belum ada instance yang menjalankannya dalam
+// satu interval terakhir. Key Redis berlaku selama Interval dan menjadi tanda
+// bahwa job sudah berjalan; jika job gagal masa berlakunya diperpendek agar
+// dicoba lagi setelah retryDelay.
+func (s *scheduler) runIfDue(ctx context.Context, job Job) {
+	key := "job:" + job.Name
+	due, err := s.redisClient.SetNX(ctx, key, time.Now().Unix(), job.Interval).Result()
+	if err != nil {
+		s.logger.Errorf("Failed to schedule job %s: %v", job.Name, err)
+		return
+	}
+	if !due {
+		return
+	}
+
+	runCtx := ctx
+	if job.Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, job.Timeout)
+		defer cancel()
+	}
+
+	started := time.Now()
+	if err := s.safeRun(runCtx, job); err != nil {
+		s.logger.Errorf("Job %s failed: %v", job.Name, err)
+		if retryDelay < job.Interval {
+			if err := s.redisClient.Expire(ctx, key, retryDelay).Err(); err != nil {
+				s.logger.Warnf("Failed to reschedule job %s: %v", job.Name, err)
+			}
+		}
+		return
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"job":      job.Name,
+		"duration": time.Since(started).String(),
+	}).Info("Job finished")
+}
+
+// safeRun mencegah panic di satu job menghentikan seluruh aplikasi
+func (s *scheduler) safeRun(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return job.Run(ctx)
+}
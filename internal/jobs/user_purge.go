@@ -0,0 +1,41 @@
+package jobs
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/configs"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/services"
+	"github.com/sirupsen/logrus"
+)
+
+// NewUserPurgeJob menghapus permanen user yang sudah melewati masa retensi di
+// trash. Setiap user yang terhapus dicatat di audit log tanpa actor.
+func NewUserPurgeJob(userService services.UserService, auditService services.AuditService, cfg *configs.Config, logger *logrus.Logger) Job {
+	return Job{
+		Name:     "user_purge",
+		Interval: cfg.UserPurgeInterval,
+		Timeout:  30 * time.Minute,
+		Run: func(ctx context.Context) error {
+			purged, err := userService.PurgeExpiredUsers(ctx)
+			// Catat yang sudah terhapus walaupun batch berikutnya gagal
+			for _, user := range purged {
+				auditService.Record(&entities.AuditLog{
+					Action:     entities.AuditUserPurge,
+					TargetType: "user",
+					TargetID:   strconv.FormatUint(uint64(user.ID), 10),
+				}, map[string]any{
+					"email":     user.Email,
+					"reason":    "retention",
+					"retention": cfg.UserPurgeRetention.String(),
+				})
+			}
+			if len(purged) > 0 {
+				logger.Infof("Purged %d users deleted more than %s ago", len(purged), cfg.UserPurgeRetention)
+			}
+			return err
+		},
+	}
+}
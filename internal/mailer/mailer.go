@@ -0,0 +1,66 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/configs"
+)
+
+const (
+	DriverSMTP   = "smtp"
+	DriverOutbox = "outbox"
+)
+
+type Message struct {
+	To      []string
+	Subject string
+	Body    string
+}
+
+// Mailer mengirim email transaksional (reset password, undangan, notifikasi)
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewMailer memilih implementasi berdasarkan MAIL_DRIVER. Driver "outbox"
+// menulis email ke file sehingga bisa dipakai di development tanpa SMTP
+// server; di luar APP_ENV=development driver ini ditolak agar tautan reset
+// password dan undangan tidak diam-diam hanya tersimpan di disk.
+func NewMailer(cfg *configs.Config) (Mailer, error) {
+	switch cfg.MailDriver {
+	case DriverSMTP:
+		return NewSMTPMailer(cfg), nil
+	case DriverOutbox, "":
+		if cfg.AppEnv != "development" {
+			if cfg.MailDriver == "" {
+				return nil, fmt.Errorf("MAIL_DRIVER is required outside development (use %q)", DriverSMTP)
+			}
+			return nil, fmt.Errorf("mail driver %q is only allowed when APP_ENV=development", DriverOutbox)
+		}
+		return NewOutboxMailer(cfg.MailOutboxDir, cfg.MailFrom)
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", cfg.MailDriver)
+	}
+}
+
+// buildMessage menyusun email plain text dalam format RFC 5322
+func buildMessage(from string, msg Message) []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(msg.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	return buf.Bytes()
+}
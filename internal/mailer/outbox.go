@@ -0,0 +1,46 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+type outboxMailer struct {
+	dir  string
+	from string
+}
+
+// NewOutboxMailer menyimpan setiap email sebagai file .eml di dir
+func NewOutboxMailer(dir, from string) (Mailer, error) {
+	if dir == "" {
+		dir = "storage/outbox"
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create outbox directory: %w", err)
+	}
+
+	return &outboxMailer{dir: dir, from: from}, nil
+}
+
+func (m *outboxMailer) Send(ctx context.Context, msg Message) error {
+	if len(msg.To) == 0 {
+		return errors.New("mail has no recipient")
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(m.dir, time.Now().Format("20060102T150405")+"-*.eml")
+	if err != nil {
+		return fmt.Errorf("failed to create outbox file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(buildMessage(m.from, msg)); err != nil {
+		return fmt.Errorf("failed to write outbox file: %w", err)
+	}
+	return nil
+}
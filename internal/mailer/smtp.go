@@ -0,0 +1,53 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/smtp"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/configs"
+)
+
+type smtpMailer struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+}
+
+func NewSMTPMailer(cfg *configs.Config) Mailer {
+	return &smtpMailer{
+		addr:     net.JoinHostPort(cfg.SMTPHost, cfg.SMTPPort),
+		host:     cfg.SMTPHost,
+		username: cfg.SMTPUsername,
+		password: cfg.SMTPPassword,
+		from:     cfg.MailFrom,
+	}
+}
+
+// Send mengirim email lewat SMTP. STARTTLS dipakai otomatis jika server
+// mendukungnya.
+func (m *smtpMailer) Send(ctx context.Context, msg Message) error {
+	if len(msg.To) == 0 {
+		return errors.New("mail has no recipient")
+	}
+
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(m.addr, auth, m.from, msg.To, buildMessage(m.from, msg))
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
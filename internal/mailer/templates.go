@@ -0,0 +1,91 @@
+package mailer
+
+import (
+	"bytes"
+	"text/template"
+)
+
+var passwordResetTemplate = template.Must(template.New("password_reset").Parse(`Halo {{.Name}},
+
+Kami menerima permintaan untuk mengatur ulang password akun Ara Medika Anda.
+Buka tautan berikut untuk membuat password baru:
+
+{{.Link}}
+
+Tautan ini hanya dapat digunakan sekali dan berlaku selama {{.ExpiresIn}}.
+Jika Anda tidak meminta reset password, abaikan email ini.
+`))
+
+var invitationTemplate = template.Must(template.New("invitation").Parse(`Halo {{.Name}},
+
+{{.InvitedBy}} mengundang Anda untuk bergabung di Ara Medika.
+Buka tautan berikut untuk membuat password dan mengaktifkan akun Anda:
+
+{{.Link}}
+
+Tautan ini berlaku selama {{.ExpiresIn}}. Jika Anda merasa tidak seharusnya
+menerima undangan ini, abaikan email ini.
+`))
+
+var licenseExpiryTemplate = template.Must(template.New("license_expiry").Parse(`Halo {{.Name}},
+
+Lisensi tenaga kesehatan berikut sudah atau akan berakhir dalam {{.WarningDays}} hari ke depan:
+{{range .Licenses}}
+- {{.StaffName}} ({{.Profession}}): {{.Type}} {{.Number}}, berakhir {{.ExpiresAt}}{{if .Expired}} (SUDAH BERAKHIR){{end}}{{end}}
+
+Pastikan STR/SIP diperpanjang dan data profil staf diperbarui setelahnya.
+Lisensi yang sama tidak akan dilaporkan lagi sampai tanggal berakhirnya diubah.
+`))
+
+type PasswordResetData struct {
+	Name      string
+	Link      string
+	ExpiresIn string
+}
+
+func PasswordResetMessage(to string, data PasswordResetData) (Message, error) {
+	return render(to, "Reset password akun Ara Medika", passwordResetTemplate, data)
+}
+
+type InvitationData struct {
+	Name      string
+	InvitedBy string
+	Link      string
+	ExpiresIn string
+}
+
+func InvitationMessage(to string, data InvitationData) (Message, error) {
+	return render(to, "Undangan akun Ara Medika", invitationTemplate, data)
+}
+
+type LicenseExpiryItem struct {
+	StaffName  string
+	Profession string
+	Type       string
+	Number     string
+	ExpiresAt  string
+	Expired    bool
+}
+
+type LicenseExpiryData struct {
+	Name        string
+	WarningDays int
+	Licenses    []LicenseExpiryItem
+}
+
+func LicenseExpiryMessage(to string, data LicenseExpiryData) (Message, error) {
+	return render(to, "Lisensi tenaga kesehatan akan berakhir", licenseExpiryTemplate, data)
+}
+
+func render(to, subject string, tmpl *template.Template, data any) (Message, error) {
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, data); err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		To:      []string{to},
+		Subject: subject,
+		Body:    body.String(),
+	}, nil
+}
@@ -0,0 +1,112 @@
+package middlewares
+
+import (
+	"github.com/anieswahdie1/ara-medika-api.git/internal/errors"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// AuditLogFromContext membentuk audit log dengan actor, impersonator dan
+// informasi request dari context gin
+func AuditLogFromContext(ctx *gin.Context, action string) *entities.AuditLog {
+	log := &entities.AuditLog{
+		Action:    action,
+		Method:    ctx.Request.Method,
+		Path:      ctx.Request.URL.Path,
+		IPAddress: ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+	}
+
+	if userID, ok := ctx.Get("userID"); ok {
+		id := userID.(uint)
+		log.ActorID = &id
+	}
+	if impersonatorID, ok := ctx.Get("impersonatorID"); ok {
+		id := impersonatorID.(uint)
+		log.ImpersonatorID = &id
+	}
+
+	return log
+}
+
+// ImpersonationAudit dipasang secara global. Setelah request selesai, setiap
+// request yang dilakukan dengan token impersonasi dicatat di log aplikasi dan
+// audit trail.
+func ImpersonationAudit(auditService services.AuditService, logger *logrus.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		impersonatorID, ok := ctx.Get("impersonatorID")
+		if !ok {
+			return
+		}
+
+		logger.WithFields(logrus.Fields{
+			"impersonation":      true,
+			"impersonator_id":    impersonatorID,
+			"impersonator_email": ctx.GetString("impersonatorEmail"),
+			"user_id":            ctx.GetUint("userID"),
+			"status":             ctx.Writer.Status(),
+		}).Infof("Impersonated request: %s %s", ctx.Request.Method, ctx.Request.URL.Path)
+
+		log := AuditLogFromContext(ctx, entities.AuditImpersonationRequest)
+		log.StatusCode = ctx.Writer.Status()
+		auditService.Record(log, map[string]any{
+			"query": ctx.Request.URL.RawQuery,
+			"route": ctx.FullPath(),
+		})
+	}
+}
+
+// NoImpersonation menolak token impersonasi pada route sensitif, misalnya
+// pengaturan two-factor dan seluruh route admin
+func NoImpersonation() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if _, ok := ctx.Get("impersonatorID"); ok {
+			ctx.Abort()
+			ctx.Error(errors.NewForbiddenError(
+				errors.CodeForbidden,
+				"This endpoint cannot be used while impersonating",
+			))
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// IsImpersonating dipakai handler untuk mengetahui apakah request dilakukan
+// dengan token impersonasi
+func IsImpersonating(ctx *gin.Context) bool {
+	_, ok := ctx.Get("impersonatorID")
+	return ok
+}
+
+// AccessRequestFromContext membentuk AccessRequest untuk
+// AccessPolicyService dengan subject dan environment dari context gin
+func AccessRequestFromContext(ctx *gin.Context, action string, resource services.AccessResource) services.AccessRequest {
+	req := services.AccessRequest{
+		Subject: services.AccessSubject{
+			ID:          ctx.GetUint("userID"),
+			Role:        entities.Role(ctx.GetString("role")),
+			Permissions: ctx.GetStringSlice("permissions"),
+		},
+		Action:   action,
+		Resource: resource,
+		Environment: map[string]string{
+			"ip":          ctx.ClientIP(),
+			"method":      ctx.Request.Method,
+			"path":        ctx.Request.URL.Path,
+			"user_agent":  ctx.Request.UserAgent(),
+			"auth_method": ctx.GetString("authMethod"),
+		},
+	}
+
+	if impersonatorID, ok := ctx.Get("impersonatorID"); ok {
+		id := impersonatorID.(uint)
+		req.Subject.ImpersonatorID = &id
+	}
+
+	return req
+}
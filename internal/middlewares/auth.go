@@ -4,14 +4,23 @@ import (
 	"net/http"
 	"strings"
 
-	"github.com/anieswahdie1/ara-medika-api.git/internal/configs"
 	"github.com/anieswahdie1/ara-medika-api.git/internal/errors"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/services"
 	"github.com/anieswahdie1/ara-medika-api.git/internal/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 )
 
-func AuthMiddleware(cfg *configs.Config, redisClient *redis.Client) gin.HandlerFunc {
+const (
+	AuthMethodJWT    = "jwt"
+	AuthMethodAPIKey = "api_key"
+)
+
+// AuthMiddleware menerima access token JWT (`Bearer <token>`) untuk user
+// interaktif atau API key (`ApiKey <key>`) untuk integrasi machine-to-machine.
+// Keduanya mengisi userID, email, role dan permission role tersebut di context
+// sehingga RoleMiddleware dan RequirePermission tetap berlaku.
+func AuthMiddleware(redisClient *redis.Client, apiKeyService services.APIKeyService, roleService services.RoleService) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authHeader := ctx.GetHeader("Authorization")
 		if authHeader == "" {
@@ -24,12 +33,12 @@ func AuthMiddleware(cfg *configs.Config, redisClient *redis.Client) gin.HandlerF
 		}
 
 		// Periksa format header dengan lebih hati-hati
+		// Header tidak ikut dikembalikan karena bisa berisi token atau API key
 		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
+		if len(parts) != 2 || (parts[0] != "Bearer" && parts[0] != "ApiKey") {
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 				"error":           "Invalid authorization header format",
-				"expected_format": "Bearer <token>",
-				"received":        authHeader,
+				"expected_format": "Bearer <token> or ApiKey <key>",
 			})
 			return
 		}
@@ -44,30 +53,111 @@ func AuthMiddleware(cfg *configs.Config, redisClient *redis.Client) gin.HandlerF
 			return
 		}
 
-		// Check if token is blacklisted in Redis
-		val, err := redisClient.Get(ctx, tokenString).Result()
-		if err == nil && val == "blacklisted" {
+		if parts[0] == "ApiKey" {
+			authenticateAPIKey(ctx, apiKeyService, roleService, tokenString)
+			return
+		}
+
+		claims, err := utils.ValidateToken(tokenString)
+		if err != nil {
 			ctx.Abort()
 			ctx.Error(errors.NewUnauthorizedError(
 				errors.CodeUnauthorized,
-				"Token has been invalidated",
+				"Invalid Token",
 			))
 			return
 		}
 
-		claims, err := utils.ValidateToken(cfg, tokenString)
+		// Pastikan token belum di-blacklist dan session-nya masih aktif
+		status, err := utils.CheckTokenStatus(ctx, redisClient, claims, true)
 		if err != nil {
+			ctx.Abort()
+			ctx.Error(errors.NewInternalServerError(
+				errors.CodeInternalError,
+				"Failed to verify session",
+			))
+			return
+		}
+
+		switch status {
+		case utils.TokenBlacklisted:
 			ctx.Abort()
 			ctx.Error(errors.NewUnauthorizedError(
 				errors.CodeUnauthorized,
-				"Invalid Token",
+				"Token has been invalidated",
+			))
+			return
+		case utils.TokenSessionRevoked:
+			ctx.Abort()
+			ctx.Error(errors.NewUnauthorizedError(
+				errors.CodeUnauthorized,
+				"Session has been revoked",
 			))
 			return
 		}
 
+		if !setPermissions(ctx, roleService, claims.Role) {
+			return
+		}
+
 		ctx.Set("userID", claims.UserID)
 		ctx.Set("email", claims.Email)
 		ctx.Set("role", claims.Role)
+		ctx.Set("sessionID", claims.FamilyID)
+		ctx.Set("authMethod", AuthMethodJWT)
+		if claims.Actor != nil {
+			ctx.Set("impersonatorID", claims.Actor.ActorID())
+			ctx.Set("impersonatorEmail", claims.Actor.Email)
+		}
 		ctx.Next()
 	}
 }
+
+func authenticateAPIKey(ctx *gin.Context, apiKeyService services.APIKeyService, roleService services.RoleService, rawKey string) {
+	apiKey, err := apiKeyService.Authenticate(rawKey, ctx.ClientIP())
+	if err != nil {
+		ctx.Abort()
+		if err == services.ErrAPIKeyInvalid {
+			ctx.Error(errors.NewUnauthorizedError(
+				errors.CodeUnauthorized,
+				"Invalid API key",
+			))
+			return
+		}
+		ctx.Error(errors.NewInternalServerError(
+			errors.CodeInternalError,
+			"Failed to verify API key",
+		))
+		return
+	}
+
+	if !setPermissions(ctx, roleService, string(apiKey.User.Role)) {
+		return
+	}
+
+	ctx.Set("userID", apiKey.UserID)
+	ctx.Set("email", apiKey.User.Email)
+	ctx.Set("role", string(apiKey.User.Role))
+	ctx.Set("authMethod", AuthMethodAPIKey)
+	ctx.Set("apiKeyID", apiKey.ID)
+	ctx.Set("scopes", apiKey.ScopeList())
+	ctx.Next()
+}
+
+// setPermissions memuat permission role ke context untuk RequirePermission.
+// Role diambil dari token, sehingga perubahan role user baru berlaku setelah
+// token diperbarui, sedangkan perubahan permission role langsung berlaku.
+func setPermissions(ctx *gin.Context, roleService services.RoleService, role string) bool {
+	permissions, err := roleService.RolePermissions(ctx, role)
+	if err != nil {
+		ctx.Abort()
+		ctx.Error(errors.NewInternalServerError(
+			errors.CodeInternalError,
+			"Failed to load permissions",
+		))
+		return false
+	}
+
+	ctx.Set("permissions", permissions)
+	return true
+}
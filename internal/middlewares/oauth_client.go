@@ -0,0 +1,35 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/configs"
+	"github.com/gin-gonic/gin"
+)
+
+// OAuthClientAuth mengautentikasi client (service internal) dengan client
+// credentials lewat HTTP Basic atau parameter form client_id/client_secret
+// (RFC 6749 bagian 2.3.1). Error memakai format OAuth, bukan errors.APIError.
+func OAuthClientAuth(cfg *configs.Config) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		clientID, clientSecret, ok := ctx.Request.BasicAuth()
+		if !ok {
+			clientID = ctx.PostForm("client_id")
+			clientSecret = ctx.PostForm("client_secret")
+		}
+
+		expected, known := cfg.OAuthClients[clientID]
+		if clientID == "" || !known || subtle.ConstantTimeCompare([]byte(expected), []byte(clientSecret)) != 1 {
+			ctx.Header("WWW-Authenticate", `Basic realm="oauth"`)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error":             "invalid_client",
+				"error_description": "Client authentication failed",
+			})
+			return
+		}
+
+		ctx.Set("oauthClientID", clientID)
+		ctx.Next()
+	}
+}
@@ -0,0 +1,30 @@
+package middlewares
+
+import (
+	"github.com/anieswahdie1/ara-medika-api.git/internal/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission membatasi route untuk user yang role-nya memiliki salah
+// satu permission, misalnya RequirePermission("patients:read"). Permission
+// diisi oleh AuthMiddleware, jadi middleware ini harus dipasang setelahnya.
+// Untuk API key, RequireScope tetap berlaku di atas permission pemiliknya.
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		granted := ctx.GetStringSlice("permissions")
+		for _, permission := range permissions {
+			for _, g := range granted {
+				if g == permission {
+					ctx.Next()
+					return
+				}
+			}
+		}
+
+		ctx.Abort()
+		ctx.Error(errors.NewForbiddenError(
+			errors.CodeForbidden,
+			"Insufficient permissions",
+		))
+	}
+}
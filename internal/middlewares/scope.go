@@ -0,0 +1,50 @@
+package middlewares
+
+import (
+	"github.com/anieswahdie1/ara-medika-api.git/internal/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireScope membatasi route untuk request dengan API key yang memiliki
+// salah satu scope. Request dari user yang login dengan JWT tidak dibatasi
+// scope; aksesnya cukup diatur RequirePermission.
+func RequireScope(scopes ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.GetString("authMethod") != AuthMethodAPIKey {
+			ctx.Next()
+			return
+		}
+
+		granted := ctx.GetStringSlice("scopes")
+		for _, scope := range scopes {
+			for _, g := range granted {
+				if g == scope {
+					ctx.Next()
+					return
+				}
+			}
+		}
+
+		ctx.Abort()
+		ctx.Error(errors.NewForbiddenError(
+			errors.CodeForbidden,
+			"API key does not have the required scope",
+		))
+	}
+}
+
+// SessionOnly menolak API key pada route yang hanya masuk akal untuk user
+// interaktif, misalnya logout, pengaturan two-factor, dan pengelolaan API key.
+func SessionOnly() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.GetString("authMethod") == AuthMethodAPIKey {
+			ctx.Abort()
+			ctx.Error(errors.NewForbiddenError(
+				errors.CodeForbidden,
+				"This endpoint cannot be used with an API key",
+			))
+			return
+		}
+		ctx.Next()
+	}
+}
@@ -0,0 +1,58 @@
+package entities
+
+import (
+	"strings"
+	"time"
+)
+
+// Scope API key. Request dengan API key hanya boleh mengakses route yang
+// mensyaratkan salah satu scope miliknya.
+const (
+	ScopeUsersRead         = "users:read"
+	ScopeUsersWrite        = "users:write"
+	ScopeStaffProfilesRead = "staff_profiles:read"
+	ScopeAdmin             = "admin"
+)
+
+var APIKeyScopes = []string{ScopeUsersRead, ScopeUsersWrite, ScopeStaffProfilesRead, ScopeAdmin}
+
+// APIKey dipakai integrasi machine-to-machine (analyzer lab, kiosk farmasi,
+// job reporting). Key bertindak atas nama UserID sehingga role dan hak
+// aksesnya mengikuti user tersebut.
+type APIKey struct {
+	ID         uint       `gorm:"primarykey" json:"id"`
+	Name       string     `gorm:"not null" json:"name"`
+	Prefix     string     `gorm:"unique;not null" json:"prefix"`
+	KeyHash    string     `gorm:"not null" json:"-"`
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	User       *Users     `gorm:"foreignKey:UserID" json:"-"`
+	Scopes     string     `gorm:"not null" json:"-"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedBy  *uint      `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// ScopeList mengembalikan scope yang disimpan dipisahkan spasi
+func (k *APIKey) ScopeList() []string {
+	return strings.Fields(k.Scopes)
+}
+
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.ScopeList() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type APIKeyCreateRequest struct {
+	Name      string     `json:"name" validate:"required,min=3,max=100"`
+	UserID    uint       `json:"user_id" validate:"required"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,required"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
@@ -0,0 +1,36 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Action audit log
+const (
+	AuditImpersonationStart   = "impersonation.start"
+	AuditImpersonationEnd     = "impersonation.end"
+	AuditImpersonationRequest = "impersonation.request"
+	AuditAccessDenied         = "access.denied"
+	AuditUsersExport          = "users.export"
+	AuditUserRestore          = "user.restore"
+	AuditUserPurge            = "user.purge"
+)
+
+// AuditLog mencatat aksi sensitif. ActorID adalah user yang terlihat
+// melakukan aksi; jika aksi dilakukan lewat impersonasi, ImpersonatorID berisi
+// super admin yang sebenarnya bertindak.
+type AuditLog struct {
+	ID             uint            `gorm:"primarykey" json:"id"`
+	ActorID        *uint           `json:"actor_id"`
+	ImpersonatorID *uint           `json:"impersonator_id,omitempty"`
+	Action         string          `gorm:"not null" json:"action"`
+	TargetType     string          `json:"target_type,omitempty"`
+	TargetID       string          `json:"target_id,omitempty"`
+	Method         string          `json:"method,omitempty"`
+	Path           string          `json:"path,omitempty"`
+	StatusCode     int             `json:"status_code,omitempty"`
+	IPAddress      string          `json:"ip_address"`
+	UserAgent      string          `json:"user_agent"`
+	Metadata       json.RawMessage `gorm:"type:jsonb" json:"metadata" swaggertype:"object"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
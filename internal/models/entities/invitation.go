@@ -0,0 +1,50 @@
+package entities
+
+import "time"
+
+// Status undangan, dihitung dari AcceptedAt dan ExpiresAt
+const (
+	InvitationPending  = "pending"
+	InvitationExpired  = "expired"
+	InvitationAccepted = "accepted"
+)
+
+// UserInvitation menyimpan undangan untuk user yang dibuat admin. User dibuat
+// nonaktif dan baru aktif setelah pemilik email membuat passwordnya sendiri.
+// TokenHash adalah hash jti tautan terakhir yang dikirim, sehingga mengirim
+// ulang undangan membatalkan tautan sebelumnya.
+type UserInvitation struct {
+	ID         uint       `gorm:"primarykey" json:"id"`
+	UserID     uint       `gorm:"not null;uniqueIndex" json:"user_id"`
+	User       *Users     `gorm:"foreignKey:UserID" json:"-"`
+	TokenHash  string     `gorm:"not null" json:"-"`
+	InvitedBy  *uint      `json:"invited_by"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+	SentCount  int        `gorm:"not null;default:1" json:"sent_count"`
+	LastSentAt *time.Time `json:"last_sent_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func (i *UserInvitation) Status() string {
+	switch {
+	case i.AcceptedAt != nil:
+		return InvitationAccepted
+	case time.Now().After(i.ExpiresAt):
+		return InvitationExpired
+	default:
+		return InvitationPending
+	}
+}
+
+type InvitationCreateRequest struct {
+	Name  string `json:"name" validate:"required,min=3,max=50"`
+	Email string `json:"email" validate:"required,email"`
+	Role  Role   `json:"role" validate:"required,role"`
+}
+
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8,strong_password"`
+}
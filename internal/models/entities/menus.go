@@ -1,24 +1,52 @@
 package entities
 
+import "time"
+
 type IconMenu struct {
 	IconActive    string `json:"iconActive"`
 	IconNonActive string `json:"iconNonActive"`
 }
 
-type ChildMenu struct {
-	Code     string `json:"code"`
-	Name     string `json:"name"`
-	Priority string `json:"priority"`
+// Menus adalah item menu aplikasi. Submenu menunjuk induknya lewat ParentID
+// dan hanya tampil bagi role yang terdaftar di role_menus.
+type Menus struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	ParentID  *uint     `json:"parentId"`
+	Code      string    `gorm:"not null" json:"code"`
+	Name      string    `gorm:"not null" json:"name"`
+	Path      string    `json:"path"`
+	Icon      IconMenu  `gorm:"type:jsonb;column:icons;serializer:json" json:"icons"`
+	Priority  int       `json:"priority"`
+	Roles     []Roles   `gorm:"many2many:role_menus;joinForeignKey:MenuID;joinReferences:RoleID" json:"-"`
+	CreatedAt time.Time `json:"-"`
+	UpdatedAt time.Time `json:"-"`
 }
 
-type Menus struct {
-	Id          int    `gorm:"primary_key;Column:id" json:"id"`
-	Code        string `gorm:"Column:code" json:"code"`
-	Name        string `gorm:"Column:name" json:"name"`
-	Path        string `gorm:"Column:path" json:"path"`
-	Icon        string `gorm:"type:json;Column:icons" json:"icons"`
-	HasChild    bool   `gorm:"has_child" json:"hasChild"`
-	ChildMenus  string `gorm:"child_menus" json:"childMenus"`
-	Priority    int    `gorm:"priority" json:"priority"`
-	CanAccessBy string `gorm:"can_access_by" json:"-"`
+func (m *Menus) RoleNames() []string {
+	names := make([]string, 0, len(m.Roles))
+	for _, role := range m.Roles {
+		names = append(names, role.Name)
+	}
+	return names
+}
+
+// MenuRequest dipakai untuk membuat dan mengganti menu. Roles berisi nama
+// role yang boleh melihat menu.
+type MenuRequest struct {
+	ParentID *uint    `json:"parentId"`
+	Code     string   `json:"code" validate:"required,max=100"`
+	Name     string   `json:"name" validate:"required,max=255"`
+	Path     string   `json:"path" validate:"max=255"`
+	Icon     IconMenu `json:"icons"`
+	Priority int      `json:"priority" validate:"min=0"`
+	Roles    []string `json:"roles" validate:"dive,role"`
+}
+
+type MenuOrderItem struct {
+	ID       uint `json:"id" validate:"required"`
+	Priority int  `json:"priority" validate:"min=0"`
+}
+
+type MenuReorderRequest struct {
+	Items []MenuOrderItem `json:"items" validate:"required,min=1,dive"`
 }
@@ -0,0 +1,22 @@
+package entities
+
+import "time"
+
+type UserRecoveryCode struct {
+	ID        uint       `gorm:"primarykey" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"not null" json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// MFAEnrollment berisi secret TOTP yang belum dikonfirmasi beserta URI untuk
+// QR code aplikasi authenticator
+type MFAEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type MFAVerifyRequest struct {
+	Code string `json:"code" validate:"required,min=6,max=20"`
+}
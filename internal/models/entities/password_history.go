@@ -0,0 +1,17 @@
+package entities
+
+import "time"
+
+// PasswordHistory menyimpan hash password yang pernah dipakai user untuk
+// mencegah password lama dipakai ulang. Hash password saat ini juga tercatat.
+type PasswordHistory struct {
+	ID           uint      `gorm:"primarykey" json:"id"`
+	UserID       uint      `gorm:"not null;index" json:"user_id"`
+	PasswordHash string    `gorm:"not null" json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type LoginPasswordChangeRequest struct {
+	PasswordChangeToken string `json:"password_change_token" validate:"required"`
+	NewPassword         string `json:"new_password" validate:"required,min=8,strong_password"`
+}
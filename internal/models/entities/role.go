@@ -0,0 +1,62 @@
+package entities
+
+import "time"
+
+// Permission bawaan. Permission lain boleh ditambahkan lewat migrasi seiring
+// route baru yang memakainya.
+const (
+	PermissionUsersRead          = "users:read"
+	PermissionUsersWrite         = "users:write"
+	PermissionSessionsManage     = "sessions:manage"
+	PermissionAPIKeysManage      = "api_keys:manage"
+	PermissionAuditLogsRead      = "audit_logs:read"
+	PermissionInvitationsManage  = "invitations:manage"
+	PermissionRolesManage        = "roles:manage"
+	PermissionMenusManage        = "menus:manage"
+	PermissionUsersExport        = "users:export"
+	PermissionUsersPurge         = "users:purge"
+	PermissionStaffProfilesRead  = "staff_profiles:read"
+	PermissionStaffProfilesWrite = "staff_profiles:write"
+)
+
+// Roles adalah role yang tersimpan di database. Users.Role berisi Name role.
+// Role sistem (super_admin, admin, user) tidak bisa dihapus karena dipakai
+// langsung oleh kode.
+type Roles struct {
+	ID          uint          `gorm:"primarykey" json:"id"`
+	Name        string        `gorm:"unique;not null" json:"name"`
+	Description string        `json:"description"`
+	IsSystem    bool          `gorm:"not null;default:false" json:"is_system"`
+	Permissions []Permissions `gorm:"many2many:role_permissions;joinForeignKey:RoleID;joinReferences:PermissionID" json:"permissions"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+type Permissions struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	Name        string    `gorm:"unique;not null" json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (r *Roles) PermissionNames() []string {
+	names := make([]string, 0, len(r.Permissions))
+	for _, permission := range r.Permissions {
+		names = append(names, permission.Name)
+	}
+	return names
+}
+
+type RoleCreateRequest struct {
+	Name        string   `json:"name" validate:"required,min=2,max=50"`
+	Description string   `json:"description" validate:"max=255"`
+	Permissions []string `json:"permissions" validate:"dive,required"`
+}
+
+type RoleUpdateRequest struct {
+	Description string `json:"description" validate:"max=255"`
+}
+
+type RolePermissionsRequest struct {
+	Permissions []string `json:"permissions" validate:"required,dive,required"`
+}
@@ -0,0 +1,23 @@
+package entities
+
+import "time"
+
+// Session merepresentasikan satu login aktif (satu perangkat). ID session sama
+// dengan ID refresh token family yang dibuat saat login.
+type Session struct {
+	ID         string    `json:"id"`
+	UserID     uint      `json:"user_id"`
+	DeviceName string    `json:"device_name"`
+	IPAddress  string    `json:"ip_address"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// SessionMeta berisi informasi perangkat yang dikirim saat login
+type SessionMeta struct {
+	DeviceName string
+	IPAddress  string
+	UserAgent  string
+}
@@ -0,0 +1,111 @@
+package entities
+
+import "time"
+
+// Profession adalah jenis tenaga kesehatan
+type Profession string
+
+const (
+	ProfessionDoctor     Profession = "doctor"
+	ProfessionDentist    Profession = "dentist"
+	ProfessionNurse      Profession = "nurse"
+	ProfessionMidwife    Profession = "midwife"
+	ProfessionPharmacist Profession = "pharmacist"
+)
+
+// Status lisensi (STR/SIP), dihitung dari nomor dan tanggal berakhirnya
+const (
+	LicenseNone     = "none"
+	LicenseValid    = "valid"
+	LicenseExpiring = "expiring"
+	LicenseExpired  = "expired"
+)
+
+// Jenis lisensi tenaga kesehatan
+const (
+	LicenseSTR = "STR"
+	LicenseSIP = "SIP"
+)
+
+// StaffProfile menyimpan data profesi user yang merupakan tenaga kesehatan.
+// STR (Surat Tanda Registrasi) tanpa tanggal berakhir berarti berlaku seumur
+// hidup; SIP (Surat Izin Praktik) selalu punya tanggal berakhir. STRAlertedAt
+// dan SIPAlertedAt diisi saat admin sudah diberi tahu bahwa lisensi akan
+// habis, dan dikosongkan lagi saat tanggal berakhirnya diperbarui.
+type StaffProfile struct {
+	ID           uint       `gorm:"primarykey" json:"id"`
+	UserID       uint       `gorm:"not null;uniqueIndex" json:"user_id"`
+	User         *Users     `gorm:"foreignKey:UserID" json:"-"`
+	Profession   Profession `gorm:"type:varchar(30);not null" json:"profession"`
+	Specialty    string     `json:"specialty"`
+	STRNumber    string     `gorm:"column:str_number" json:"str_number"`
+	STRExpiresAt *time.Time `gorm:"column:str_expires_at;type:date" json:"str_expires_at"`
+	STRAlertedAt *time.Time `gorm:"column:str_alerted_at" json:"-"`
+	SIPNumber    string     `gorm:"column:sip_number" json:"sip_number"`
+	SIPExpiresAt *time.Time `gorm:"column:sip_expires_at;type:date" json:"sip_expires_at"`
+	SIPAlertedAt *time.Time `gorm:"column:sip_alerted_at" json:"-"`
+	NIP          string     `gorm:"column:nip" json:"nip"`
+	Phone        string     `json:"phone"`
+
+	// SignatureImage tidak ikut dimuat saat membaca profil, lihat
+	// StaffProfileRepository.FindSignature
+	SignatureImage       []byte `json:"-"`
+	SignatureContentType string `json:"-"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (p *StaffProfile) HasSignature() bool {
+	return p.SignatureContentType != ""
+}
+
+func (p *StaffProfile) STRStatus(warningDays int) string {
+	return LicenseStatus(p.STRNumber, p.STRExpiresAt, warningDays)
+}
+
+func (p *StaffProfile) SIPStatus(warningDays int) string {
+	return LicenseStatus(p.SIPNumber, p.SIPExpiresAt, warningDays)
+}
+
+// LicenseStatus menentukan status lisensi. Lisensi masih berlaku sampai akhir
+// tanggal berakhirnya dan berstatus expiring jika tanggal tersebut paling lama
+// warningDays hari dari hari ini.
+func LicenseStatus(number string, expiresAt *time.Time, warningDays int) string {
+	switch {
+	case number == "":
+		return LicenseNone
+	case expiresAt == nil:
+		return LicenseValid
+	}
+
+	today := DateOf(time.Now())
+	switch {
+	case expiresAt.Before(today):
+		return LicenseExpired
+	case expiresAt.Before(today.AddDate(0, 0, warningDays+1)):
+		return LicenseExpiring
+	default:
+		return LicenseValid
+	}
+}
+
+// DateOf mengembalikan tanggal t menurut zona waktu lokal sebagai tengah malam
+// UTC, sama seperti nilai kolom DATE yang dibaca dari database
+func DateOf(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// StaffProfileRequest dipakai untuk membuat dan mengganti profil. Tanggal
+// memakai format YYYY-MM-DD; str_expires_at kosong berarti STR seumur hidup.
+type StaffProfileRequest struct {
+	Profession   Profession `json:"profession" validate:"required,oneof=doctor dentist nurse midwife pharmacist"`
+	Specialty    string     `json:"specialty" validate:"max=100"`
+	STRNumber    string     `json:"str_number" validate:"required_with=SIPNumber,omitempty,str_number"`
+	STRExpiresAt string     `json:"str_expires_at" validate:"excluded_without=STRNumber,omitempty,datetime=2006-01-02"`
+	SIPNumber    string     `json:"sip_number" validate:"omitempty,sip_number"`
+	SIPExpiresAt string     `json:"sip_expires_at" validate:"required_with=SIPNumber,excluded_without=SIPNumber,omitempty,datetime=2006-01-02"`
+	NIP          string     `json:"nip" validate:"omitempty,nip"`
+	Phone        string     `json:"phone" validate:"omitempty,phone_id"`
+}
@@ -6,6 +6,8 @@ import (
 	"gorm.io/gorm"
 )
 
+// Role adalah nama role di tabel roles. Konstanta di bawah adalah role sistem
+// yang selalu ada; role lain bisa dibuat lewat /admin/roles.
 type Role string
 
 const (
@@ -24,10 +26,24 @@ type Model struct {
 type Users struct {
 	Model
 	Name     string `gorm:"not null" validate:"required,min=3,max=50"`
-	Email    string `gorm:"unique;not null" validate:"required,email"`
+	Email    string `gorm:"not null" validate:"required,email"` // unik di antara user yang belum dihapus
 	Password string `gorm:"not null" validate:"required,min=8"`
-	Role     Role   `gorm:"type:role;not null" validate:"required,role"`
+	Role     Role   `gorm:"type:varchar(50);not null" validate:"required,role"`
 	Active   bool   `gorm:"default:true" json:"active"`
+
+	// MFASecret disimpan terenkripsi (AES-GCM), jangan pernah diserialisasi
+	MFASecret    string     `gorm:"column:mfa_secret" json:"-"`
+	MFAEnabled   bool       `gorm:"column:mfa_enabled;default:false" json:"mfa_enabled"`
+	MFAEnabledAt *time.Time `gorm:"column:mfa_enabled_at" json:"mfa_enabled_at,omitempty"`
+
+	// EmailVerifiedAt diisi saat user menerima undangan
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+
+	// PasswordChangedAt dipakai untuk menghitung umur password (PASSWORD_MAX_AGE)
+	PasswordChangedAt *time.Time `json:"-"`
+
+	// LastLoginAt diperbarui setiap login berhasil
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
 }
 
 type UserCreateRequest struct {
@@ -42,7 +58,29 @@ type UserUpdateRequest struct {
 	Email string `json:"email" validate:"omitempty,email"`
 }
 
+// ProfileUpdateRequest dipakai user untuk mengubah profilnya sendiri. Mengganti
+// email wajib disertai password saat ini karena email dipakai untuk reset
+// password.
+type ProfileUpdateRequest struct {
+	Name            string `json:"name" validate:"omitempty,min=3,max=50"`
+	Email           string `json:"email" validate:"omitempty,email"`
+	CurrentPassword string `json:"current_password"`
+}
+
+type UserRoleUpdateRequest struct {
+	Role Role `json:"role" validate:"required,role"`
+}
+
 type ChangePasswordRequest struct {
 	OldPassword string `json:"old_password" validate:"required"`
-	NewPassword string `json:"new_password" validate:"required,min=8"`
+	NewPassword string `json:"new_password" validate:"required,min=8,strong_password"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=8,strong_password"`
 }
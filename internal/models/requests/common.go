@@ -1,7 +1,48 @@
 package requests
 
+import "time"
+
 type BaseGetListRequest struct {
 	Page   int    `form:"page"`
 	Limit  int    `form:"limit"`
 	Search string `form:"search"`
 }
+
+// UserListRequest adalah query daftar user. Role boleh diulang atau dipisah
+// koma (?role=admin,user). Sort berisi field dipisah koma; awalan "-" berarti
+// urutan menurun, misalnya "role,-created_at". Cursor diisi next_cursor dari
+// halaman sebelumnya untuk paginasi keyset; Page diabaikan jika Cursor diisi.
+type UserListRequest struct {
+	Page        int       `form:"page"`
+	Limit       int       `form:"limit"`
+	Search      string    `form:"search"`
+	Role        []string  `form:"role"`
+	Status      string    `form:"status"`
+	CreatedFrom time.Time `form:"created_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedTo   time.Time `form:"created_to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Sort        string    `form:"sort"`
+	Cursor      string    `form:"cursor"`
+}
+
+type AuditLogListRequest struct {
+	Page           int       `form:"page"`
+	Limit          int       `form:"limit"`
+	ActorID        uint      `form:"actor_id"`
+	ImpersonatorID uint      `form:"impersonator_id"`
+	Action         string    `form:"action"`
+	TargetType     string    `form:"target_type"`
+	TargetID       string    `form:"target_id"`
+	From           time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To             time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// StaffProfileListRequest adalah query daftar profil staf. LicenseStatus
+// berisi expiring (STR/SIP berakhir dalam LICENSE_EXPIRY_WARNING_DAYS hari)
+// atau expired.
+type StaffProfileListRequest struct {
+	Page          int    `form:"page"`
+	Limit         int    `form:"limit"`
+	Search        string `form:"search"`
+	Profession    string `form:"profession"`
+	LicenseStatus string `form:"license_status"`
+}
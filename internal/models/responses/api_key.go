@@ -0,0 +1,24 @@
+package responses
+
+import "time"
+
+type APIKeyResponse struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	UserID     uint       `json:"user_id"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedBy  *uint      `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// APIKeyCreatedResponse berisi key lengkap yang hanya ditampilkan sekali saat
+// dibuat
+type APIKeyCreatedResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
@@ -1,8 +1,32 @@
 package responses
 
+import "time"
+
 type TokenResponse struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
+	AccessToken   string   `json:"access_token"`
+	RefreshToken  string   `json:"refresh_token"`
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+type MFAChallengeResponse struct {
+	MFARequired        bool   `json:"mfa_required"`
+	EnrollmentRequired bool   `json:"enrollment_required"`
+	MFAToken           string `json:"mfa_token"`
+	ExpiresIn          int64  `json:"expires_in"`
+}
+
+// PasswordChangeRequiredResponse dikembalikan jika password user sudah
+// kedaluwarsa. RecoveryCodes terisi jika enrollment two-factor baru saja
+// diselesaikan pada login yang sama.
+type PasswordChangeRequiredResponse struct {
+	PasswordChangeRequired bool     `json:"password_change_required"`
+	PasswordChangeToken    string   `json:"password_change_token"`
+	ExpiresIn              int64    `json:"expires_in"`
+	RecoveryCodes          []string `json:"recovery_codes,omitempty"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
 }
 
 type ErrorResponse struct {
@@ -12,3 +36,19 @@ type ErrorResponse struct {
 type SuccessResponse struct {
 	Message string `json:"message"`
 }
+
+type ImpersonationResponse struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresIn   int64     `json:"expires_in"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	UserID      uint      `json:"user_id"`
+	Name        string    `json:"name"`
+	Email       string    `json:"email"`
+	Role        string    `json:"role"`
+}
+
+type ImpersonatorResponse struct {
+	ID    uint   `json:"id"`
+	Email string `json:"email"`
+}
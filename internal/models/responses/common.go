@@ -5,3 +5,14 @@ type Responses struct {
 	Description string      `json:"description"`
 	Data        interface{} `json:"data"`
 }
+
+// PageResponse membungkus satu halaman data. Pada paginasi cursor, Total dan
+// Page tidak diisi agar tabel besar tidak perlu dihitung ulang setiap halaman.
+type PageResponse struct {
+	Items      interface{} `json:"items"`
+	Total      *int64      `json:"total,omitempty"`
+	Page       int         `json:"page,omitempty"`
+	Limit      int         `json:"limit"`
+	HasNext    bool        `json:"has_next"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,25 @@
+package responses
+
+import "time"
+
+type InvitationResponse struct {
+	ID         uint       `json:"id"`
+	UserID     uint       `json:"user_id"`
+	Name       string     `json:"name"`
+	Email      string     `json:"email"`
+	Role       string     `json:"role"`
+	Status     string     `json:"status"`
+	InvitedBy  *uint      `json:"invited_by"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+	SentCount  int        `json:"sent_count"`
+	LastSentAt *time.Time `json:"last_sent_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// InvitationSentResponse menandakan apakah email undangan berhasil dikirim.
+// Jika gagal, undangan tetap tersimpan dan bisa dikirim ulang.
+type InvitationSentResponse struct {
+	InvitationResponse
+	EmailSent bool `json:"email_sent"`
+}
@@ -0,0 +1,17 @@
+package responses
+
+import "github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+
+// MenuResponse adalah satu node tree menu. Roles hanya diisi pada endpoint
+// admin.
+type MenuResponse struct {
+	ID       uint              `json:"id"`
+	ParentID *uint             `json:"parentId"`
+	Code     string            `json:"code"`
+	Name     string            `json:"name"`
+	Path     string            `json:"path"`
+	Icon     entities.IconMenu `json:"icons"`
+	Priority int               `json:"priority"`
+	Roles    []string          `json:"roles,omitempty"`
+	Children []MenuResponse    `json:"children"`
+}
@@ -0,0 +1,26 @@
+package responses
+
+import "time"
+
+// StaffProfileResponse adalah profil profesi tenaga kesehatan. Tanggal
+// berakhir lisensi memakai format YYYY-MM-DD; status bernilai none, valid,
+// expiring atau expired.
+type StaffProfileResponse struct {
+	UserID       uint      `json:"user_id"`
+	Name         string    `json:"name"`
+	Email        string    `json:"email"`
+	Role         string    `json:"role"`
+	Profession   string    `json:"profession"`
+	Specialty    string    `json:"specialty"`
+	STRNumber    string    `json:"str_number"`
+	STRExpiresAt *string   `json:"str_expires_at"`
+	STRStatus    string    `json:"str_status"`
+	SIPNumber    string    `json:"sip_number"`
+	SIPExpiresAt *string   `json:"sip_expires_at"`
+	SIPStatus    string    `json:"sip_status"`
+	NIP          string    `json:"nip"`
+	Phone        string    `json:"phone"`
+	HasSignature bool      `json:"has_signature"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
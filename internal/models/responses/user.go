@@ -1,23 +1,52 @@
 package responses
 
-import (
-	"time"
-
-	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
-)
+import "time"
 
 type UserResponse struct {
-	ID         uint             `json:"id"`
-	Name       string           `json:"name"`
-	Email      string           `json:"email"`
-	Role       string           `json:"role"`
-	AccessMenu []entities.Menus `json:"access_menus"`
-	CreatedAt  time.Time        `json:"created_at"`
+	ID         uint           `json:"id"`
+	Name       string         `json:"name"`
+	Email      string         `json:"email"`
+	Role       string         `json:"role"`
+	AccessMenu []MenuResponse `json:"access_menus"`
+	CreatedAt  time.Time      `json:"created_at"`
+
+	// Terisi jika request dilakukan dengan token impersonasi
+	Impersonated   bool                  `json:"impersonated"`
+	ImpersonatedBy *ImpersonatorResponse `json:"impersonated_by,omitempty"`
+}
+
+// UserProfileResponse adalah profil user (/users/me dan /users/{id})
+type UserProfileResponse struct {
+	ID              uint       `json:"id"`
+	Name            string     `json:"name"`
+	Email           string     `json:"email"`
+	Role            string     `json:"role"`
+	Active          bool       `json:"active"`
+	MFAEnabled      bool       `json:"mfa_enabled"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	LastLoginAt     *time.Time `json:"last_login_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
 }
 
 type GetUsers struct {
-	Name   string `json:"name"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
-	Status string `json:"status"`
+	ID          uint       `json:"id"`
+	Name        string     `json:"name"`
+	Email       string     `json:"email"`
+	Role        string     `json:"role"`
+	Status      string     `json:"status"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// DeletedUserResponse adalah user di trash. PurgeAt kosong jika purge
+// otomatis dimatikan.
+type DeletedUserResponse struct {
+	ID        uint       `json:"id"`
+	Name      string     `json:"name"`
+	Email     string     `json:"email"`
+	Role      string     `json:"role"`
+	Active    bool       `json:"active"`
+	DeletedAt time.Time  `json:"deleted_at"`
+	PurgeAt   *time.Time `json:"purge_at"`
 }
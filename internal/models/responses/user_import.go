@@ -0,0 +1,29 @@
+package responses
+
+// UserImportResponse adalah hasil import user massal. ReportURL mengarah ke
+// laporan CSV yang bisa diunduh oleh user yang menjalankan import.
+type UserImportResponse struct {
+	ImportID  string                  `json:"import_id"`
+	DryRun    bool                    `json:"dry_run"`
+	Committed bool                    `json:"committed"`
+	Total     int                     `json:"total"`
+	Valid     int                     `json:"valid"`
+	Invalid   int                     `json:"invalid"`
+	ReportURL string                  `json:"report_url"`
+	Rows      []UserImportRowResponse `json:"rows"`
+}
+
+type UserImportRowResponse struct {
+	Row          int      `json:"row"`
+	Name         string   `json:"name"`
+	Email        string   `json:"email"`
+	Role         string   `json:"role"`
+	Status       string   `json:"status"`
+	Errors       []string `json:"errors,omitempty"`
+	UserID       uint     `json:"user_id,omitempty"`
+	InvitationID uint     `json:"invitation_id,omitempty"`
+	EmailSent    *bool    `json:"email_sent,omitempty"`
+	// EmailQueued true jika email undangan masih dikirim di background;
+	// email_sent tidak diisi karena hasilnya belum diketahui
+	EmailQueued bool `json:"email_queued,omitempty"`
+}
@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository interface {
+	Create(apiKey *entities.APIKey) error
+	FindByID(id uint) (*entities.APIKey, error)
+	FindByPrefix(prefix string) (*entities.APIKey, error)
+	FindAll(userID uint) ([]entities.APIKey, error)
+	Revoke(id uint) error
+	TouchLastUsed(id uint, ip string, minInterval time.Duration) error
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+func (r *apiKeyRepository) Create(apiKey *entities.APIKey) error {
+	return r.db.Create(apiKey).Error
+}
+
+func (r *apiKeyRepository) FindByID(id uint) (*entities.APIKey, error) {
+	var apiKey entities.APIKey
+	err := r.db.First(&apiKey, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+// FindByPrefix mencari key beserta user pemiliknya. User yang sudah dihapus
+// (soft delete) tidak ikut dimuat sehingga User bernilai nil.
+func (r *apiKeyRepository) FindByPrefix(prefix string) (*entities.APIKey, error) {
+	var apiKey entities.APIKey
+	err := r.db.Preload("User").Where("prefix = ?", prefix).First(&apiKey).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+// FindAll mengembalikan semua key, atau hanya milik userID jika tidak 0
+func (r *apiKeyRepository) FindAll(userID uint) ([]entities.APIKey, error) {
+	var apiKeys []entities.APIKey
+	query := r.db.Order("created_at DESC")
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	err := query.Find(&apiKeys).Error
+	return apiKeys, err
+}
+
+func (r *apiKeyRepository) Revoke(id uint) error {
+	return r.db.Model(&entities.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+// TouchLastUsed mencatat pemakaian terakhir, paling sering sekali per
+// minInterval agar setiap request tidak selalu menulis ke database
+func (r *apiKeyRepository) TouchLastUsed(id uint, ip string, minInterval time.Duration) error {
+	now := time.Now()
+	return r.db.Model(&entities.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, now.Add(-minInterval)).
+		UpdateColumns(map[string]interface{}{
+			"last_used_at": now,
+			"last_used_ip": ip,
+		}).Error
+}
@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/requests"
+	"gorm.io/gorm"
+)
+
+type AuditLogRepository interface {
+	Create(log *entities.AuditLog) error
+	FindAll(request requests.AuditLogListRequest) ([]entities.AuditLog, error)
+}
+
+type auditLogRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) AuditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+func (r *auditLogRepository) Create(log *entities.AuditLog) error {
+	return r.db.Create(log).Error
+}
+
+func (r *auditLogRepository) FindAll(request requests.AuditLogListRequest) ([]entities.AuditLog, error) {
+	var logs []entities.AuditLog
+
+	query := r.db.Model(&entities.AuditLog{})
+	if request.ActorID != 0 {
+		query = query.Where("actor_id = ?", request.ActorID)
+	}
+	if request.ImpersonatorID != 0 {
+		query = query.Where("impersonator_id = ?", request.ImpersonatorID)
+	}
+	if request.Action != "" {
+		query = query.Where("action = ?", request.Action)
+	}
+	if request.TargetType != "" {
+		query = query.Where("target_type = ?", request.TargetType)
+	}
+	if request.TargetID != "" {
+		query = query.Where("target_id = ?", request.TargetID)
+	}
+	if !request.From.IsZero() {
+		query = query.Where("created_at >= ?", request.From)
+	}
+	if !request.To.IsZero() {
+		query = query.Where("created_at < ?", request.To)
+	}
+
+	err := query.
+		Order("created_at DESC, id DESC").
+		Limit(request.Limit).
+		Offset((request.Page - 1) * request.Limit).
+		Find(&logs).Error
+	return logs, err
+}
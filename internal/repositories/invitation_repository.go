@@ -0,0 +1,140 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"gorm.io/gorm"
+)
+
+type InvitationRepository interface {
+	CreateWithUser(user *entities.Users, invitation *entities.UserInvitation) error
+	CreateManyWithUsers(users []*entities.Users, invitations []*entities.UserInvitation) error
+	FindByID(id uint) (*entities.UserInvitation, error)
+	FindAll(status string) ([]entities.UserInvitation, error)
+	UpdateToken(invitation *entities.UserInvitation) error
+	Accept(invitation *entities.UserInvitation, hashedPassword string) error
+}
+
+type invitationRepository struct {
+	db *gorm.DB
+}
+
+func NewInvitationRepository(db *gorm.DB) InvitationRepository {
+	return &invitationRepository{db: db}
+}
+
+// CreateWithUser membuat user nonaktif beserta undangannya dalam satu
+// transaksi
+func (r *invitationRepository) CreateWithUser(user *entities.Users, invitation *entities.UserInvitation) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return createWithUser(tx, user, invitation)
+	})
+}
+
+// CreateManyWithUsers membuat beberapa user nonaktif beserta undangannya dalam
+// satu transaksi; satu baris gagal membatalkan semuanya. users[i] dipasangkan
+// dengan invitations[i].
+func (r *invitationRepository) CreateManyWithUsers(users []*entities.Users, invitations []*entities.UserInvitation) error {
+	if len(users) != len(invitations) {
+		return errors.New("users and invitations must have the same length")
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i := range users {
+			if err := createWithUser(tx, users[i], invitations[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func createWithUser(tx *gorm.DB, user *entities.Users, invitation *entities.UserInvitation) error {
+	if err := tx.Create(user).Error; err != nil {
+		return err
+	}
+	// Active memakai default:true sehingga nilai false tidak ikut di-insert
+	// oleh Create
+	if err := tx.Model(user).Update("active", false).Error; err != nil {
+		return err
+	}
+	user.Active = false
+
+	invitation.UserID = user.ID
+	return tx.Create(invitation).Error
+}
+
+func (r *invitationRepository) FindByID(id uint) (*entities.UserInvitation, error) {
+	var invitation entities.UserInvitation
+	err := r.db.Preload("User").First(&invitation, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// FindAll mengembalikan undangan sesuai status. Tanpa status, semua undangan
+// yang belum diterima (pending dan expired) dikembalikan.
+func (r *invitationRepository) FindAll(status string) ([]entities.UserInvitation, error) {
+	var invitations []entities.UserInvitation
+	now := time.Now()
+
+	query := r.db.Preload("User").Order("created_at DESC")
+	switch status {
+	case entities.InvitationPending:
+		query = query.Where("accepted_at IS NULL AND expires_at > ?", now)
+	case entities.InvitationExpired:
+		query = query.Where("accepted_at IS NULL AND expires_at <= ?", now)
+	case entities.InvitationAccepted:
+		query = query.Where("accepted_at IS NOT NULL")
+	default:
+		query = query.Where("accepted_at IS NULL")
+	}
+
+	err := query.Find(&invitations).Error
+	return invitations, err
+}
+
+// UpdateToken menyimpan token dan masa berlaku baru saat undangan dikirim
+// ulang
+func (r *invitationRepository) UpdateToken(invitation *entities.UserInvitation) error {
+	return r.db.Model(invitation).
+		Where("accepted_at IS NULL").
+		Updates(map[string]interface{}{
+			"token_hash":   invitation.TokenHash,
+			"expires_at":   invitation.ExpiresAt,
+			"sent_count":   invitation.SentCount,
+			"last_sent_at": invitation.LastSentAt,
+		}).Error
+}
+
+// Accept menandai undangan diterima lalu mengaktifkan user dengan password
+// barunya. Undangan yang sudah diterima tidak bisa dipakai lagi.
+func (r *invitationRepository) Accept(invitation *entities.UserInvitation, hashedPassword string) error {
+	now := time.Now()
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entities.UserInvitation{}).
+			Where("id = ? AND token_hash = ? AND accepted_at IS NULL", invitation.ID, invitation.TokenHash).
+			Update("accepted_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return tx.Model(&entities.Users{}).
+			Where("id = ?", invitation.UserID).
+			Updates(map[string]interface{}{
+				"password":            hashedPassword,
+				"password_changed_at": now,
+				"active":              true,
+				"email_verified_at":   now,
+			}).Error
+	})
+}
@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"gorm.io/gorm"
+)
+
+type MenuRepository interface {
+	FindAll() ([]entities.Menus, error)
+	FindByRole(role string) ([]entities.Menus, error)
+	FindByID(id uint) (*entities.Menus, error)
+	FindByCode(parentID *uint, code string) (*entities.Menus, error)
+	Create(menu *entities.Menus) error
+	Update(menu *entities.Menus) error
+	Delete(id uint) error
+	UpdatePriorities(items []entities.MenuOrderItem) (bool, error)
+}
+
+type menuRepository struct {
+	db *gorm.DB
+}
+
+func NewMenuRepository(db *gorm.DB) MenuRepository {
+	return &menuRepository{db: db}
+}
+
+// FindAll mengembalikan semua menu beserta role-nya, urut berdasarkan
+// priority
+func (r *menuRepository) FindAll() ([]entities.Menus, error) {
+	var menus []entities.Menus
+	err := r.db.Preload("Roles").Order("priority asc, id asc").Find(&menus).Error
+	return menus, err
+}
+
+// FindByRole mengembalikan menu yang terdaftar untuk role di role_menus, urut
+// berdasarkan priority. Role tidak ikut dimuat.
+func (r *menuRepository) FindByRole(role string) ([]entities.Menus, error) {
+	var menus []entities.Menus
+	err := r.db.
+		Joins("JOIN role_menus ON role_menus.menu_id = menus.id").
+		Joins("JOIN roles ON roles.id = role_menus.role_id").
+		Where("roles.name = ?", role).
+		Order("menus.priority asc, menus.id asc").
+		Find(&menus).Error
+	return menus, err
+}
+
+func (r *menuRepository) FindByID(id uint) (*entities.Menus, error) {
+	var menu entities.Menus
+	err := r.db.Preload("Roles").First(&menu, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &menu, nil
+}
+
+// FindByCode mencari menu dengan kode tertentu di bawah induk yang sama
+func (r *menuRepository) FindByCode(parentID *uint, code string) (*entities.Menus, error) {
+	var menu entities.Menus
+	query := r.db.Where("code = ?", code)
+	if parentID == nil {
+		query = query.Where("parent_id IS NULL")
+	} else {
+		query = query.Where("parent_id = ?", *parentID)
+	}
+
+	err := query.First(&menu).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &menu, nil
+}
+
+func (r *menuRepository) Create(menu *entities.Menus) error {
+	return r.db.Create(menu).Error
+}
+
+// Update menyimpan field menu dan mengganti daftar role-nya dalam satu
+// transaksi
+func (r *menuRepository) Update(menu *entities.Menus) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(menu).
+			Select("parent_id", "code", "name", "path", "icons", "priority").
+			Updates(menu).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(menu).Association("Roles").Replace(menu.Roles)
+	})
+}
+
+func (r *menuRepository) Delete(id uint) error {
+	return r.db.Delete(&entities.Menus{}, id).Error
+}
+
+// UpdatePriorities mengubah priority beberapa menu sekaligus. Jika salah satu
+// menu tidak ada, tidak ada yang diubah dan hasilnya false.
+func (r *menuRepository) UpdatePriorities(items []entities.MenuOrderItem) (bool, error) {
+	updated := true
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for _, item := range items {
+			result := tx.Model(&entities.Menus{}).Where("id = ?", item.ID).Update("priority", item.Priority)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				updated = false
+				return gorm.ErrRecordNotFound
+			}
+		}
+		return nil
+	})
+	if !updated {
+		return false, nil
+	}
+	return updated, err
+}
@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"gorm.io/gorm"
+)
+
+type MFARepository interface {
+	Enable(userID uint, encryptedSecret string, codeHashes []string) error
+	Disable(userID uint) error
+	ReplaceRecoveryCodes(userID uint, codeHashes []string) error
+	UseRecoveryCode(userID uint, codeHash string) (bool, error)
+	CountUnusedRecoveryCodes(userID uint) (int64, error)
+}
+
+type mfaRepository struct {
+	db *gorm.DB
+}
+
+func NewMFARepository(db *gorm.DB) MFARepository {
+	return &mfaRepository{db: db}
+}
+
+func (r *mfaRepository) Enable(userID uint, encryptedSecret string, codeHashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&entities.Users{}).
+			Where("id = ?", userID).
+			Updates(map[string]interface{}{
+				"mfa_secret":     encryptedSecret,
+				"mfa_enabled":    true,
+				"mfa_enabled_at": time.Now(),
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		return replaceRecoveryCodes(tx, userID, codeHashes)
+	})
+}
+
+func (r *mfaRepository) Disable(userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&entities.Users{}).
+			Where("id = ?", userID).
+			Updates(map[string]interface{}{
+				"mfa_secret":     nil,
+				"mfa_enabled":    false,
+				"mfa_enabled_at": nil,
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Where("user_id = ?", userID).Delete(&entities.UserRecoveryCode{}).Error
+	})
+}
+
+func (r *mfaRepository) ReplaceRecoveryCodes(userID uint, codeHashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codeHashes)
+	})
+}
+
+// UseRecoveryCode menandai recovery code sebagai terpakai. Nilai false berarti
+// kode tidak ditemukan atau sudah pernah dipakai.
+func (r *mfaRepository) UseRecoveryCode(userID uint, codeHash string) (bool, error) {
+	result := r.db.Model(&entities.UserRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *mfaRepository) CountUnusedRecoveryCodes(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&entities.UserRecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID uint, codeHashes []string) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&entities.UserRecoveryCode{}).Error; err != nil {
+		return err
+	}
+
+	codes := make([]entities.UserRecoveryCode, 0, len(codeHashes))
+	for _, hash := range codeHashes {
+		codes = append(codes, entities.UserRecoveryCode{
+			UserID:   userID,
+			CodeHash: hash,
+		})
+	}
+	return tx.Create(&codes).Error
+}
@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"gorm.io/gorm"
+)
+
+type PasswordHistoryRepository interface {
+	Create(entry *entities.PasswordHistory) error
+	FindRecent(userID uint, limit int) ([]entities.PasswordHistory, error)
+	Prune(userID uint, keep int) error
+}
+
+type passwordHistoryRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordHistoryRepository(db *gorm.DB) PasswordHistoryRepository {
+	return &passwordHistoryRepository{db: db}
+}
+
+func (r *passwordHistoryRepository) Create(entry *entities.PasswordHistory) error {
+	return r.db.Create(entry).Error
+}
+
+// FindRecent mengembalikan limit hash terbaru milik user, terbaru lebih dulu
+func (r *passwordHistoryRepository) FindRecent(userID uint, limit int) ([]entities.PasswordHistory, error) {
+	var entries []entities.PasswordHistory
+	err := r.db.
+		Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&entries).Error
+	return entries, err
+}
+
+// Prune menghapus riwayat selain keep hash terbaru
+func (r *passwordHistoryRepository) Prune(userID uint, keep int) error {
+	recent := r.db.Model(&entities.PasswordHistory{}).
+		Select("id").
+		Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").
+		Limit(keep)
+
+	return r.db.
+		Where("user_id = ? AND id NOT IN (?)", userID, recent).
+		Delete(&entities.PasswordHistory{}).Error
+}
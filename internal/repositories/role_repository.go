@@ -0,0 +1,144 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"gorm.io/gorm"
+)
+
+type RoleRepository interface {
+	Create(role *entities.Roles) error
+	FindAll() ([]entities.Roles, error)
+	FindByID(id uint) (*entities.Roles, error)
+	FindByName(name string) (*entities.Roles, error)
+	FindByNames(names []string) ([]entities.Roles, error)
+	Update(role *entities.Roles) error
+	Delete(id uint) error
+	ReplacePermissions(role *entities.Roles, permissions []entities.Permissions) error
+	CountUsers(name string) (int64, error)
+	FindAllPermissions() ([]entities.Permissions, error)
+	FindPermissionsByNames(names []string) ([]entities.Permissions, error)
+	FindPermissionNamesByRole(name string) ([]string, error)
+	FindRoleNamesByPermission(permission string) ([]string, error)
+}
+
+type roleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepository{db: db}
+}
+
+func (r *roleRepository) Create(role *entities.Roles) error {
+	return r.db.Create(role).Error
+}
+
+func (r *roleRepository) FindAll() ([]entities.Roles, error) {
+	var roles []entities.Roles
+	err := r.db.Preload("Permissions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("permissions.name")
+	}).Order("id").Find(&roles).Error
+	return roles, err
+}
+
+func (r *roleRepository) FindByID(id uint) (*entities.Roles, error) {
+	var role entities.Roles
+	err := r.db.Preload("Permissions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("permissions.name")
+	}).First(&role, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *roleRepository) FindByName(name string) (*entities.Roles, error) {
+	var role entities.Roles
+	err := r.db.Where("name = ?", name).First(&role).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *roleRepository) FindByNames(names []string) ([]entities.Roles, error) {
+	var roles []entities.Roles
+	if len(names) == 0 {
+		return roles, nil
+	}
+	err := r.db.Where("name IN ?", names).Find(&roles).Error
+	return roles, err
+}
+
+// Update hanya menyimpan deskripsi; nama role tidak bisa diubah karena
+// dipakai sebagai referensi di users.role
+func (r *roleRepository) Update(role *entities.Roles) error {
+	return r.db.Model(role).Update("description", role.Description).Error
+}
+
+func (r *roleRepository) Delete(id uint) error {
+	return r.db.Delete(&entities.Roles{}, id).Error
+}
+
+// ReplacePermissions mengganti seluruh permission role dalam satu transaksi
+func (r *roleRepository) ReplacePermissions(role *entities.Roles, permissions []entities.Permissions) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return tx.Model(role).Association("Permissions").Replace(permissions)
+	})
+}
+
+// CountUsers ikut menghitung user di trash karena users.role tetap mereferensikan
+// roles(name) sampai user tersebut di-purge
+func (r *roleRepository) CountUsers(name string) (int64, error) {
+	var count int64
+	err := r.db.Unscoped().Model(&entities.Users{}).Where("role = ?", name).Count(&count).Error
+	return count, err
+}
+
+func (r *roleRepository) FindAllPermissions() ([]entities.Permissions, error) {
+	var permissions []entities.Permissions
+	err := r.db.Order("name").Find(&permissions).Error
+	return permissions, err
+}
+
+func (r *roleRepository) FindPermissionsByNames(names []string) ([]entities.Permissions, error) {
+	var permissions []entities.Permissions
+	if len(names) == 0 {
+		return permissions, nil
+	}
+	err := r.db.Where("name IN ?", names).Find(&permissions).Error
+	return permissions, err
+}
+
+func (r *roleRepository) FindPermissionNamesByRole(name string) ([]string, error) {
+	var names []string
+	err := r.db.Table("permissions").
+		Select("permissions.name").
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Joins("JOIN roles ON roles.id = role_permissions.role_id").
+		Where("roles.name = ?", name).
+		Order("permissions.name").
+		Pluck("permissions.name", &names).Error
+	return names, err
+}
+
+// FindRoleNamesByPermission mengembalikan nama role yang memiliki permission
+func (r *roleRepository) FindRoleNamesByPermission(permission string) ([]string, error) {
+	var names []string
+	err := r.db.Table("roles").
+		Select("roles.name").
+		Joins("JOIN role_permissions ON role_permissions.role_id = roles.id").
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
+		Where("permissions.name = ?", permission).
+		Order("roles.name").
+		Pluck("roles.name", &names).Error
+	return names, err
+}
@@ -0,0 +1,232 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"gorm.io/gorm"
+)
+
+type StaffProfileRepository interface {
+	Create(profile *entities.StaffProfile) error
+	FindByUserID(userID uint) (*entities.StaffProfile, error)
+	FindProfiles(query StaffProfileQuery) ([]entities.StaffProfile, int64, error)
+	Update(profile *entities.StaffProfile) error
+	DeleteByUserID(userID uint) (bool, error)
+	FindDuplicateNumber(profile *entities.StaffProfile) (string, error)
+	UpdateSignature(userID uint, image []byte, contentType string) (bool, error)
+	FindSignature(userID uint) ([]byte, string, error)
+	FindUnalertedLicenses(expiresBefore time.Time) ([]entities.StaffProfile, error)
+	MarkAlerted(strProfileIDs, sipProfileIDs []uint, at time.Time) error
+}
+
+// StaffProfileQuery adalah filter daftar profil. LicenseStatus expired berarti
+// STR atau SIP sudah berakhir sebelum Today; expiring berarti berakhir antara
+// Today dan ExpiringBefore.
+type StaffProfileQuery struct {
+	Profession     string
+	Search         string
+	LicenseStatus  string
+	Today          time.Time
+	ExpiringBefore time.Time
+	Offset         int
+	Limit          int
+}
+
+// staffProfileColumns adalah semua kolom kecuali gambar tanda tangan, yang
+// hanya dibaca lewat FindSignature
+var staffProfileColumns = []string{
+	"staff_profiles.id", "staff_profiles.user_id", "staff_profiles.profession", "staff_profiles.specialty",
+	"staff_profiles.str_number", "staff_profiles.str_expires_at", "staff_profiles.str_alerted_at",
+	"staff_profiles.sip_number", "staff_profiles.sip_expires_at", "staff_profiles.sip_alerted_at",
+	"staff_profiles.nip", "staff_profiles.phone", "staff_profiles.signature_content_type",
+	"staff_profiles.created_at", "staff_profiles.updated_at",
+}
+
+// staffProfileUserJoin membatasi profil ke user yang belum dihapus
+const staffProfileUserJoin = "JOIN users ON users.id = staff_profiles.user_id AND users.deleted_at IS NULL"
+
+type staffProfileRepository struct {
+	db *gorm.DB
+}
+
+func NewStaffProfileRepository(db *gorm.DB) StaffProfileRepository {
+	return &staffProfileRepository{db: db}
+}
+
+func (r *staffProfileRepository) Create(profile *entities.StaffProfile) error {
+	return r.db.Omit("User", "SignatureImage").Create(profile).Error
+}
+
+func (r *staffProfileRepository) FindByUserID(userID uint) (*entities.StaffProfile, error) {
+	var profile entities.StaffProfile
+	err := r.db.Select(staffProfileColumns).
+		Joins(staffProfileUserJoin).
+		Preload("User").
+		Where("staff_profiles.user_id = ?", userID).
+		First(&profile).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &profile, nil
+}
+
+func (r *staffProfileRepository) FindProfiles(query StaffProfileQuery) ([]entities.StaffProfile, int64, error) {
+	var (
+		profiles []entities.StaffProfile
+		total    int64
+	)
+
+	filtered := r.db.Model(&entities.StaffProfile{}).Joins(staffProfileUserJoin)
+	if query.Profession != "" {
+		filtered = filtered.Where("staff_profiles.profession = ?", query.Profession)
+	}
+	if query.Search != "" {
+		pattern := "%" + escapeLike(query.Search) + "%"
+		filtered = filtered.Where("(users.name ILIKE ? OR users.email ILIKE ? OR staff_profiles.specialty ILIKE ?)", pattern, pattern, pattern)
+	}
+	switch query.LicenseStatus {
+	case entities.LicenseExpired:
+		filtered = filtered.Where(
+			"((staff_profiles.str_number <> '' AND staff_profiles.str_expires_at < ?) OR (staff_profiles.sip_number <> '' AND staff_profiles.sip_expires_at < ?))",
+			query.Today, query.Today,
+		)
+	case entities.LicenseExpiring:
+		filtered = filtered.Where(
+			"((staff_profiles.str_number <> '' AND staff_profiles.str_expires_at >= ? AND staff_profiles.str_expires_at < ?) OR "+
+				"(staff_profiles.sip_number <> '' AND staff_profiles.sip_expires_at >= ? AND staff_profiles.sip_expires_at < ?))",
+			query.Today, query.ExpiringBefore, query.Today, query.ExpiringBefore,
+		)
+	}
+
+	if err := filtered.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := filtered.Session(&gorm.Session{}).
+		Select(staffProfileColumns).
+		Preload("User").
+		Order("users.name").Order("staff_profiles.id").
+		Offset(query.Offset).Limit(query.Limit).
+		Find(&profiles).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return profiles, total, nil
+}
+
+// Update mengganti data profil. Gambar tanda tangan tidak ikut diubah.
+func (r *staffProfileRepository) Update(profile *entities.StaffProfile) error {
+	return r.db.Model(profile).
+		Select("profession", "specialty", "str_number", "str_expires_at", "str_alerted_at",
+			"sip_number", "sip_expires_at", "sip_alerted_at", "nip", "phone", "updated_at").
+		Updates(profile).Error
+}
+
+func (r *staffProfileRepository) DeleteByUserID(userID uint) (bool, error) {
+	result := r.db.Where("user_id = ?", userID).Delete(&entities.StaffProfile{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// FindDuplicateNumber mengembalikan nama field (str_number, sip_number atau
+// nip) yang nomornya sudah dipakai profil user lain, atau string kosong
+func (r *staffProfileRepository) FindDuplicateNumber(profile *entities.StaffProfile) (string, error) {
+	numbers := []struct{ column, value string }{
+		{"str_number", profile.STRNumber},
+		{"sip_number", profile.SIPNumber},
+		{"nip", profile.NIP},
+	}
+	for _, number := range numbers {
+		if number.value == "" {
+			continue
+		}
+
+		var count int64
+		err := r.db.Model(&entities.StaffProfile{}).
+			Where(number.column+" = ? AND user_id <> ?", number.value, profile.UserID).
+			Count(&count).Error
+		if err != nil {
+			return "", err
+		}
+		if count > 0 {
+			return number.column, nil
+		}
+	}
+	return "", nil
+}
+
+// UpdateSignature mengganti gambar tanda tangan. Hasilnya false jika user
+// belum punya profil.
+func (r *staffProfileRepository) UpdateSignature(userID uint, image []byte, contentType string) (bool, error) {
+	result := r.db.Model(&entities.StaffProfile{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]any{
+			"signature_image":        image,
+			"signature_content_type": contentType,
+			"updated_at":             time.Now(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// FindSignature mengembalikan gambar tanda tangan beserta content type-nya;
+// gambar nil berarti profil belum punya tanda tangan
+func (r *staffProfileRepository) FindSignature(userID uint) ([]byte, string, error) {
+	var profile entities.StaffProfile
+	err := r.db.Select("staff_profiles.signature_image", "staff_profiles.signature_content_type").
+		Joins(staffProfileUserJoin).
+		Where("staff_profiles.user_id = ?", userID).
+		First(&profile).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", nil
+		}
+		return nil, "", err
+	}
+	return profile.SignatureImage, profile.SignatureContentType, nil
+}
+
+// FindUnalertedLicenses mengembalikan profil user aktif dengan STR atau SIP
+// yang berakhir sebelum expiresBefore dan belum pernah dilaporkan ke admin
+func (r *staffProfileRepository) FindUnalertedLicenses(expiresBefore time.Time) ([]entities.StaffProfile, error) {
+	var profiles []entities.StaffProfile
+	err := r.db.Select(staffProfileColumns).
+		Joins(staffProfileUserJoin).
+		Preload("User").
+		Where("users.active = ?", true).
+		Where(
+			"((staff_profiles.str_number <> '' AND staff_profiles.str_expires_at < ? AND staff_profiles.str_alerted_at IS NULL) OR "+
+				"(staff_profiles.sip_number <> '' AND staff_profiles.sip_expires_at < ? AND staff_profiles.sip_alerted_at IS NULL))",
+			expiresBefore, expiresBefore,
+		).
+		Order("users.name").Order("staff_profiles.id").
+		Find(&profiles).Error
+	return profiles, err
+}
+
+// MarkAlerted menandai STR dan SIP yang sudah dilaporkan ke admin agar tidak
+// dilaporkan lagi sampai tanggal berakhirnya diperbarui
+func (r *staffProfileRepository) MarkAlerted(strProfileIDs, sipProfileIDs []uint, at time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if len(strProfileIDs) > 0 {
+			err := tx.Model(&entities.StaffProfile{}).
+				Where("id IN ?", strProfileIDs).
+				UpdateColumn("str_alerted_at", at).Error
+			if err != nil {
+				return err
+			}
+		}
+		if len(sipProfileIDs) > 0 {
+			err := tx.Model(&entities.StaffProfile{}).
+				Where("id IN ?", sipProfileIDs).
+				UpdateColumn("sip_alerted_at", at).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -3,20 +3,31 @@ package repositories
 import (
 	"errors"
 	"strings"
+	"time"
 
 	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
-	"github.com/anieswahdie1/ara-medika-api.git/internal/models/requests"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository interface {
 	Create(user *entities.Users) error
 	FindByID(id uint) (*entities.Users, error)
 	FindByEmail(email string) (*entities.Users, error)
+	FindRegisteredEmails(emails []string) ([]string, error)
 	Update(user *entities.Users) error
-	Delete(id uint) error
-	FindUsers(request requests.BaseGetListRequest) ([]entities.Users, error)
-	FindAllMenus(roles string) ([]entities.Menus, error)
+	UpdatePasswordHash(userID uint, hashedPassword string) error
+	UpdateRole(userID uint, role entities.Role) (bool, error)
+	SetActive(userID uint, active bool) (bool, error)
+	Delete(id uint) (bool, error)
+	UpdateLastLogin(userID uint, at time.Time) error
+	FindUsers(query UserListQuery) ([]entities.Users, int64, error)
+	StreamUsers(query UserListQuery, fn func(user *entities.Users) error) error
+	FindDeletedByID(id uint) (*entities.Users, error)
+	FindDeleted(search string, offset, limit int) ([]entities.Users, int64, error)
+	Restore(id uint) (bool, error)
+	Purge(id uint) (bool, error)
+	PurgeDeletedBefore(before time.Time, limit int) ([]entities.Users, error)
 }
 
 type userRepository struct {
@@ -43,9 +54,11 @@ func (r *userRepository) FindByID(id uint) (*entities.Users, error) {
 	return &user, nil
 }
 
+// FindByEmail mencari user tanpa membedakan huruf besar/kecil, sesuai index
+// unik LOWER(email)
 func (r *userRepository) FindByEmail(email string) (*entities.Users, error) {
 	var user entities.Users
-	err := r.db.Where("email = ?", email).First(&user).Error
+	err := r.db.Where("LOWER(email) = LOWER(?)", email).First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -55,58 +68,312 @@ func (r *userRepository) FindByEmail(email string) (*entities.Users, error) {
 	return &user, nil
 }
 
+// FindRegisteredEmails mengembalikan email (huruf kecil) dari daftar yang sudah
+// dipakai user. Email user di trash boleh dipakai lagi sehingga tidak ikut.
+func (r *userRepository) FindRegisteredEmails(emails []string) ([]string, error) {
+	var registered []string
+	if len(emails) == 0 {
+		return registered, nil
+	}
+
+	lowered := make([]string, len(emails))
+	for i, email := range emails {
+		lowered[i] = strings.ToLower(email)
+	}
+
+	err := r.db.Model(&entities.Users{}).
+		Where("LOWER(email) IN ?", lowered).
+		Pluck("LOWER(email)", &registered).Error
+	return registered, err
+}
+
 func (r *userRepository) Update(user *entities.Users) error {
 	return r.db.Save(user).Error
 }
 
-func (r *userRepository) Delete(id uint) error {
-	return r.db.Delete(&entities.Users{}, id).Error
+// UpdatePasswordHash hanya mengganti kolom password, dipakai saat hash lama
+// di-hash ulang tanpa mengganti password user
+func (r *userRepository) UpdatePasswordHash(userID uint, hashedPassword string) error {
+	return r.db.Model(&entities.Users{}).
+		Where("id = ?", userID).
+		Update("password", hashedPassword).Error
 }
 
-func (r *userRepository) FindUsers(request requests.BaseGetListRequest) ([]entities.Users, error) {
-	var (
-		users  []entities.Users
-		offset int
-	)
+// UpdateLastLogin tidak mengubah updated_at karena login bukan perubahan data
+// user
+func (r *userRepository) UpdateLastLogin(userID uint, at time.Time) error {
+	return r.db.Model(&entities.Users{}).
+		Where("id = ?", userID).
+		UpdateColumn("last_login_at", at).Error
+}
 
-	offset = (request.Page - 1) * request.Limit
+// UpdateRole mengganti role user. Jika user adalah super_admin aktif terakhir
+// dan role barunya bukan super_admin, role tidak diubah dan hasilnya false.
+func (r *userRepository) UpdateRole(userID uint, role entities.Role) (bool, error) {
+	return r.updateKeepingSuperAdmin(userID, role != entities.SuperAdmin, func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&entities.Users{}).Where("id = ?", userID).Update("role", role)
+	})
+}
 
-	queryBuilder := r.db.
-		Limit(request.Limit).
-		Offset(offset).
-		Order("created_at DESC").
-		Where("active = ?", "true").
-		Find(&users)
+// SetActive mengubah status aktif user. Super_admin aktif terakhir tidak bisa
+// dinonaktifkan; hasilnya false.
+func (r *userRepository) SetActive(userID uint, active bool) (bool, error) {
+	return r.updateKeepingSuperAdmin(userID, !active, func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&entities.Users{}).Where("id = ?", userID).Update("active", active)
+	})
+}
 
-	if request.Search != "" {
-		queryBuilder = queryBuilder.Where("name ILIKE ?", "%"+request.Search+"%")
-	}
+// Delete menghapus user (soft delete). Super_admin aktif terakhir tidak bisa
+// dihapus; hasilnya false.
+func (r *userRepository) Delete(id uint) (bool, error) {
+	return r.updateKeepingSuperAdmin(id, true, func(tx *gorm.DB) *gorm.DB {
+		return tx.Delete(&entities.Users{}, id)
+	})
+}
 
-	if err := queryBuilder.Error; err != nil {
+// FindDeletedByID mencari user yang ada di trash (sudah di-soft-delete)
+func (r *userRepository) FindDeletedByID(id uint) (*entities.Users, error) {
+	var user entities.Users
+	err := r.db.Unscoped().Where("deleted_at IS NOT NULL").First(&user, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
 		return nil, err
 	}
-
-	return users, nil
+	return &user, nil
 }
 
-func (repos *userRepository) FindAllMenus(roles string) ([]entities.Menus, error) {
+// FindDeleted mengembalikan isi trash, yang terakhir dihapus lebih dulu
+func (r *userRepository) FindDeleted(search string, offset, limit int) ([]entities.Users, int64, error) {
 	var (
-		menus, newListMenus []entities.Menus
+		users []entities.Users
+		total int64
 	)
-	err := repos.db.Order("priority asc").Find(&menus).Error
+
+	deleted := r.db.Unscoped().Model(&entities.Users{}).Where("deleted_at IS NOT NULL")
+	if search != "" {
+		pattern := "%" + escapeLike(search) + "%"
+		deleted = deleted.Where("(name ILIKE ? OR email ILIKE ?)", pattern, pattern)
+	}
+
+	if err := deleted.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := deleted.Session(&gorm.Session{}).
+		Order("deleted_at DESC").Order("id DESC").
+		Offset(offset).Limit(limit).
+		Find(&users).Error
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
+	return users, total, nil
+}
+
+// Restore mengeluarkan user dari trash. Hasilnya false jika user tidak ada di
+// trash.
+func (r *userRepository) Restore(id uint) (bool, error) {
+	result := r.db.Unscoped().Model(&entities.Users{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Purge menghapus permanen user yang ada di trash beserta data turunannya
+// (ON DELETE CASCADE). User yang belum di-soft-delete tidak ikut terhapus.
+func (r *userRepository) Purge(id uint) (bool, error) {
+	result := r.db.Unscoped().
+		Where("deleted_at IS NOT NULL").
+		Delete(&entities.Users{}, id)
+	return result.RowsAffected > 0, result.Error
+}
+
+// PurgeDeletedBefore menghapus permanen paling banyak limit user yang masuk
+// trash sebelum waktu tertentu dan mengembalikan id serta email user tersebut
+func (r *userRepository) PurgeDeletedBefore(before time.Time, limit int) ([]entities.Users, error) {
+	var purged []entities.Users
+	expired := r.db.Unscoped().Model(&entities.Users{}).
+		Select("id").
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Order("deleted_at").
+		Limit(limit)
+
+	err := r.db.Unscoped().
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}, {Name: "email"}}}).
+		Where("id IN (?)", expired).
+		Delete(&purged).Error
+	return purged, err
+}
+
+// updateKeepingSuperAdmin menjalankan update dalam transaksi. Jika
+// removesSuperAdmin bernilai true dan userID adalah super_admin aktif
+// terakhir, update dibatalkan dan hasilnya false. Baris super_admin dikunci
+// agar dua perubahan bersamaan tidak sama-sama lolos.
+func (r *userRepository) updateKeepingSuperAdmin(userID uint, removesSuperAdmin bool, update func(tx *gorm.DB) *gorm.DB) (bool, error) {
+	updated := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if removesSuperAdmin {
+			var superAdmins []entities.Users
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Select("id", "active").
+				Where("role = ?", entities.SuperAdmin).
+				Find(&superAdmins).Error
+			if err != nil {
+				return err
+			}
 
-	if len(menus) > 0 {
-		for _, menu := range menus {
-			newMenu := strings.Split(menu.CanAccessBy, ",")
-			for _, item := range newMenu {
-				if item == roles {
-					newListMenus = append(newListMenus, menu)
+			isSuperAdmin, others := false, 0
+			for _, superAdmin := range superAdmins {
+				if superAdmin.ID == userID {
+					isSuperAdmin = true
+				} else if superAdmin.Active {
+					others++
 				}
 			}
+			if isSuperAdmin && others == 0 {
+				return nil
+			}
+		}
+
+		result := update(tx)
+		if result.Error != nil {
+			return result.Error
+		}
+		updated = result.RowsAffected > 0
+		return nil
+	})
+	return updated, err
+}
+
+// UserListQuery adalah query daftar user yang sudah divalidasi service. Sort
+// selalu diakhiri kolom unik (id) agar urutan stabil; After berisi nilai kolom
+// Sort dari baris terakhir halaman sebelumnya untuk paginasi keyset.
+type UserListQuery struct {
+	Search      string
+	Roles       []string
+	Active      *bool
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	Sort        []SortField
+	After       []any
+	Offset      int
+	Limit       int
+	CountTotal  bool
+}
+
+// SortField adalah kolom pengurutan. Column harus berasal dari daftar kolom
+// yang diizinkan karena disisipkan langsung ke SQL.
+type SortField struct {
+	Column string
+	Desc   bool
+}
+
+// FindUsers mengembalikan user sesuai query. Total hanya dihitung jika
+// CountTotal bernilai true.
+func (r *userRepository) FindUsers(query UserListQuery) ([]entities.Users, int64, error) {
+	var (
+		users []entities.Users
+		total int64
+	)
+
+	filtered := r.filterUsers(query)
+
+	if query.CountTotal {
+		if err := filtered.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			return nil, 0, err
+		}
+	}
+
+	page := filtered.Session(&gorm.Session{})
+	if len(query.After) > 0 {
+		condition, args := keysetCondition(query.Sort, query.After)
+		page = page.Where(condition, args...)
+	}
+
+	err := orderUsers(page, query.Sort).Offset(query.Offset).Limit(query.Limit).Find(&users).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// StreamUsers memanggil fn untuk setiap user yang cocok dengan filter query,
+// baris demi baris dari cursor database, sehingga hasil sebesar apa pun tidak
+// dimuat ke memori. After, Offset dan Limit diabaikan. Jika fn mengembalikan
+// error, iterasi berhenti dan error tersebut dikembalikan.
+func (r *userRepository) StreamUsers(query UserListQuery, fn func(user *entities.Users) error) error {
+	db := orderUsers(r.filterUsers(query), query.Sort)
+	rows, err := db.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user entities.Users
+		if err := db.ScanRows(rows, &user); err != nil {
+			return err
+		}
+		if err := fn(&user); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// filterUsers menerapkan filter pencarian, role, status dan tanggal dibuat
+func (r *userRepository) filterUsers(query UserListQuery) *gorm.DB {
+	filtered := r.db.Model(&entities.Users{})
+	if query.Search != "" {
+		pattern := "%" + escapeLike(query.Search) + "%"
+		filtered = filtered.Where("(name ILIKE ? OR email ILIKE ?)", pattern, pattern)
+	}
+	if len(query.Roles) > 0 {
+		filtered = filtered.Where("role IN ?", query.Roles)
+	}
+	if query.Active != nil {
+		filtered = filtered.Where("active = ?", *query.Active)
+	}
+	if !query.CreatedFrom.IsZero() {
+		filtered = filtered.Where("created_at >= ?", query.CreatedFrom)
+	}
+	if !query.CreatedTo.IsZero() {
+		filtered = filtered.Where("created_at < ?", query.CreatedTo)
+	}
+	return filtered
+}
+
+func orderUsers(db *gorm.DB, sort []SortField) *gorm.DB {
+	for _, field := range sort {
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: field.Column}, Desc: field.Desc})
+	}
+	return db
+}
+
+// keysetCondition membentuk kondisi "setelah baris terakhir" untuk urutan
+// campuran naik/turun: (a > ?) OR (a = ? AND b < ?) OR ...
+func keysetCondition(sort []SortField, after []any) (string, []any) {
+	clauses := make([]string, 0, len(sort))
+	args := make([]any, 0, len(sort)*(len(sort)+1)/2)
+	for i, field := range sort {
+		parts := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			parts = append(parts, sort[j].Column+" = ?")
+			args = append(args, after[j])
+		}
+		op := " > ?"
+		if field.Desc {
+			op = " < ?"
 		}
+		parts = append(parts, field.Column+op)
+		args = append(args, after[i])
+		clauses = append(clauses, "("+strings.Join(parts, " AND ")+")")
 	}
-	return newListMenus, nil
+	return "(" + strings.Join(clauses, " OR ") + ")", args
+}
+
+// escapeLike meng-escape karakter wildcard LIKE agar input dicari apa adanya
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
 }
@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKeysetCondition(t *testing.T) {
+	tests := []struct {
+		name     string
+		sort     []SortField
+		after    []any
+		want     string
+		wantArgs []any
+	}{
+		{
+			name:     "single ascending column",
+			sort:     []SortField{{Column: "id"}},
+			after:    []any{int64(7)},
+			want:     "((id > ?))",
+			wantArgs: []any{int64(7)},
+		},
+		{
+			name:     "single descending column",
+			sort:     []SortField{{Column: "id", Desc: true}},
+			after:    []any{int64(7)},
+			want:     "((id < ?))",
+			wantArgs: []any{int64(7)},
+		},
+		{
+			name:     "descending with id tie-breaker",
+			sort:     []SortField{{Column: "created_at", Desc: true}, {Column: "id", Desc: true}},
+			after:    []any{"t", int64(7)},
+			want:     "((created_at < ?) OR (created_at = ? AND id < ?))",
+			wantArgs: []any{"t", "t", int64(7)},
+		},
+		{
+			name:     "mixed directions",
+			sort:     []SortField{{Column: "role"}, {Column: "name", Desc: true}, {Column: "id"}},
+			after:    []any{"admin", "Budi", int64(3)},
+			want:     "((role > ?) OR (role = ? AND name < ?) OR (role = ? AND name = ? AND id > ?))",
+			wantArgs: []any{"admin", "admin", "Budi", "admin", "Budi", int64(3)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, args := keysetCondition(tt.sort, tt.after)
+			if got != tt.want {
+				t.Errorf("condition = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"budi", "budi"},
+		{"100%", `100\%`},
+		{"a_b", `a\_b`},
+		{`c:\temp`, `c:\\temp`},
+	}
+
+	for _, tt := range tests {
+		if got := escapeLike(tt.input); got != tt.want {
+			t.Errorf("escapeLike(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
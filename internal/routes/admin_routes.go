@@ -0,0 +1,77 @@
+package routes
+
+import (
+	"github.com/anieswahdie1/ara-medika-api.git/internal/controllers"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/middlewares"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupAdminRoutes(
+	router *gin.Engine,
+	authMiddleware gin.HandlerFunc,
+	authController *controllers.AuthController,
+	sessionController *controllers.SessionController,
+	apiKeyController *controllers.APIKeyController,
+	impersonationController *controllers.ImpersonationController,
+	auditController *controllers.AuditController,
+	invitationController *controllers.InvitationController,
+	roleController *controllers.RoleController,
+	userController *controllers.UserController,
+	menuController *controllers.MenuController,
+) {
+	adminGroup := router.Group("/admin")
+	adminGroup.Use(authMiddleware)
+	adminGroup.Use(middlewares.RequireScope(entities.ScopeAdmin))
+	adminGroup.Use(middlewares.NoImpersonation())
+	{
+		sessionGroup := adminGroup.Group("/users/:userID/sessions", middlewares.RequirePermission(entities.PermissionSessionsManage))
+		sessionGroup.GET("", sessionController.ListUserSessions)
+		sessionGroup.DELETE("", sessionController.RevokeAllUserSessions)
+		sessionGroup.DELETE("/:id", sessionController.RevokeUserSession)
+		adminGroup.POST("/users/:userID/unlock", middlewares.RequirePermission(entities.PermissionUsersWrite), authController.UnlockUser)
+		adminGroup.PUT("/users/:userID/role",
+			middlewares.SessionOnly(),
+			middlewares.RequirePermission(entities.PermissionUsersWrite),
+			userController.ChangeUserRole,
+		)
+
+		// API key hanya bisa dikelola oleh admin yang login, bukan oleh API key lain
+		apiKeyGroup := adminGroup.Group("/api-keys", middlewares.SessionOnly(), middlewares.RequirePermission(entities.PermissionAPIKeysManage))
+		apiKeyGroup.POST("", apiKeyController.CreateAPIKey)
+		apiKeyGroup.GET("", apiKeyController.ListAPIKeys)
+		apiKeyGroup.DELETE("/:id", apiKeyController.RevokeAPIKey)
+
+		adminGroup.GET("/audit-logs", middlewares.RequirePermission(entities.PermissionAuditLogsRead), auditController.ListAuditLogs)
+
+		invitationGroup := adminGroup.Group("/invitations", middlewares.SessionOnly(), middlewares.RequirePermission(entities.PermissionInvitationsManage))
+		invitationGroup.POST("", invitationController.InviteUser)
+		invitationGroup.GET("", invitationController.ListInvitations)
+		invitationGroup.POST("/:id/resend", invitationController.ResendInvitation)
+
+		roleGroup := adminGroup.Group("", middlewares.SessionOnly(), middlewares.RequirePermission(entities.PermissionRolesManage))
+		roleGroup.GET("/roles", roleController.ListRoles)
+		roleGroup.POST("/roles", roleController.CreateRole)
+		roleGroup.GET("/roles/:id", roleController.GetRole)
+		roleGroup.PUT("/roles/:id", roleController.UpdateRole)
+		roleGroup.DELETE("/roles/:id", roleController.DeleteRole)
+		roleGroup.PUT("/roles/:id/permissions", roleController.SetRolePermissions)
+		roleGroup.GET("/permissions", roleController.ListPermissions)
+
+		menuGroup := adminGroup.Group("/menus", middlewares.SessionOnly(), middlewares.RequirePermission(entities.PermissionMenusManage))
+		menuGroup.GET("", menuController.ListMenus)
+		menuGroup.POST("", menuController.CreateMenu)
+		menuGroup.PUT("/reorder", menuController.ReorderMenus)
+		menuGroup.GET("/:id", menuController.GetMenu)
+		menuGroup.PUT("/:id", menuController.UpdateMenu)
+		menuGroup.DELETE("/:id", menuController.DeleteMenu)
+
+		// Impersonasi tetap khusus super_admin, bukan permission yang bisa
+		// diberikan ke role lain
+		adminGroup.POST("/impersonate/:userID",
+			middlewares.RoleMiddleware(string(entities.SuperAdmin)),
+			middlewares.SessionOnly(),
+			impersonationController.Impersonate,
+		)
+	}
+}
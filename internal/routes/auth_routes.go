@@ -1,29 +1,49 @@
 package routes
 
 import (
-	"github.com/anieswahdie1/ara-medika-api.git/internal/configs"
 	"github.com/anieswahdie1/ara-medika-api.git/internal/controllers"
 	"github.com/anieswahdie1/ara-medika-api.git/internal/middlewares"
 	"github.com/gin-gonic/gin"
-	"github.com/redis/go-redis/v9"
 )
 
 func SetupAuthRoutes(
 	router *gin.Engine,
-	cfg *configs.Config,
-	redisClient *redis.Client,
+	authMiddleware gin.HandlerFunc,
 	authController *controllers.AuthController,
+	sessionController *controllers.SessionController,
+	mfaController *controllers.MFAController,
+	passwordController *controllers.PasswordController,
+	impersonationController *controllers.ImpersonationController,
+	invitationController *controllers.InvitationController,
 ) {
+	router.GET("/.well-known/jwks.json", authController.JWKS)
+
 	authGroup := router.Group("/auth")
 	{
 		authGroup.POST("/login", authController.Login)
+		authGroup.POST("/login/mfa", authController.LoginMFA)
+		authGroup.POST("/login/mfa/setup", authController.LoginMFASetup)
+		authGroup.POST("/login/password-change", authController.LoginPasswordChange)
+		authGroup.POST("/password/forgot", passwordController.ForgotPassword)
+		authGroup.POST("/password/reset", passwordController.ResetPassword)
+		authGroup.POST("/invitations/accept", invitationController.AcceptInvitation)
 		authGroup.POST("/refresh", authController.RefreshToken)
 
-		// protected routes
-		authGroup.Use(middlewares.AuthMiddleware(cfg, redisClient))
+		// protected routes, hanya untuk user yang login (bukan API key)
+		authGroup.Use(authMiddleware, middlewares.SessionOnly())
 		{
 			authGroup.POST("/logout", authController.Logout)
 			authGroup.GET("/me", authController.GetCurrentUser)
+			authGroup.GET("/sessions", sessionController.ListSessions)
+			authGroup.DELETE("/sessions/:id", sessionController.RevokeSession)
+			authGroup.POST("/impersonation/end", impersonationController.EndImpersonation)
+
+			mfaGroup := authGroup.Group("/mfa", middlewares.NoImpersonation())
+			mfaGroup.GET("", mfaController.Status)
+			mfaGroup.POST("/enroll", mfaController.Enroll)
+			mfaGroup.POST("/enroll/verify", mfaController.ConfirmEnrollment)
+			mfaGroup.POST("/disable", mfaController.Disable)
+			mfaGroup.POST("/recovery-codes", mfaController.RegenerateRecoveryCodes)
 		}
 	}
 }
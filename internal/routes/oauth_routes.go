@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/anieswahdie1/ara-medika-api.git/internal/configs"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/controllers"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupOAuthRoutes(
+	router *gin.Engine,
+	cfg *configs.Config,
+	oauthController *controllers.OAuthController,
+) {
+	oauthGroup := router.Group("/oauth")
+	oauthGroup.Use(middlewares.OAuthClientAuth(cfg))
+	{
+		oauthGroup.POST("/introspect", oauthController.Introspect)
+		oauthGroup.POST("/revoke", oauthController.Revoke)
+	}
+}
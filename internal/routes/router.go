@@ -4,6 +4,7 @@ import (
 	"github.com/anieswahdie1/ara-medika-api.git/internal/configs"
 	"github.com/anieswahdie1/ara-medika-api.git/internal/controllers"
 	"github.com/anieswahdie1/ara-medika-api.git/internal/middlewares"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/services"
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
@@ -14,20 +15,52 @@ func InitRouter(
 	redisClient *redis.Client,
 	logger *logrus.Logger,
 	userController *controllers.UserController,
+	userImportController *controllers.UserImportController,
 	authController *controllers.AuthController,
+	sessionController *controllers.SessionController,
+	mfaController *controllers.MFAController,
+	passwordController *controllers.PasswordController,
+	apiKeyController *controllers.APIKeyController,
+	oauthController *controllers.OAuthController,
+	impersonationController *controllers.ImpersonationController,
+	auditController *controllers.AuditController,
+	invitationController *controllers.InvitationController,
+	roleController *controllers.RoleController,
+	menuController *controllers.MenuController,
+	staffProfileController *controllers.StaffProfileController,
+	apiKeyService services.APIKeyService,
+	roleService services.RoleService,
+	auditService services.AuditService,
 ) *gin.Engine {
 
 	router := gin.New()
 	router.Use(gin.Recovery())
 
+	// Tanpa daftar ini gin mempercayai X-Forwarded-For dari siapa pun, sehingga
+	// IP client bisa dipalsukan
+	var trustedProxies []string
+	if len(cfg.TrustedProxies) > 0 {
+		trustedProxies = cfg.TrustedProxies
+	}
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		logger.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+
 	// Global middleware
 	router.Use(middlewares.ErrorHandler())
 	router.Use(middlewares.CORSMiddleware())
 	router.Use(middlewares.RequestLoggerMiddleware(logger))
+	router.Use(middlewares.ImpersonationAudit(auditService, logger))
+
+	// Middleware autentikasi (JWT atau API key) dipakai bersama oleh semua route
+	authMiddleware := middlewares.AuthMiddleware(redisClient, apiKeyService, roleService)
 
 	// Setup routes
-	SetupUserRoutes(router, cfg, redisClient, userController)
-	SetupAuthRoutes(router, cfg, redisClient, authController)
+	SetupUserRoutes(router, authMiddleware, userController, userImportController)
+	SetupAuthRoutes(router, authMiddleware, authController, sessionController, mfaController, passwordController, impersonationController, invitationController)
+	SetupAdminRoutes(router, authMiddleware, authController, sessionController, apiKeyController, impersonationController, auditController, invitationController, roleController, userController, menuController)
+	SetupOAuthRoutes(router, cfg, oauthController)
+	SetupStaffProfileRoutes(router, authMiddleware, staffProfileController)
 
 	return router
 }
@@ -0,0 +1,41 @@
+package routes
+
+import (
+	"github.com/anieswahdie1/ara-medika-api.git/internal/controllers"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/middlewares"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupStaffProfileRoutes(
+	router *gin.Engine,
+	authMiddleware gin.HandlerFunc,
+	staffProfileController *controllers.StaffProfileController,
+) {
+	staffGroup := router.Group("/staff-profiles")
+	staffGroup.Use(authMiddleware)
+	{
+		// Profil milik user yang login
+		staffGroup.GET("/me", staffProfileController.GetMyStaffProfile)
+
+		// NIP, nomor telepon dan tanda tangan staf hanya untuk API key yang
+		// secara eksplisit diberi scope staff_profiles:read
+		readGroup := staffGroup.Group("",
+			middlewares.RequireScope(entities.ScopeStaffProfilesRead),
+			middlewares.RequirePermission(entities.PermissionStaffProfilesRead),
+		)
+		readGroup.GET("", staffProfileController.ListStaffProfiles)
+		readGroup.GET("/:userID", staffProfileController.GetStaffProfile)
+		readGroup.GET("/:userID/signature", staffProfileController.GetSignature)
+
+		// Perubahan data profesi dan tanda tangan hanya lewat session login
+		writeGroup := staffGroup.Group("",
+			middlewares.SessionOnly(),
+			middlewares.RequirePermission(entities.PermissionStaffProfilesWrite),
+		)
+		writeGroup.POST("/:userID", staffProfileController.CreateStaffProfile)
+		writeGroup.PUT("/:userID", staffProfileController.UpdateStaffProfile)
+		writeGroup.DELETE("/:userID", staffProfileController.DeleteStaffProfile)
+		writeGroup.PUT("/:userID/signature", staffProfileController.UploadSignature)
+	}
+}
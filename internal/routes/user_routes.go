@@ -1,32 +1,91 @@
 package routes
 
 import (
-	"github.com/anieswahdie1/ara-medika-api.git/internal/configs"
 	"github.com/anieswahdie1/ara-medika-api.git/internal/controllers"
 	"github.com/anieswahdie1/ara-medika-api.git/internal/middlewares"
 	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
 	"github.com/gin-gonic/gin"
-	"github.com/redis/go-redis/v9"
 )
 
 func SetupUserRoutes(
 	router *gin.Engine,
-	cfg *configs.Config,
-	redisClient *redis.Client,
+	authMiddleware gin.HandlerFunc,
 	userController *controllers.UserController,
+	userImportController *controllers.UserImportController,
 ) {
 	userGroup := router.Group("/users")
-	userGroup.Use(middlewares.AuthMiddleware(cfg, redisClient))
+	userGroup.Use(authMiddleware)
 	{
-		// Routes untuk semua user terautentikasi
-		userGroup.GET("/me", userController.GetUserByID)
-		userGroup.PUT("/me", userController.UpdateUser)
-		// Routes untuk admin dan super_admin
-		userGroup.Use(middlewares.RoleMiddleware(string(entities.Admin), string(entities.SuperAdmin)))
-		{
-			userGroup.POST("/", userController.CreateUser)
-			userGroup.GET("/", userController.GetListUser)
-		}
+		// Routes untuk semua user terautentikasi, selalu atas user yang login
+		userGroup.GET("/me", userController.GetMe)
+		userGroup.PUT("/me", middlewares.SessionOnly(), middlewares.NoImpersonation(), userController.UpdateMe)
+		userGroup.POST("/me/password", middlewares.SessionOnly(), middlewares.NoImpersonation(), userController.ChangeMyPassword)
+		// Routes untuk role dengan permission pengelolaan user
+		userGroup.POST("/",
+			middlewares.RequirePermission(entities.PermissionUsersWrite),
+			middlewares.RequireScope(entities.ScopeUsersWrite),
+			userController.CreateUser,
+		)
+		userGroup.GET("/",
+			middlewares.RequirePermission(entities.PermissionUsersRead),
+			middlewares.RequireScope(entities.ScopeUsersRead, entities.ScopeUsersWrite),
+			userController.GetListUser,
+		)
+		userGroup.GET("/:id",
+			middlewares.RequirePermission(entities.PermissionUsersRead),
+			middlewares.RequireScope(entities.ScopeUsersRead, entities.ScopeUsersWrite),
+			userController.GetUserByID,
+		)
+		userGroup.PUT("/:id",
+			middlewares.RequirePermission(entities.PermissionUsersWrite),
+			middlewares.RequireScope(entities.ScopeUsersWrite),
+			userController.UpdateUser,
+		)
 
+		// Ekspor berisi data seluruh staf sehingga hanya lewat session login
+		userGroup.GET("/export",
+			middlewares.SessionOnly(),
+			middlewares.NoImpersonation(),
+			middlewares.RequirePermission(entities.PermissionUsersExport),
+			userController.ExportUsers,
+		)
+
+		// Import massal membuat user sekaligus mengirim undangan
+		importGroup := userGroup.Group("/import",
+			middlewares.SessionOnly(),
+			middlewares.RequirePermission(entities.PermissionUsersWrite),
+			middlewares.RequirePermission(entities.PermissionInvitationsManage),
+		)
+		importGroup.POST("", userImportController.ImportUsers)
+		importGroup.GET("/:importID/report", userImportController.DownloadImportReport)
+
+		// User yang dihapus bisa dilihat dan di-restore sampai di-purge
+		trashGroup := userGroup.Group("/trash", middlewares.SessionOnly())
+		trashGroup.GET("",
+			middlewares.RequirePermission(entities.PermissionUsersRead),
+			userController.ListDeletedUsers,
+		)
+		trashGroup.POST("/:id/restore",
+			middlewares.NoImpersonation(),
+			middlewares.RequirePermission(entities.PermissionUsersWrite),
+			userController.RestoreUser,
+		)
+		trashGroup.DELETE("/:id",
+			middlewares.NoImpersonation(),
+			middlewares.RequirePermission(entities.PermissionUsersPurge),
+			userController.PurgeUser,
+		)
+
+		// Aksi yang mengunci user keluar hanya lewat session login dan tidak
+		// bisa dilakukan saat impersonasi
+		accountGroup := userGroup.Group("/:id",
+			middlewares.SessionOnly(),
+			middlewares.NoImpersonation(),
+			middlewares.RequirePermission(entities.PermissionUsersWrite),
+		)
+		accountGroup.DELETE("", userController.DeleteUser)
+		accountGroup.POST("/deactivate", userController.DeactivateUser)
+		accountGroup.POST("/reactivate", userController.ReactivateUser)
+		accountGroup.POST("/reset-password", userController.ResetUserPassword)
 	}
 }
@@ -0,0 +1,445 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/configs"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	PolicyEffectAllow = "allow"
+	PolicyEffectDeny  = "deny"
+)
+
+var ErrAccessDenied = errors.New("access denied")
+
+// AccessSubject adalah user yang melakukan aksi. Attributes berisi atribut
+// tambahan, misalnya branch_id atau specialty.
+type AccessSubject struct {
+	ID             uint
+	Role           entities.Role
+	Permissions    []string
+	ImpersonatorID *uint
+	Attributes     map[string]any
+}
+
+// AccessResource adalah data yang diakses, misalnya encounter dengan atribut
+// author_id atau user dengan atribut branch_id
+type AccessResource struct {
+	Type       string
+	ID         string
+	Attributes map[string]any
+}
+
+// AccessRequest adalah pertanyaan "bolehkah Subject melakukan Action terhadap
+// Resource". Environment berisi informasi request (ip, method, path,
+// user_agent) yang dipakai untuk kondisi dan audit log.
+type AccessRequest struct {
+	Subject     AccessSubject
+	Action      string
+	Resource    AccessResource
+	Environment map[string]string
+}
+
+type AccessDecision struct {
+	Allowed bool   `json:"allowed"`
+	Policy  string `json:"policy,omitempty"`
+	Reason  string `json:"reason"`
+}
+
+// AccessDeniedError dikembalikan Authorize jika akses ditolak dan cocok dengan
+// ErrAccessDenied lewat errors.Is
+type AccessDeniedError struct {
+	Decision AccessDecision
+}
+
+func (e *AccessDeniedError) Error() string {
+	return "access denied: " + e.Decision.Reason
+}
+
+func (e *AccessDeniedError) Is(target error) bool {
+	return target == ErrAccessDenied
+}
+
+// AccessPolicy berlaku untuk request yang Action, tipe Resource dan role
+// Subject-nya cocok, serta semua Conditions dan Condition-nya terpenuhi.
+// Actions dan Resources menerima "*" dan prefix seperti "encounters:*"; Roles
+// kosong berarti semua role. Condition hanya untuk policy yang didefinisikan
+// di kode.
+type AccessPolicy struct {
+	Name        string                   `json:"name"`
+	Description string                   `json:"description"`
+	Effect      string                   `json:"effect"`
+	Actions     []string                 `json:"actions"`
+	Resources   []string                 `json:"resources"`
+	Roles       []string                 `json:"roles"`
+	Conditions  []AccessCondition        `json:"conditions"`
+	Condition   func(AccessRequest) bool `json:"-"`
+}
+
+// AccessCondition membandingkan dua atribut, atau atribut dengan nilai tetap
+// jika Right kosong. Atribut ditulis sebagai "subject.id", "subject.role",
+// "subject.<atribut>", "resource.id", "resource.<atribut>" atau "env.<nama>".
+// Operator: eq, ne, in (Value berupa array) dan exists.
+//
+// Contoh policy di ACCESS_POLICY_FILE:
+//
+//	[{
+//	  "name": "doctor-own-encounters",
+//	  "description": "doctors may only edit encounters they authored",
+//	  "effect": "allow",
+//	  "actions": ["encounters:update"],
+//	  "resources": ["encounter"],
+//	  "roles": ["doctor"],
+//	  "conditions": [{"left": "subject.id", "op": "eq", "right": "resource.author_id"}]
+//	}]
+type AccessCondition struct {
+	Left  string `json:"left"`
+	Op    string `json:"op"`
+	Right string `json:"right,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+type AccessPolicyService interface {
+	// Evaluate mengembalikan keputusan tanpa efek samping
+	Evaluate(req AccessRequest) AccessDecision
+	// Authorize mengevaluasi request dan mencatat penolakan di audit log.
+	// Mengembalikan *AccessDeniedError jika akses ditolak.
+	Authorize(req AccessRequest) error
+}
+
+type accessPolicyService struct {
+	policies     []AccessPolicy
+	auditService AuditService
+	logger       *logrus.Logger
+}
+
+// defaultAccessPolicies adalah policy yang selalu berlaku. Policy dari file
+// ditambahkan setelahnya.
+func defaultAccessPolicies(roleService RoleService, logger *logrus.Logger) []AccessPolicy {
+	return []AccessPolicy{
+		{
+			Name:        "super-admin",
+			Description: "super_admin may perform any action",
+			Effect:      PolicyEffectAllow,
+			Actions:     []string{"*"},
+			Resources:   []string{"*"},
+			Roles:       []string{string(entities.SuperAdmin)},
+		},
+		{
+			// Permission role (RequirePermission) tetap berlaku: action yang
+			// namanya sama dengan permission subject diizinkan. Pembatasan per
+			// record ditambahkan sebagai policy deny.
+			Name:        "role-permission",
+			Description: "role grants a permission with the same name as the action",
+			Effect:      PolicyEffectAllow,
+			Actions:     []string{"*"},
+			Resources:   []string{"*"},
+			Condition: func(req AccessRequest) bool {
+				return containsString(req.Subject.Permissions, req.Action)
+			},
+		},
+		{
+			Name:        "self",
+			Description: "users may read and update their own account",
+			Effect:      PolicyEffectAllow,
+			Actions:     []string{entities.PermissionUsersRead, entities.PermissionUsersWrite},
+			Resources:   []string{"user"},
+			Conditions:  []AccessCondition{{Left: "subject.id", Op: "eq", Right: "resource.id"}},
+		},
+		{
+			// Memakai hierarki role yang sama dengan RoleService.CanGrant:
+			// akun hanya boleh diubah oleh user yang boleh memberikan role
+			// akun tersebut, sehingga role buatan dengan permission lebih
+			// banyak tidak bisa diambil alih. Restore memakai users:write;
+			// sessions:manage mencegah sesi akun tersebut dilihat atau dicabut.
+			Name:        "protect-privileged-accounts",
+			Description: "users may only modify accounts whose role they are allowed to grant",
+			Effect:      PolicyEffectDeny,
+			Actions:     []string{entities.PermissionUsersWrite, entities.PermissionUsersPurge, entities.PermissionSessionsManage},
+			Resources:   []string{"user"},
+			Condition: func(req AccessRequest) bool {
+				if strconv.FormatUint(uint64(req.Subject.ID), 10) == req.Resource.ID {
+					return false
+				}
+				role, _ := req.Resource.Attributes["role"].(string)
+				allowed, err := roleService.CanGrant(context.Background(), req.Subject.Role, entities.Role(role))
+				if err != nil {
+					// Gagal tertutup: tanpa hierarki yang bisa diperiksa akses ditolak
+					logger.Errorf("Failed to check role hierarchy for %s: %v", req.Subject.Role, err)
+					return true
+				}
+				return !allowed
+			},
+		},
+	}
+}
+
+// NewAccessPolicyService memuat policy bawaan dan policy dari
+// cfg.AccessPolicyFile. Policy yang tidak valid membuat aplikasi gagal start
+// agar salah konfigurasi tidak diam-diam membuka atau menutup akses.
+func NewAccessPolicyService(auditService AuditService, roleService RoleService, cfg *configs.Config, logger *logrus.Logger) (AccessPolicyService, error) {
+	policies := defaultAccessPolicies(roleService, logger)
+
+	if cfg.AccessPolicyFile != "" {
+		data, err := os.ReadFile(cfg.AccessPolicyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read access policy file: %w", err)
+		}
+
+		var filePolicies []AccessPolicy
+		if err := json.Unmarshal(data, &filePolicies); err != nil {
+			return nil, fmt.Errorf("failed to parse access policy file: %w", err)
+		}
+		policies = append(policies, filePolicies...)
+	}
+
+	for _, policy := range policies {
+		if err := validateAccessPolicy(policy); err != nil {
+			return nil, err
+		}
+	}
+
+	logger.Infof("Loaded %d access policies", len(policies))
+
+	return &accessPolicyService{
+		policies:     policies,
+		auditService: auditService,
+		logger:       logger,
+	}, nil
+}
+
+// Evaluate memakai aturan deny-overrides: satu policy deny yang cocok selalu
+// menolak, dan tanpa policy allow yang cocok akses juga ditolak.
+func (s *accessPolicyService) Evaluate(req AccessRequest) AccessDecision {
+	var allowedBy *AccessPolicy
+
+	for i := range s.policies {
+		policy := &s.policies[i]
+		if !policy.matches(req) {
+			continue
+		}
+		if policy.Effect == PolicyEffectDeny {
+			return AccessDecision{Allowed: false, Policy: policy.Name, Reason: policy.reason()}
+		}
+		if allowedBy == nil {
+			allowedBy = policy
+		}
+	}
+
+	if allowedBy == nil {
+		return AccessDecision{
+			Allowed: false,
+			Reason:  fmt.Sprintf("no policy allows %s on %s", req.Action, req.Resource.Type),
+		}
+	}
+	return AccessDecision{Allowed: true, Policy: allowedBy.Name, Reason: allowedBy.reason()}
+}
+
+func (s *accessPolicyService) Authorize(req AccessRequest) error {
+	decision := s.Evaluate(req)
+	if decision.Allowed {
+		return nil
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"user_id":     req.Subject.ID,
+		"action":      req.Action,
+		"resource":    req.Resource.Type,
+		"resource_id": req.Resource.ID,
+		"policy":      decision.Policy,
+	}).Warnf("Access denied: %s", decision.Reason)
+
+	actorID := req.Subject.ID
+	s.auditService.Record(&entities.AuditLog{
+		ActorID:        &actorID,
+		ImpersonatorID: req.Subject.ImpersonatorID,
+		Action:         entities.AuditAccessDenied,
+		TargetType:     req.Resource.Type,
+		TargetID:       req.Resource.ID,
+		Method:         req.Environment["method"],
+		Path:           req.Environment["path"],
+		IPAddress:      req.Environment["ip"],
+		UserAgent:      req.Environment["user_agent"],
+	}, map[string]any{
+		"action": req.Action,
+		"role":   req.Subject.Role,
+		"policy": decision.Policy,
+		"reason": decision.Reason,
+	})
+
+	return &AccessDeniedError{Decision: decision}
+}
+
+func (p *AccessPolicy) matches(req AccessRequest) bool {
+	if !matchesPattern(p.Actions, req.Action) || !matchesPattern(p.Resources, req.Resource.Type) {
+		return false
+	}
+	if len(p.Roles) > 0 && !containsString(p.Roles, string(req.Subject.Role)) {
+		return false
+	}
+	for _, condition := range p.Conditions {
+		if !condition.evaluate(req) {
+			return false
+		}
+	}
+	return p.Condition == nil || p.Condition(req)
+}
+
+func (p *AccessPolicy) reason() string {
+	if p.Description != "" {
+		return p.Description
+	}
+	return p.Name
+}
+
+func (c AccessCondition) evaluate(req AccessRequest) bool {
+	left, ok := attributeValue(req, c.Left)
+
+	switch c.Op {
+	case "exists":
+		return ok && left != nil
+	case "eq", "ne":
+		var right any
+		if c.Right != "" {
+			var found bool
+			right, found = attributeValue(req, c.Right)
+			if !found {
+				// Atribut yang tidak ada tidak pernah dianggap sama
+				return false
+			}
+		} else {
+			right = c.Value
+		}
+		if !ok {
+			return false
+		}
+		equal := sameValue(left, right)
+		if c.Op == "eq" {
+			return equal
+		}
+		return !equal
+	case "in":
+		values, isList := c.Value.([]any)
+		if !ok || !isList {
+			return false
+		}
+		for _, value := range values {
+			if sameValue(left, value) {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// attributeValue mengambil nilai atribut dari request sesuai path seperti
+// "subject.id" atau "resource.author_id"
+func attributeValue(req AccessRequest, path string) (any, bool) {
+	scope, name, ok := strings.Cut(path, ".")
+	if !ok {
+		return nil, false
+	}
+
+	switch scope {
+	case "subject":
+		switch name {
+		case "id":
+			return req.Subject.ID, true
+		case "role":
+			return string(req.Subject.Role), true
+		}
+		value, found := req.Subject.Attributes[name]
+		return value, found
+	case "resource":
+		switch name {
+		case "id":
+			return req.Resource.ID, req.Resource.ID != ""
+		case "type":
+			return req.Resource.Type, true
+		}
+		value, found := req.Resource.Attributes[name]
+		return value, found
+	case "env":
+		value, found := req.Environment[name]
+		return value, found
+	}
+	return nil, false
+}
+
+// sameValue membandingkan nilai tanpa memedulikan tipenya, sehingga uint dari
+// kode, float64 dari JSON dan string ID dianggap sama jika nilainya sama
+func sameValue(a, b any) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return normalizeValue(a) == normalizeValue(b)
+}
+
+func normalizeValue(value any) string {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case *uint:
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatUint(uint64(*v), 10)
+	}
+	return fmt.Sprint(value)
+}
+
+func matchesPattern(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if pattern == "*" || pattern == value {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func validateAccessPolicy(policy AccessPolicy) error {
+	if policy.Name == "" {
+		return errors.New("access policy must have a name")
+	}
+	if policy.Effect != PolicyEffectAllow && policy.Effect != PolicyEffectDeny {
+		return fmt.Errorf("access policy %s: effect must be allow or deny", policy.Name)
+	}
+	if len(policy.Actions) == 0 || len(policy.Resources) == 0 {
+		return fmt.Errorf("access policy %s: actions and resources are required", policy.Name)
+	}
+	for _, condition := range policy.Conditions {
+		switch condition.Op {
+		case "eq", "ne", "in", "exists":
+		default:
+			return fmt.Errorf("access policy %s: unknown condition operator %q", policy.Name, condition.Op)
+		}
+		if _, _, ok := strings.Cut(condition.Left, "."); !ok {
+			return fmt.Errorf("access policy %s: invalid attribute %q", policy.Name, condition.Left)
+		}
+	}
+	return nil
+}
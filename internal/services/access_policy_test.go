@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/configs"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/requests"
+	"github.com/sirupsen/logrus"
+)
+
+// fakeRoleService hanya mengimplementasikan CanGrant; grants berisi pasangan
+// "actor>role" yang diizinkan
+type fakeRoleService struct {
+	RoleService
+	grants map[string]bool
+	err    error
+}
+
+func (f *fakeRoleService) CanGrant(_ context.Context, actorRole, role entities.Role) (bool, error) {
+	if f.err != nil {
+		return false, f.err
+	}
+	return actorRole == entities.SuperAdmin || f.grants[string(actorRole)+">"+string(role)], nil
+}
+
+type fakeAuditService struct {
+	logs []*entities.AuditLog
+}
+
+func (f *fakeAuditService) Record(log *entities.AuditLog, _ map[string]any) {
+	f.logs = append(f.logs, log)
+}
+
+func (f *fakeAuditService) List(requests.AuditLogListRequest) ([]entities.AuditLog, error) {
+	return nil, nil
+}
+
+func newTestAccessPolicyService(t *testing.T, roles RoleService, policyFile string) (AccessPolicyService, *fakeAuditService) {
+	t.Helper()
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	audit := &fakeAuditService{}
+
+	service, err := NewAccessPolicyService(audit, roles, &configs.Config{AccessPolicyFile: policyFile}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return service, audit
+}
+
+func userResource(id, role string) AccessResource {
+	return AccessResource{Type: "user", ID: id, Attributes: map[string]any{"role": role}}
+}
+
+func TestAuthorizeDefaultPolicies(t *testing.T) {
+	roles := &fakeRoleService{grants: map[string]bool{
+		"admin>user":      true,
+		"admin>reception": true,
+	}}
+	adminPermissions := []string{entities.PermissionUsersRead, entities.PermissionUsersWrite, entities.PermissionUsersPurge}
+
+	tests := []struct {
+		name       string
+		subject    AccessSubject
+		action     string
+		resource   AccessResource
+		wantPolicy string
+		wantErr    bool
+	}{
+		{
+			name:       "super_admin may do anything",
+			subject:    AccessSubject{ID: 1, Role: entities.SuperAdmin},
+			action:     entities.PermissionUsersPurge,
+			resource:   userResource("2", "admin"),
+			wantPolicy: "super-admin",
+		},
+		{
+			name:       "permission grants the action",
+			subject:    AccessSubject{ID: 2, Role: entities.Admin, Permissions: adminPermissions},
+			action:     entities.PermissionUsersWrite,
+			resource:   userResource("5", "user"),
+			wantPolicy: "role-permission",
+		},
+		{
+			name:     "no permission and not self",
+			subject:  AccessSubject{ID: 5, Role: entities.User},
+			action:   entities.PermissionUsersRead,
+			resource: userResource("6", "user"),
+			wantErr:  true,
+		},
+		{
+			name:       "self may read own account",
+			subject:    AccessSubject{ID: 5, Role: entities.User},
+			action:     entities.PermissionUsersRead,
+			resource:   userResource("5", "user"),
+			wantPolicy: "self",
+		},
+		{
+			name:     "self policy does not cover purge",
+			subject:  AccessSubject{ID: 5, Role: entities.User},
+			action:   entities.PermissionUsersPurge,
+			resource: userResource("5", "user"),
+			wantErr:  true,
+		},
+		{
+			name:       "deny overrides permission for another admin",
+			subject:    AccessSubject{ID: 2, Role: entities.Admin, Permissions: adminPermissions},
+			action:     entities.PermissionUsersWrite,
+			resource:   userResource("3", "admin"),
+			wantPolicy: "protect-privileged-accounts",
+			wantErr:    true,
+		},
+		{
+			name:       "deny covers custom roles outside the hierarchy",
+			subject:    AccessSubject{ID: 2, Role: entities.Admin, Permissions: adminPermissions},
+			action:     entities.PermissionUsersWrite,
+			resource:   userResource("7", "role_manager"),
+			wantPolicy: "protect-privileged-accounts",
+			wantErr:    true,
+		},
+		{
+			name:       "deny covers purge",
+			subject:    AccessSubject{ID: 2, Role: entities.Admin, Permissions: adminPermissions},
+			action:     entities.PermissionUsersPurge,
+			resource:   userResource("7", "role_manager"),
+			wantPolicy: "protect-privileged-accounts",
+			wantErr:    true,
+		},
+		{
+			name:       "deny covers session management",
+			subject:    AccessSubject{ID: 2, Role: entities.Admin, Permissions: []string{entities.PermissionSessionsManage}},
+			action:     entities.PermissionSessionsManage,
+			resource:   userResource("1", "super_admin"),
+			wantPolicy: "protect-privileged-accounts",
+			wantErr:    true,
+		},
+		{
+			name:       "custom role within the hierarchy",
+			subject:    AccessSubject{ID: 2, Role: entities.Admin, Permissions: adminPermissions},
+			action:     entities.PermissionUsersPurge,
+			resource:   userResource("8", "reception"),
+			wantPolicy: "role-permission",
+		},
+		{
+			name:       "admin may modify own account",
+			subject:    AccessSubject{ID: 2, Role: entities.Admin, Permissions: adminPermissions},
+			action:     entities.PermissionUsersWrite,
+			resource:   userResource("2", "admin"),
+			wantPolicy: "role-permission",
+		},
+		{
+			name:       "deny does not cover reading",
+			subject:    AccessSubject{ID: 2, Role: entities.Admin, Permissions: adminPermissions},
+			action:     entities.PermissionUsersRead,
+			resource:   userResource("3", "admin"),
+			wantPolicy: "role-permission",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, audit := newTestAccessPolicyService(t, roles, "")
+
+			err := service.Authorize(AccessRequest{Subject: tt.subject, Action: tt.action, Resource: tt.resource})
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("Authorize err = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			decision := service.Evaluate(AccessRequest{Subject: tt.subject, Action: tt.action, Resource: tt.resource})
+			if decision.Policy != tt.wantPolicy {
+				t.Errorf("policy = %q, want %q", decision.Policy, tt.wantPolicy)
+			}
+
+			if !tt.wantErr {
+				if len(audit.logs) != 0 {
+					t.Errorf("allowed request was audited as denied")
+				}
+				return
+			}
+			var denied *AccessDeniedError
+			if !errors.As(err, &denied) || !errors.Is(err, ErrAccessDenied) {
+				t.Errorf("err = %v, want *AccessDeniedError", err)
+			}
+			if len(audit.logs) != 1 || audit.logs[0].Action != entities.AuditAccessDenied || audit.logs[0].TargetID != tt.resource.ID {
+				t.Errorf("denial was not audited: %+v", audit.logs)
+			}
+		})
+	}
+}
+
+func TestAuthorizeDeniesWhenHierarchyUnavailable(t *testing.T) {
+	service, _ := newTestAccessPolicyService(t, &fakeRoleService{err: errors.New("redis down")}, "")
+
+	err := service.Authorize(AccessRequest{
+		Subject:  AccessSubject{ID: 2, Role: entities.Admin, Permissions: []string{entities.PermissionUsersWrite}},
+		Action:   entities.PermissionUsersWrite,
+		Resource: userResource("5", "user"),
+	})
+	if !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("err = %v, want ErrAccessDenied", err)
+	}
+}
+
+func TestAuthorizePoliciesFromFile(t *testing.T) {
+	const policies = `[
+	{
+		"name": "doctor-own-encounters",
+		"effect": "allow",
+		"actions": ["encounters:update"],
+		"resources": ["encounter"],
+		"roles": ["doctor"],
+		"conditions": [{"left": "subject.id", "op": "eq", "right": "resource.author_id"}]
+	},
+	{
+		"name": "no-closed-encounters",
+		"description": "closed encounters are read-only",
+		"effect": "deny",
+		"actions": ["encounters:*"],
+		"resources": ["encounter"],
+		"conditions": [{"left": "resource.status", "op": "in", "value": ["closed", "cancelled"]}]
+	}
+]`
+	file := filepath.Join(t.TempDir(), "policies.json")
+	if err := os.WriteFile(file, []byte(policies), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	service, _ := newTestAccessPolicyService(t, &fakeRoleService{}, file)
+
+	encounter := func(author float64, status string) AccessResource {
+		return AccessResource{Type: "encounter", ID: "9", Attributes: map[string]any{"author_id": author, "status": status}}
+	}
+	doctor := AccessSubject{ID: 4, Role: entities.Role("doctor")}
+
+	tests := []struct {
+		name       string
+		subject    AccessSubject
+		resource   AccessResource
+		wantPolicy string
+		wantErr    bool
+	}{
+		{"author may update", doctor, encounter(4, "open"), "doctor-own-encounters", false},
+		{"other doctor may not update", AccessSubject{ID: 5, Role: entities.Role("doctor")}, encounter(4, "open"), "", true},
+		{"other role may not update", AccessSubject{ID: 4, Role: entities.User}, encounter(4, "open"), "", true},
+		{"deny overrides author", doctor, encounter(4, "closed"), "no-closed-encounters", true},
+		{"deny overrides super_admin", AccessSubject{ID: 1, Role: entities.SuperAdmin}, encounter(4, "cancelled"), "no-closed-encounters", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := AccessRequest{Subject: tt.subject, Action: "encounters:update", Resource: tt.resource}
+			if err := service.Authorize(req); tt.wantErr != (err != nil) {
+				t.Fatalf("Authorize err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if decision := service.Evaluate(req); decision.Policy != tt.wantPolicy {
+				t.Errorf("policy = %q, want %q", decision.Policy, tt.wantPolicy)
+			}
+		})
+	}
+}
+
+func TestNewAccessPolicyServiceRejectsInvalidFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"not JSON", `{`},
+		{"missing name", `[{"effect": "allow", "actions": ["*"], "resources": ["*"]}]`},
+		{"unknown effect", `[{"name": "p", "effect": "maybe", "actions": ["*"], "resources": ["*"]}]`},
+		{"missing actions", `[{"name": "p", "effect": "allow", "resources": ["*"]}]`},
+		{"unknown operator", `[{"name": "p", "effect": "allow", "actions": ["*"], "resources": ["*"], "conditions": [{"left": "subject.id", "op": "gt"}]}]`},
+		{"invalid attribute", `[{"name": "p", "effect": "allow", "actions": ["*"], "resources": ["*"], "conditions": [{"left": "id", "op": "exists"}]}]`},
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "policies.json")
+			if err := os.WriteFile(file, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := NewAccessPolicyService(&fakeAuditService{}, &fakeRoleService{}, &configs.Config{AccessPolicyFile: file}, logger); err == nil {
+				t.Error("NewAccessPolicyService accepted an invalid policy file")
+			}
+		})
+	}
+
+	if _, err := NewAccessPolicyService(&fakeAuditService{}, &fakeRoleService{}, &configs.Config{AccessPolicyFile: filepath.Join(t.TempDir(), "missing.json")}, logger); err == nil {
+		t.Error("NewAccessPolicyService accepted a missing policy file")
+	}
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/repositories"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/utils"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrAPIKeyInvalid         = errors.New("invalid API key")
+	ErrAPIKeyNotFound        = errors.New("API key not found")
+	ErrInvalidAPIKeyScope    = errors.New("invalid API key scope")
+	ErrAPIKeyOwnerNotAllowed = errors.New("not allowed to create an API key for this user")
+	ErrAPIKeyExpiryInPast    = errors.New("expires_at must be in the future")
+)
+
+const (
+	// Format key: amk_<prefix>_<secret>. Prefix disimpan apa adanya untuk
+	// mencari key dan mengenalinya di log, secret hanya disimpan hash-nya.
+	apiKeyPrefix           = "amk_"
+	apiKeyPrefixBytes      = 6
+	apiKeySecretSize       = 32
+	apiKeyTouchMinInterval = time.Minute
+)
+
+type APIKeyService interface {
+	Create(createdBy uint, creatorRole entities.Role, req entities.APIKeyCreateRequest) (*entities.APIKey, string, error)
+	List(userID uint) ([]entities.APIKey, error)
+	Revoke(id uint) error
+	Authenticate(rawKey, ip string) (*entities.APIKey, error)
+}
+
+type apiKeyService struct {
+	apiKeyRepo  repositories.APIKeyRepository
+	userRepo    repositories.UserRepository
+	roleService RoleService
+	logger      *logrus.Logger
+}
+
+func NewAPIKeyService(apiKeyRepo repositories.APIKeyRepository, userRepo repositories.UserRepository, roleService RoleService, logger *logrus.Logger) APIKeyService {
+	return &apiKeyService{
+		apiKeyRepo:  apiKeyRepo,
+		userRepo:    userRepo,
+		roleService: roleService,
+		logger:      logger,
+	}
+}
+
+// Create membuat API key baru dan mengembalikan key lengkap. Key lengkap tidak
+// disimpan sehingga hanya bisa ditampilkan sekali ini.
+func (s *apiKeyService) Create(createdBy uint, creatorRole entities.Role, req entities.APIKeyCreateRequest) (*entities.APIKey, string, error) {
+	if err := validateAPIKeyScopes(req.Scopes); err != nil {
+		return nil, "", err
+	}
+
+	owner, err := s.userRepo.FindByID(req.UserID)
+	if err != nil {
+		s.logger.Errorf("Failed to find API key owner %d: %v", req.UserID, err)
+		return nil, "", errors.New("failed to create API key")
+	}
+	if owner == nil {
+		return nil, "", errors.New("user not found")
+	}
+
+	// Request dengan API key diautentikasi dan diaudit atas nama pemiliknya,
+	// jadi key untuk user lain hanya boleh dibuat jika pembuat boleh
+	// memberikan role pemilik (admin lain hanya oleh super_admin)
+	if owner.ID != createdBy {
+		allowed, err := s.roleService.CanGrant(context.Background(), creatorRole, owner.Role)
+		if err != nil {
+			s.logger.Errorf("Failed to check role hierarchy for API key owner %d: %v", owner.ID, err)
+			return nil, "", errors.New("failed to create API key")
+		}
+		if !allowed {
+			return nil, "", ErrAPIKeyOwnerNotAllowed
+		}
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, "", ErrAPIKeyExpiryInPast
+	}
+
+	prefixBytes := make([]byte, apiKeyPrefixBytes)
+	if _, err := rand.Read(prefixBytes); err != nil {
+		return nil, "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	prefix := hex.EncodeToString(prefixBytes)
+
+	secret, err := utils.GenerateRandomToken(apiKeySecretSize)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	rawKey := apiKeyPrefix + prefix + "_" + secret
+
+	apiKey := &entities.APIKey{
+		Name:      req.Name,
+		Prefix:    prefix,
+		KeyHash:   utils.HashToken(rawKey),
+		UserID:    owner.ID,
+		Scopes:    strings.Join(req.Scopes, " "),
+		ExpiresAt: req.ExpiresAt,
+		CreatedBy: &createdBy,
+	}
+
+	if err := s.apiKeyRepo.Create(apiKey); err != nil {
+		s.logger.Errorf("Failed to store API key: %v", err)
+		return nil, "", errors.New("failed to create API key")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"api_key_id": apiKey.ID,
+		"prefix":     prefix,
+		"user_id":    owner.ID,
+		"created_by": createdBy,
+	}).Info("API key created")
+
+	return apiKey, rawKey, nil
+}
+
+func (s *apiKeyService) List(userID uint) ([]entities.APIKey, error) {
+	apiKeys, err := s.apiKeyRepo.FindAll(userID)
+	if err != nil {
+		s.logger.Errorf("Failed to list API keys: %v", err)
+		return nil, errors.New("failed to list API keys")
+	}
+	return apiKeys, nil
+}
+
+func (s *apiKeyService) Revoke(id uint) error {
+	apiKey, err := s.apiKeyRepo.FindByID(id)
+	if err != nil {
+		s.logger.Errorf("Failed to find API key %d: %v", id, err)
+		return errors.New("failed to revoke API key")
+	}
+	if apiKey == nil {
+		return ErrAPIKeyNotFound
+	}
+
+	if err := s.apiKeyRepo.Revoke(id); err != nil {
+		s.logger.Errorf("Failed to revoke API key %d: %v", id, err)
+		return errors.New("failed to revoke API key")
+	}
+
+	s.logger.WithField("api_key_id", id).Info("API key revoked")
+	return nil
+}
+
+// Authenticate memvalidasi key dari header Authorization. Key yang salah,
+// dicabut, kedaluwarsa, atau milik user nonaktif semuanya menghasilkan
+// ErrAPIKeyInvalid agar client tidak bisa membedakan penyebabnya.
+func (s *apiKeyService) Authenticate(rawKey, ip string) (*entities.APIKey, error) {
+	rest, ok := strings.CutPrefix(rawKey, apiKeyPrefix)
+	if !ok {
+		return nil, ErrAPIKeyInvalid
+	}
+	prefix, _, ok := strings.Cut(rest, "_")
+	if !ok || len(prefix) != apiKeyPrefixBytes*2 {
+		return nil, ErrAPIKeyInvalid
+	}
+
+	apiKey, err := s.apiKeyRepo.FindByPrefix(prefix)
+	if err != nil {
+		s.logger.Errorf("Failed to find API key: %v", err)
+		return nil, errors.New("failed to verify API key")
+	}
+	if apiKey == nil {
+		return nil, ErrAPIKeyInvalid
+	}
+
+	if subtle.ConstantTimeCompare([]byte(apiKey.KeyHash), []byte(utils.HashToken(rawKey))) != 1 {
+		return nil, ErrAPIKeyInvalid
+	}
+
+	if apiKey.RevokedAt != nil {
+		s.logger.WithField("prefix", prefix).Warn("Revoked API key used")
+		return nil, ErrAPIKeyInvalid
+	}
+	if apiKey.ExpiresAt != nil && time.Now().After(*apiKey.ExpiresAt) {
+		return nil, ErrAPIKeyInvalid
+	}
+	if apiKey.User == nil || !apiKey.User.Active {
+		return nil, ErrAPIKeyInvalid
+	}
+
+	if err := s.apiKeyRepo.TouchLastUsed(apiKey.ID, ip, apiKeyTouchMinInterval); err != nil {
+		s.logger.Warnf("Failed to update last use of API key %d: %v", apiKey.ID, err)
+	}
+
+	return apiKey, nil
+}
+
+func validateAPIKeyScopes(scopes []string) error {
+	for _, scope := range scopes {
+		valid := false
+		for _, known := range entities.APIKeyScopes {
+			if scope == known {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("%w: %s", ErrInvalidAPIKeyScope, scope)
+		}
+	}
+	return nil
+}
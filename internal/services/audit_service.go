@@ -0,0 +1,73 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/requests"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/repositories"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultAuditPageSize = 20
+	maxAuditPageSize     = 100
+)
+
+type AuditService interface {
+	Record(log *entities.AuditLog, metadata map[string]any)
+	List(request requests.AuditLogListRequest) ([]entities.AuditLog, error)
+}
+
+type auditService struct {
+	auditLogRepo repositories.AuditLogRepository
+	logger       *logrus.Logger
+}
+
+func NewAuditService(auditLogRepo repositories.AuditLogRepository, logger *logrus.Logger) AuditService {
+	return &auditService{
+		auditLogRepo: auditLogRepo,
+		logger:       logger,
+	}
+}
+
+// Record menyimpan audit log. Kegagalan hanya dicatat di log aplikasi agar
+// tidak menggagalkan request yang sedang diaudit.
+func (s *auditService) Record(log *entities.AuditLog, metadata map[string]any) {
+	log.Metadata = json.RawMessage("{}")
+	if len(metadata) > 0 {
+		encoded, err := json.Marshal(metadata)
+		if err != nil {
+			s.logger.Warnf("Failed to encode audit metadata for %s: %v", log.Action, err)
+		} else {
+			log.Metadata = encoded
+		}
+	}
+
+	if err := s.auditLogRepo.Create(log); err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"action":   log.Action,
+			"actor_id": log.ActorID,
+		}).Errorf("Failed to write audit log: %v", err)
+	}
+}
+
+func (s *auditService) List(request requests.AuditLogListRequest) ([]entities.AuditLog, error) {
+	if request.Page < 1 {
+		request.Page = 1
+	}
+	if request.Limit < 1 {
+		request.Limit = defaultAuditPageSize
+	}
+	if request.Limit > maxAuditPageSize {
+		request.Limit = maxAuditPageSize
+	}
+
+	logs, err := s.auditLogRepo.FindAll(request)
+	if err != nil {
+		s.logger.Errorf("Failed to list audit logs: %v", err)
+		return nil, errors.New("failed to list audit logs")
+	}
+	return logs, nil
+}
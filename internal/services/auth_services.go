@@ -4,48 +4,99 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strconv"
-	"strings"
 	"time"
 
 	"github.com/anieswahdie1/ara-medika-api.git/internal/configs"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
 	"github.com/anieswahdie1/ara-medika-api.git/internal/repositories"
 	"github.com/anieswahdie1/ara-medika-api.git/internal/utils"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 )
 
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// LoginResult adalah hasil login. Jika MFARequired bernilai true, token belum
+// diterbitkan dan client harus menukar MFAToken beserta kode two-factor di
+// /auth/login/mfa. Jika PasswordChangeRequired bernilai true, password user
+// sudah kedaluwarsa dan client harus mengirim password baru beserta
+// PasswordChangeToken ke /auth/login/password-change.
+type LoginResult struct {
+	AccessToken  string
+	RefreshToken string
+
+	MFARequired           bool
+	MFAEnrollmentRequired bool
+	MFAToken              string
+
+	PasswordChangeRequired bool
+	PasswordChangeToken    string
+
+	// RecoveryCodes hanya terisi ketika enrollment two-factor diselesaikan
+	// bersamaan dengan login
+	RecoveryCodes []string
+}
+
 type AuthService interface {
-	Login(email, password string) (accessToken, refreshToken string, err error)
+	Login(email, password string, meta entities.SessionMeta) (*LoginResult, error)
+	VerifyLoginMFA(mfaToken, code string) (*LoginResult, error)
+	CompleteLoginPasswordChange(passwordChangeToken, newPassword string) (*LoginResult, error)
+	BeginLoginMFAEnrollment(mfaToken string) (*entities.MFAEnrollment, error)
 	Logout(tokenString string, userID uint) error
 	RefreshToken(refreshToken string) (newAccessToken, newRefreshToken string, err error)
-	InvalidateOtherSessions(userID uint) error
-	StoreToken(userID uint, token string) error
+	InvalidateOtherSessions(userID uint, keepSessionID string) error
+	RevokeAllSessions(userID uint) error
+	StoreToken(userID uint, sessionID, tokenID string) error
+	ListSessions(userID uint) ([]entities.Session, error)
+	RevokeSession(userID uint, sessionID string) error
+	UnlockAccount(userID uint) error
+	IntrospectToken(token, tokenTypeHint string) (*TokenIntrospection, error)
+	RevokeToken(token, tokenTypeHint string) error
+	Impersonate(actorID, targetID uint, meta entities.SessionMeta) (*ImpersonationResult, error)
+	EndImpersonation(userID uint, sessionID string) error
 }
 
 type authService struct {
-	userRepo    repositories.UserRepository
-	redisClient *redis.Client
-	cfg         *configs.Config
-	logger      *logrus.Logger
+	userRepo       repositories.UserRepository
+	mfaService     MFAService
+	loginLimiter   LoginLimiter
+	passwordPolicy PasswordPolicyService
+	roleService    RoleService
+	redisClient    *redis.Client
+	cfg            *configs.Config
+	logger         *logrus.Logger
 }
 
-func NewAuthService(userRepo repositories.UserRepository, redisClient *redis.Client, cfg *configs.Config, logger *logrus.Logger) AuthService {
+func NewAuthService(userRepo repositories.UserRepository, mfaService MFAService, loginLimiter LoginLimiter, passwordPolicy PasswordPolicyService, roleService RoleService, redisClient *redis.Client, cfg *configs.Config, logger *logrus.Logger) AuthService {
 	return &authService{
-		userRepo:    userRepo,
-		redisClient: redisClient,
-		cfg:         cfg,
-		logger:      logger,
+		userRepo:       userRepo,
+		mfaService:     mfaService,
+		loginLimiter:   loginLimiter,
+		passwordPolicy: passwordPolicy,
+		roleService:    roleService,
+		redisClient:    redisClient,
+		cfg:            cfg,
+		logger:         logger,
 	}
 }
 
-func (s *authService) Login(email, password string) (string, string, error) {
+func (s *authService) Login(email, password string, meta entities.SessionMeta) (*LoginResult, error) {
+	ctx := context.Background()
+
+	// Tolak lebih awal jika email atau IP sedang dikunci / harus menunggu
+	if err := s.loginLimiter.Check(ctx, email, meta.IPAddress); err != nil {
+		return nil, err
+	}
+
 	// Cari user by email
 	user, err := s.userRepo.FindByEmail(email)
 	if err != nil {
 		s.logger.Errorf("Failed to find user by email: %v", err)
-		return "", "", fmt.Errorf("user lookup failed: %w", err)
+		return nil, fmt.Errorf("user lookup failed: %w", err)
+	}
+	if user == nil {
+		s.recordLoginFailure(ctx, email, meta.IPAddress)
+		return nil, ErrInvalidCredentials
 	}
 
 	// Debug: Cetak hash yang tersimpan (HANYA untuk development)
@@ -64,144 +115,390 @@ func (s *authService) Login(email, password string) (string, string, error) {
 			"stored_hash_prefix":    getHashPrefix(user.Password),
 		}).Warn("Password verification failed")
 
-		return "", "", errors.New("invalid credentials")
+		s.recordLoginFailure(ctx, email, meta.IPAddress)
+		return nil, ErrInvalidCredentials
+	}
+
+	// User nonaktif (termasuk undangan yang belum diterima) tidak boleh login
+	if !user.Active {
+		return nil, ErrInvalidCredentials
+	}
+
+	// Password benar: hash dengan algoritma/parameter lama diganti hash baru
+	if utils.PasswordNeedsRehash(user.Password) {
+		s.upgradePasswordHash(user, password)
+	}
+
+	// Two-factor: password benar, tapi token baru diterbitkan setelah kode
+	// TOTP diverifikasi di /auth/login/mfa
+	if user.MFAEnabled || s.mfaService.IsRequired(user.Role) {
+		mfaToken, err := s.createLoginChallenge(ctx, challengePurposeMFA, user.ID, meta)
+		if err != nil {
+			s.logger.Errorf("Failed to create MFA challenge: %v", err)
+			return nil, errors.New("failed to complete login")
+		}
+
+		return &LoginResult{
+			MFARequired:           true,
+			MFAEnrollmentRequired: !user.MFAEnabled,
+			MFAToken:              mfaToken,
+		}, nil
+	}
+
+	return s.finishLogin(ctx, user, meta)
+}
+
+// VerifyLoginMFA menyelesaikan login dua langkah. Untuk user dengan role yang
+// wajib two-factor tetapi belum enroll, kode pertama sekaligus mengonfirmasi
+// enrollment yang dimulai lewat BeginLoginMFAEnrollment.
+func (s *authService) VerifyLoginMFA(mfaToken, code string) (*LoginResult, error) {
+	ctx := context.Background()
+
+	challenge, err := s.loadLoginChallenge(ctx, challengePurposeMFA, mfaToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(challenge.userID)
+	if err != nil {
+		return nil, fmt.Errorf("user lookup failed: %w", err)
+	}
+	if user == nil || !user.Active {
+		return nil, ErrLoginChallengeInvalid
+	}
+
+	if err := s.loginLimiter.Check(ctx, user.Email, challenge.meta.IPAddress); err != nil {
+		return nil, err
+	}
+
+	var recoveryCodes []string
+	if user.MFAEnabled {
+		err = s.mfaService.Verify(user, code)
+	} else {
+		recoveryCodes, err = s.mfaService.ConfirmEnrollment(user.ID, code)
+	}
+	if err != nil {
+		s.logger.WithField("user_id", user.ID).Warnf("Two-factor verification failed: %v", err)
+		if err == ErrInvalidMFACode {
+			s.recordLoginFailure(ctx, user.Email, challenge.meta.IPAddress)
+		}
+		return nil, err
+	}
+
+	s.deleteLoginChallenge(ctx, challenge.tokenHash)
+
+	result, err := s.finishLogin(ctx, user, challenge.meta)
+	if err != nil {
+		return nil, err
+	}
+	result.RecoveryCodes = recoveryCodes
+
+	return result, nil
+}
+
+// CompleteLoginPasswordChange menyelesaikan login user yang passwordnya sudah
+// kedaluwarsa. Password baru harus lolos kebijakan password dan berbeda dari
+// password saat ini.
+func (s *authService) CompleteLoginPasswordChange(passwordChangeToken, newPassword string) (*LoginResult, error) {
+	ctx := context.Background()
+
+	challenge, err := s.loadLoginChallenge(ctx, challengePurposePasswordChange, passwordChangeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(challenge.userID)
+	if err != nil {
+		return nil, fmt.Errorf("user lookup failed: %w", err)
+	}
+	if user == nil || !user.Active {
+		return nil, ErrLoginChallengeInvalid
+	}
+
+	if err := s.passwordPolicy.Validate(user.ID, newPassword); err != nil {
+		return nil, err
+	}
+	if utils.CheckPassword(newPassword, user.Password) {
+		return nil, &PasswordPolicyError{Violations: []string{"must differ from the current password"}}
+	}
+
+	if err := s.passwordPolicy.SetPassword(user, newPassword); err != nil {
+		return nil, err
+	}
+	s.deleteLoginChallenge(ctx, challenge.tokenHash)
+
+	s.logger.WithField("user_id", user.ID).Info("Expired password changed during login")
+	return s.completeLogin(ctx, user, challenge.meta)
+}
+
+// BeginLoginMFAEnrollment memulai enrollment TOTP untuk user yang diwajibkan
+// memakai two-factor tetapi belum pernah enroll, menggunakan MFA token dari
+// /auth/login sebagai bukti bahwa password sudah diverifikasi.
+func (s *authService) BeginLoginMFAEnrollment(mfaToken string) (*entities.MFAEnrollment, error) {
+	challenge, err := s.loadLoginChallenge(context.Background(), challengePurposeMFA, mfaToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.mfaService.BeginEnrollment(challenge.userID)
+}
+
+// finishLogin dipanggil setelah password (dan kode two-factor) terverifikasi.
+// Jika password sudah kedaluwarsa, token belum diterbitkan dan user harus
+// mengganti password lebih dulu.
+func (s *authService) finishLogin(ctx context.Context, user *entities.Users, meta entities.SessionMeta) (*LoginResult, error) {
+	if !s.passwordPolicy.IsExpired(user) {
+		return s.completeLogin(ctx, user, meta)
+	}
+
+	token, err := s.createLoginChallenge(ctx, challengePurposePasswordChange, user.ID, meta)
+	if err != nil {
+		s.logger.Errorf("Failed to create password change challenge: %v", err)
+		return nil, errors.New("failed to complete login")
+	}
+
+	return &LoginResult{
+		PasswordChangeRequired: true,
+		PasswordChangeToken:    token,
+	}, nil
+}
+
+// completeLogin menerbitkan token, me-reset counter login gagal dan mencatat
+// waktu login terakhir. Counter hanya di-reset setelah login benar-benar
+// selesai, sehingga password yang benar tanpa kode two-factor yang valid tidak
+// menghapus riwayat kegagalan.
+func (s *authService) completeLogin(ctx context.Context, user *entities.Users, meta entities.SessionMeta) (*LoginResult, error) {
+	result, err := s.issueTokens(ctx, user, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.loginLimiter.Reset(ctx, user.Email); err != nil {
+		s.logger.Warnf("Failed to reset login failures of user %d: %v", user.ID, err)
+	}
+	if err := s.userRepo.UpdateLastLogin(user.ID, time.Now()); err != nil {
+		s.logger.Warnf("Failed to update last login of user %d: %v", user.ID, err)
+	}
+
+	// Isi cache permission role lebih awal agar request pertama setelah login
+	// tidak perlu ke database
+	if _, err := s.roleService.RolePermissions(ctx, string(user.Role)); err != nil {
+		s.logger.Warnf("Failed to load permissions of role %s: %v", user.Role, err)
+	}
+
+	return result, nil
+}
+
+// upgradePasswordHash menyimpan ulang password dengan hasher yang sedang aktif.
+// Kegagalan hanya dicatat karena login tetap bisa dilanjutkan dengan hash lama.
+func (s *authService) upgradePasswordHash(user *entities.Users, password string) {
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		s.logger.Warnf("Failed to rehash password of user %d: %v", user.ID, err)
+		return
+	}
+	if err := s.userRepo.UpdatePasswordHash(user.ID, hashedPassword); err != nil {
+		s.logger.Warnf("Failed to store rehashed password of user %d: %v", user.ID, err)
+		return
+	}
+
+	user.Password = hashedPassword
+	s.logger.WithField("user_id", user.ID).Info("Password hash upgraded")
+}
+
+func (s *authService) recordLoginFailure(ctx context.Context, email, ip string) {
+	if err := s.loginLimiter.RecordFailure(ctx, email, ip); err != nil {
+		s.logger.Errorf("Failed to record login failure: %v", err)
 	}
+}
+
+// UnlockAccount membuka kunci login user yang terkunci karena terlalu banyak
+// percobaan gagal
+func (s *authService) UnlockAccount(userID uint) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return fmt.Errorf("user lookup failed: %w", err)
+	}
+	if user == nil {
+		return errors.New("user not found")
+	}
+
+	if err := s.loginLimiter.Unlock(context.Background(), user.Email); err != nil {
+		s.logger.Errorf("Failed to unlock user %d: %v", userID, err)
+		return errors.New("failed to unlock account")
+	}
+
+	s.logger.WithField("user_id", userID).Info("Account login lock cleared")
+	return nil
+}
 
-	// Invalidasi session lain jika ada
-	if err := s.InvalidateOtherSessions(user.ID); err != nil {
-		s.logger.Warnf("Failed to invalidate other sessions: %v", err)
+// issueTokens membuat session baru beserta access dan refresh token
+func (s *authService) issueTokens(ctx context.Context, user *entities.Users, meta entities.SessionMeta) (*LoginResult, error) {
+	// Batasi jumlah session aktif sesuai role
+	if err := s.enforceSessionLimit(ctx, user.ID, string(user.Role)); err != nil {
+		s.logger.Warnf("Failed to enforce session limit: %v", err)
 	}
 
 	// Generate tokens
-	accessToken, err := utils.GenerateToken(s.cfg, user.ID, user.Email, string(user.Role))
+	familyID := utils.NewTokenID()
+	accessTokenID := utils.NewTokenID()
+	refreshTokenID := utils.NewTokenID()
+
+	accessToken, err := utils.GenerateToken(s.cfg, user.ID, user.Email, string(user.Role), familyID, accessTokenID)
 	if err != nil {
 		s.logger.Errorf("Failed to generate access token: %v", err)
-		return "", "", errors.New("failed to generate token")
+		return nil, errors.New("failed to generate token")
 	}
 
-	refreshToken, err := utils.GenerateRefreshToken(s.cfg, user.ID)
+	refreshToken, err := utils.GenerateRefreshToken(s.cfg, user.ID, familyID, refreshTokenID)
 	if err != nil {
 		s.logger.Errorf("Failed to generate refresh token: %v", err)
-		return "", "", errors.New("failed to generate token")
+		return nil, errors.New("failed to generate token")
+	}
+
+	// Daftarkan refresh token family dan session baru
+	if err := s.createRefreshFamily(ctx, user.ID, familyID, refreshTokenID); err != nil {
+		s.logger.Errorf("Failed to create refresh token family: %v", err)
+		return nil, errors.New("failed to complete login")
+	}
+
+	if err := s.createSession(ctx, user.ID, familyID, meta); err != nil {
+		s.logger.Errorf("Failed to create session: %v", err)
+		return nil, errors.New("failed to complete login")
 	}
 
 	// Simpan token di Redis
-	if err := s.StoreToken(user.ID, accessToken); err != nil {
+	if err := s.StoreToken(user.ID, familyID, accessTokenID); err != nil {
 		s.logger.Errorf("Failed to store token: %v", err)
-		return "", "", errors.New("failed to complete login")
+		return nil, errors.New("failed to complete login")
 	}
 
-	return accessToken, refreshToken, nil
+	return &LoginResult{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
 }
 
 func (s *authService) Logout(tokenString string, userID uint) error {
-	// Tambahkan token ke blacklist
 	ctx := context.Background()
-	expiry := s.cfg.JWTExpire
-
-	// Hitung sisa waktu token
-	claims, err := utils.ValidateToken(s.cfg, tokenString)
-	if err == nil {
-		remaining := time.Until(claims.ExpiresAt.Time)
-		if remaining > 0 {
-			expiry = remaining
-		}
-	}
 
-	// Blacklist token
-	err = s.redisClient.Set(ctx, tokenString, "blacklisted", expiry).Err()
+	claims, err := utils.ValidateToken(tokenString)
 	if err != nil {
-		s.logger.Errorf("Failed to blacklist token: %v", err)
+		// Token sudah tidak valid (misalnya kedaluwarsa), tidak ada yang perlu dicabut
+		return nil
+	}
+
+	// Cabut session beserta refresh token family-nya. Semua access token dari
+	// session ini, termasuk token yang sedang dipakai, ikut masuk blacklist.
+	if err := s.revokeSessions(ctx, userID, claims.FamilyID); err != nil {
+		s.logger.Errorf("Failed to revoke session: %v", err)
 		return errors.New("failed to logout")
 	}
 
-	// Hapus dari active tokens
-	err = s.redisClient.Del(ctx, fmt.Sprintf("user:%d:token:%s", userID, tokenString)).Err()
-	if err != nil {
-		s.logger.Warnf("Failed to remove token from active sessions: %v", err)
+	// Blacklist token secara eksplisit untuk berjaga-jaga jika token belum
+	// tercatat pada session (misalnya gagal disimpan saat login)
+	if err := s.blacklistAccessToken(ctx, claims); err != nil {
+		s.logger.Errorf("Failed to blacklist token: %v", err)
+		return errors.New("failed to logout")
 	}
 
 	return nil
 }
 
-func (s *authService) StoreToken(userID uint, token string) error {
+func (s *authService) StoreToken(userID uint, sessionID, tokenID string) error {
 	ctx := context.Background()
-	expiry := s.cfg.JWTExpire
-
-	// Simpan mapping token -> user
-	err := s.redisClient.Set(ctx,
-		fmt.Sprintf("user:%d:token:%s", userID, token),
-		"active",
-		expiry,
-	).Err()
+	key := refreshFamilyTokensKey(sessionID)
+
+	// Catat hash jti access token pada family agar ikut dicabut bersama
+	// refresh token-nya
+	_, err := s.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SAdd(ctx, key, utils.HashToken(tokenID))
+		pipe.Expire(ctx, key, s.cfg.JWTRefreshExpire)
+		return nil
+	})
 
 	return err
 }
 
 func (s *authService) RefreshToken(refreshToken string) (string, string, error) {
-	// Validasi refresh token
-	token, err := jwt.ParseWithClaims(refreshToken, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(s.cfg.JWTSecret), nil
-	})
+	ctx := context.Background()
 
-	if err != nil || !token.Valid {
+	// Validasi refresh token
+	claims, err := utils.ParseRefreshToken(refreshToken)
+	if err != nil {
 		return "", "", errors.New("invalid refresh token")
 	}
 
-	claims, ok := token.Claims.(*jwt.RegisteredClaims)
-	if !ok {
-		return "", "", errors.New("invalid token claims")
-	}
+	userID := claims.UserID()
 
-	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	// Dapatkan user dari database
+	user, err := s.userRepo.FindByID(userID)
 	if err != nil {
-		return "", "", errors.New("invalid user ID in token")
+		return "", "", fmt.Errorf("user lookup failed: %w", err)
+	}
+	if user == nil {
+		return "", "", errors.New("user not found")
+	}
+	// Session user yang dinonaktifkan sudah dicabut; ini berjaga-jaga jika
+	// pencabutan gagal
+	if !user.Active {
+		return "", "", ErrRefreshTokenRevoked
 	}
 
-	// Dapatkan user dari database
-	user, err := s.userRepo.FindByID(uint(userID))
+	// Rotasi: jti lama hanya boleh dipakai sekali
+	newRefreshTokenID := utils.NewTokenID()
+	result, err := s.rotateRefreshFamily(ctx, userID, claims.FamilyID, claims.ID, newRefreshTokenID)
 	if err != nil {
-		return "", "", errors.New("user not found")
+		s.logger.Errorf("Failed to rotate refresh token family: %v", err)
+		return "", "", errors.New("failed to complete refresh")
+	}
+
+	switch result {
+	case rotateResultUnknownFamily:
+		return "", "", ErrRefreshTokenRevoked
+	case rotateResultReused:
+		s.logger.WithFields(logrus.Fields{
+			"user_id":   userID,
+			"family_id": claims.FamilyID,
+			"jti":       claims.ID,
+		}).Warn("Refresh token reuse detected, revoking token family")
+
+		if err := s.revokeSessions(ctx, userID, claims.FamilyID); err != nil {
+			s.logger.Errorf("Failed to revoke refresh token family: %v", err)
+		}
+		return "", "", ErrRefreshTokenReused
 	}
 
 	// Generate new tokens
-	newAccessToken, err := utils.GenerateToken(s.cfg, user.ID, user.Email, string(user.Role))
+	newAccessTokenID := utils.NewTokenID()
+	newAccessToken, err := utils.GenerateToken(s.cfg, user.ID, user.Email, string(user.Role), claims.FamilyID, newAccessTokenID)
 	if err != nil {
 		return "", "", errors.New("failed to generate token")
 	}
 
-	newRefreshToken, err := utils.GenerateRefreshToken(s.cfg, user.ID)
+	newRefreshToken, err := utils.GenerateRefreshToken(s.cfg, user.ID, claims.FamilyID, newRefreshTokenID)
 	if err != nil {
 		return "", "", errors.New("failed to generate token")
 	}
 
 	// Simpan token baru
-	if err := s.StoreToken(user.ID, newAccessToken); err != nil {
+	if err := s.StoreToken(user.ID, claims.FamilyID, newAccessTokenID); err != nil {
 		return "", "", errors.New("failed to complete refresh")
 	}
 
 	return newAccessToken, newRefreshToken, nil
 }
 
-func (s *authService) InvalidateOtherSessions(userID uint) error {
-	ctx := context.Background()
-	pattern := fmt.Sprintf("user:%d:token:*", userID)
-
-	// Dapatkan semua active tokens untuk user ini
-	keys, err := s.redisClient.Keys(ctx, pattern).Result()
-	if err != nil {
-		return err
-	}
-
-	// Blacklist semua token yang ada
-	for _, key := range keys {
-		token := strings.TrimPrefix(key, fmt.Sprintf("user:%d:token:", userID))
-		s.redisClient.Set(ctx, token, "blacklisted", s.cfg.JWTExpire)
-	}
+// InvalidateOtherSessions mencabut semua session user di perangkat lain,
+// misalnya setelah user mengganti password. Session keepSessionID tetap aktif.
+func (s *authService) InvalidateOtherSessions(userID uint, keepSessionID string) error {
+	return s.revokeOtherSessions(context.Background(), userID, keepSessionID)
+}
 
-	// Hapus semua active tokens
-	_, err = s.redisClient.Del(ctx, keys...).Result()
-	return err
+// RevokeAllSessions mencabut semua session user di semua perangkat, misalnya
+// setelah password di-reset
+func (s *authService) RevokeAllSessions(userID uint) error {
+	return s.revokeAllSessions(context.Background(), userID)
 }
 
 // Helper function untuk debugging
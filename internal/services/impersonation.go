@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/utils"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+var ErrImpersonationNotAllowed = errors.New("this user cannot be impersonated")
+
+const impersonationDeviceName = "Impersonation"
+
+type ImpersonationResult struct {
+	AccessToken string
+	SessionID   string
+	ExpiresAt   time.Time
+	User        *entities.Users
+}
+
+// Impersonate menerbitkan access token berumur pendek atas nama targetID untuk
+// super admin actorID. Token membawa claim "act" berisi actor dan tidak
+// memiliki refresh token.
+//
+// Session impersonasi sengaja tidak dimasukkan ke index session user, sehingga
+// tidak muncul di daftar perangkat user dan tidak memengaruhi batas session.
+// Session tersebut dicatat di index impersonasi target dan actor, sehingga
+// ikut dicabut saat semua session salah satu dari keduanya dicabut.
+func (s *authService) Impersonate(actorID, targetID uint, meta entities.SessionMeta) (*ImpersonationResult, error) {
+	ctx := context.Background()
+
+	actor, err := s.userRepo.FindByID(actorID)
+	if err != nil {
+		return nil, fmt.Errorf("user lookup failed: %w", err)
+	}
+	if actor == nil || actor.Role != entities.SuperAdmin {
+		return nil, ErrImpersonationNotAllowed
+	}
+
+	target, err := s.userRepo.FindByID(targetID)
+	if err != nil {
+		return nil, fmt.Errorf("user lookup failed: %w", err)
+	}
+	if target == nil {
+		return nil, errors.New("user not found")
+	}
+	if target.ID == actor.ID || target.Role == entities.SuperAdmin || !target.Active {
+		return nil, ErrImpersonationNotAllowed
+	}
+
+	sessionID := utils.NewTokenID()
+	tokenID := utils.NewTokenID()
+	expiresAt := time.Now().Add(s.cfg.ImpersonationExpire)
+
+	accessToken, err := utils.GenerateImpersonationToken(
+		target.ID, target.Email, string(target.Role), sessionID, tokenID,
+		utils.Actor{Subject: strconv.FormatUint(uint64(actor.ID), 10), Email: actor.Email},
+		s.cfg.ImpersonationExpire,
+	)
+	if err != nil {
+		s.logger.Errorf("Failed to generate impersonation token: %v", err)
+		return nil, errors.New("failed to generate token")
+	}
+
+	key := utils.SessionKey(target.ID, sessionID)
+	_, err = s.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key,
+			"device_name", impersonationDeviceName,
+			"ip_address", meta.IPAddress,
+			"user_agent", meta.UserAgent,
+			"created_at", time.Now().Unix(),
+			"last_seen_at", time.Now().Unix(),
+			"expires_at", expiresAt.Unix(),
+			"impersonator_id", actor.ID,
+		)
+		pipe.Expire(ctx, key, s.cfg.ImpersonationExpire)
+
+		targetIndex := utils.UserImpersonationsKey(target.ID)
+		pipe.SAdd(ctx, targetIndex, sessionID)
+		pipe.Expire(ctx, targetIndex, s.cfg.ImpersonationExpire)
+
+		actorIndex := utils.ImpersonatorSessionsKey(actor.ID)
+		pipe.SAdd(ctx, actorIndex, fmt.Sprintf("%d:%s", target.ID, sessionID))
+		pipe.Expire(ctx, actorIndex, s.cfg.ImpersonationExpire)
+		return nil
+	})
+	if err != nil {
+		s.logger.Errorf("Failed to create impersonation session: %v", err)
+		return nil, errors.New("failed to start impersonation")
+	}
+
+	if err := s.StoreToken(target.ID, sessionID, tokenID); err != nil {
+		s.logger.Errorf("Failed to store impersonation token: %v", err)
+		return nil, errors.New("failed to start impersonation")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"impersonator_id": actor.ID,
+		"user_id":         target.ID,
+		"session_id":      sessionID,
+	}).Warn("Impersonation started")
+
+	return &ImpersonationResult{
+		AccessToken: accessToken,
+		SessionID:   sessionID,
+		ExpiresAt:   expiresAt,
+		User:        target,
+	}, nil
+}
+
+// EndImpersonation mencabut session impersonasi beserta token-nya
+func (s *authService) EndImpersonation(userID uint, sessionID string) error {
+	if err := s.revokeSessions(context.Background(), userID, sessionID); err != nil {
+		s.logger.Errorf("Failed to end impersonation session: %v", err)
+		return errors.New("failed to end impersonation")
+	}
+	return nil
+}
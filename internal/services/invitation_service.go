@@ -0,0 +1,405 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/configs"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/mailer"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/repositories"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/utils"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvitationInvalid         = errors.New("invitation is invalid or has expired")
+	ErrInvitationNotFound        = errors.New("invitation not found")
+	ErrInvitationAlreadyAccepted = errors.New("invitation has already been accepted")
+	ErrInvitationRoleNotAllowed  = errors.New("not allowed to invite a user with this role")
+	ErrEmailAlreadyRegistered    = errors.New("email already registered")
+)
+
+const (
+	// Password sementara user yang belum menerima undangan. Nilainya acak dan
+	// tidak pernah dikirim ke siapa pun, sehingga akun tidak bisa dipakai login.
+	pendingUserPasswordSize = 32
+
+	// invitationSendWorkers membatasi jumlah email undangan massal yang
+	// dikirim bersamaan
+	invitationSendWorkers = 4
+
+	defaultInviterName = "Administrator Ara Medika"
+)
+
+type InvitationResult struct {
+	Invitation *entities.UserInvitation
+	// EmailSent false jika undangan tersimpan tetapi email gagal dikirim;
+	// admin bisa mengirim ulang lewat endpoint resend
+	EmailSent bool
+	// EmailQueued true jika email dikirim di background (undangan massal).
+	// Hasil pengiriman hanya dicatat di log; undangan yang emailnya gagal
+	// terkirim bisa dikirim ulang lewat endpoint resend.
+	EmailQueued bool
+}
+
+type InvitationService interface {
+	Invite(invitedBy uint, inviterRole entities.Role, req entities.InvitationCreateRequest) (*InvitationResult, error)
+	InviteMany(invitedBy uint, inviterRole entities.Role, reqs []entities.InvitationCreateRequest) ([]InvitationResult, error)
+	List(status string) ([]entities.UserInvitation, error)
+	Resend(id uint) (*InvitationResult, error)
+	Accept(token, password string) error
+}
+
+type invitationService struct {
+	invitationRepo repositories.InvitationRepository
+	userRepo       repositories.UserRepository
+	passwordPolicy PasswordPolicyService
+	roleService    RoleService
+	mailer         mailer.Mailer
+	cfg            *configs.Config
+	logger         *logrus.Logger
+}
+
+func NewInvitationService(
+	invitationRepo repositories.InvitationRepository,
+	userRepo repositories.UserRepository,
+	passwordPolicy PasswordPolicyService,
+	roleService RoleService,
+	mailer mailer.Mailer,
+	cfg *configs.Config,
+	logger *logrus.Logger,
+) InvitationService {
+	return &invitationService{
+		invitationRepo: invitationRepo,
+		userRepo:       userRepo,
+		passwordPolicy: passwordPolicy,
+		roleService:    roleService,
+		mailer:         mailer,
+		cfg:            cfg,
+		logger:         logger,
+	}
+}
+
+// Invite membuat user nonaktif dan mengirim tautan undangan ke emailnya
+func (s *invitationService) Invite(invitedBy uint, inviterRole entities.Role, req entities.InvitationCreateRequest) (*InvitationResult, error) {
+	// Undangan tunduk pada hierarki role yang sama dengan perubahan role
+	if err := s.checkCanInvite(inviterRole, req.Role); err != nil {
+		return nil, err
+	}
+
+	existingUser, err := s.userRepo.FindByEmail(req.Email)
+	if err != nil {
+		s.logger.Errorf("Error checking email existence: %v", err)
+		return nil, errors.New("failed to check email availability")
+	}
+	if existingUser != nil {
+		return nil, ErrEmailAlreadyRegistered
+	}
+
+	user, invitation, tokenID, err := s.newPendingInvitation(invitedBy, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.invitationRepo.CreateWithUser(user, invitation); err != nil {
+		s.logger.Errorf("Failed to store invitation for %s: %v", req.Email, err)
+		return nil, errors.New("failed to create invitation")
+	}
+	invitation.User = user
+
+	s.logger.WithFields(logrus.Fields{
+		"invitation_id": invitation.ID,
+		"user_id":       user.ID,
+		"invited_by":    invitedBy,
+	}).Info("User invited")
+
+	return &InvitationResult{
+		Invitation: invitation,
+		EmailSent:  s.sendInvitation(invitation, tokenID, s.inviterName(invitation.InvitedBy)),
+	}, nil
+}
+
+// InviteMany mengundang beberapa user sekaligus. Semua user dibuat dalam satu
+// transaksi: jika satu gagal (misalnya email sudah terdaftar), tidak ada yang
+// dibuat. Email undangan dikirim setelah transaksi berhasil.
+func (s *invitationService) InviteMany(invitedBy uint, inviterRole entities.Role, reqs []entities.InvitationCreateRequest) ([]InvitationResult, error) {
+	checked := make(map[entities.Role]bool)
+	emails := make([]string, 0, len(reqs))
+	for _, req := range reqs {
+		if !checked[req.Role] {
+			if err := s.checkCanInvite(inviterRole, req.Role); err != nil {
+				return nil, err
+			}
+			checked[req.Role] = true
+		}
+		emails = append(emails, req.Email)
+	}
+
+	registered, err := s.userRepo.FindRegisteredEmails(emails)
+	if err != nil {
+		s.logger.Errorf("Error checking email existence: %v", err)
+		return nil, errors.New("failed to check email availability")
+	}
+	if len(registered) > 0 {
+		return nil, ErrEmailAlreadyRegistered
+	}
+
+	users := make([]*entities.Users, len(reqs))
+	invitations := make([]*entities.UserInvitation, len(reqs))
+	tokenIDs := make([]string, len(reqs))
+	for i, req := range reqs {
+		users[i], invitations[i], tokenIDs[i], err = s.newPendingInvitation(invitedBy, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.invitationRepo.CreateManyWithUsers(users, invitations); err != nil {
+		s.logger.Errorf("Failed to store %d invitations: %v", len(reqs), err)
+		return nil, errors.New("failed to create invitations")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"count":      len(reqs),
+		"invited_by": invitedBy,
+	}).Info("Users invited")
+
+	results := make([]InvitationResult, len(reqs))
+	for i, invitation := range invitations {
+		invitation.User = users[i]
+		results[i] = InvitationResult{
+			Invitation:  invitation,
+			EmailQueued: true,
+		}
+	}
+
+	// Email dikirim di background agar request tidak tertahan oleh SMTP;
+	// user dan undangan sudah tersimpan di titik ini
+	go s.sendInvitations(invitations, tokenIDs, s.inviterName(&invitedBy))
+	return results, nil
+}
+
+// sendInvitations mengirim email undangan massal dengan paling banyak
+// invitationSendWorkers pengiriman bersamaan
+func (s *invitationService) sendInvitations(invitations []*entities.UserInvitation, tokenIDs []string, inviterName string) {
+	var (
+		wg      sync.WaitGroup
+		sent    atomic.Int64
+		pending = make(chan int)
+	)
+	for w := 0; w < invitationSendWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range pending {
+				if s.sendInvitation(invitations[i], tokenIDs[i], inviterName) {
+					sent.Add(1)
+				}
+			}
+		}()
+	}
+	for i := range invitations {
+		pending <- i
+	}
+	close(pending)
+	wg.Wait()
+
+	logger := s.logger.WithFields(logrus.Fields{
+		"count": len(invitations),
+		"sent":  sent.Load(),
+	})
+	if int(sent.Load()) < len(invitations) {
+		logger.Warn("Some invitation emails could not be sent")
+		return
+	}
+	logger.Info("Invitation emails sent")
+}
+
+func (s *invitationService) checkCanInvite(inviterRole, role entities.Role) error {
+	allowed, err := s.roleService.CanGrant(context.Background(), inviterRole, role)
+	if err != nil {
+		s.logger.Errorf("Failed to check role hierarchy: %v", err)
+		return errors.New("failed to create invitation")
+	}
+	if !allowed {
+		return ErrInvitationRoleNotAllowed
+	}
+	return nil
+}
+
+// newPendingInvitation menyiapkan user nonaktif dengan password acak beserta
+// undangannya (belum disimpan). tokenID adalah rahasia tautan undangan.
+func (s *invitationService) newPendingInvitation(invitedBy uint, req entities.InvitationCreateRequest) (*entities.Users, *entities.UserInvitation, string, error) {
+	placeholder, err := utils.GenerateRandomToken(pendingUserPasswordSize)
+	if err != nil {
+		return nil, nil, "", errors.New("failed to create invitation")
+	}
+	hashedPassword, err := utils.HashPassword(placeholder)
+	if err != nil {
+		s.logger.Errorf("Failed to hash placeholder password: %v", err)
+		return nil, nil, "", errors.New("failed to create invitation")
+	}
+
+	user := &entities.Users{
+		Name:     req.Name,
+		Email:    req.Email,
+		Password: hashedPassword,
+		Role:     req.Role,
+	}
+
+	now := time.Now()
+	tokenID := utils.NewTokenID()
+	invitation := &entities.UserInvitation{
+		TokenHash:  utils.HashToken(tokenID),
+		InvitedBy:  &invitedBy,
+		ExpiresAt:  now.Add(s.cfg.InvitationExpire),
+		SentCount:  1,
+		LastSentAt: &now,
+	}
+	return user, invitation, tokenID, nil
+}
+
+func (s *invitationService) List(status string) ([]entities.UserInvitation, error) {
+	invitations, err := s.invitationRepo.FindAll(status)
+	if err != nil {
+		s.logger.Errorf("Failed to list invitations: %v", err)
+		return nil, errors.New("failed to list invitations")
+	}
+	return invitations, nil
+}
+
+// Resend menerbitkan tautan baru dengan masa berlaku baru. Tautan yang
+// dikirim sebelumnya tidak berlaku lagi.
+func (s *invitationService) Resend(id uint) (*InvitationResult, error) {
+	invitation, err := s.invitationRepo.FindByID(id)
+	if err != nil {
+		s.logger.Errorf("Failed to find invitation %d: %v", id, err)
+		return nil, errors.New("failed to resend invitation")
+	}
+	if invitation == nil || invitation.User == nil {
+		return nil, ErrInvitationNotFound
+	}
+	if invitation.AcceptedAt != nil {
+		return nil, ErrInvitationAlreadyAccepted
+	}
+
+	now := time.Now()
+	tokenID := utils.NewTokenID()
+	invitation.TokenHash = utils.HashToken(tokenID)
+	invitation.ExpiresAt = now.Add(s.cfg.InvitationExpire)
+	invitation.SentCount++
+	invitation.LastSentAt = &now
+
+	if err := s.invitationRepo.UpdateToken(invitation); err != nil {
+		s.logger.Errorf("Failed to update invitation %d: %v", id, err)
+		return nil, errors.New("failed to resend invitation")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"invitation_id": invitation.ID,
+		"sent_count":    invitation.SentCount,
+	}).Info("Invitation resent")
+
+	return &InvitationResult{
+		Invitation: invitation,
+		EmailSent:  s.sendInvitation(invitation, tokenID, s.inviterName(invitation.InvitedBy)),
+	}, nil
+}
+
+// Accept memverifikasi tautan undangan, menyimpan password pilihan user, lalu
+// mengaktifkan akun dan menandai emailnya terverifikasi
+func (s *invitationService) Accept(token, password string) error {
+	invitationID, tokenID, err := utils.ParseInvitationToken(token)
+	if err != nil {
+		return ErrInvitationInvalid
+	}
+
+	invitation, err := s.invitationRepo.FindByID(invitationID)
+	if err != nil {
+		s.logger.Errorf("Failed to find invitation %d: %v", invitationID, err)
+		return errors.New("failed to accept invitation")
+	}
+	if invitation == nil || invitation.User == nil {
+		return ErrInvitationInvalid
+	}
+	if subtle.ConstantTimeCompare([]byte(invitation.TokenHash), []byte(utils.HashToken(tokenID))) != 1 {
+		return ErrInvitationInvalid
+	}
+	if invitation.Status() != entities.InvitationPending {
+		return ErrInvitationInvalid
+	}
+
+	if err := s.passwordPolicy.Validate(invitation.UserID, password); err != nil {
+		return err
+	}
+
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		s.logger.Errorf("Failed to hash new password: %v", err)
+		return errors.New("failed to process password")
+	}
+
+	if err := s.invitationRepo.Accept(invitation, hashedPassword); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvitationInvalid
+		}
+		s.logger.Errorf("Failed to accept invitation %d: %v", invitation.ID, err)
+		return errors.New("failed to accept invitation")
+	}
+	s.passwordPolicy.Record(invitation.UserID, hashedPassword)
+
+	s.logger.WithFields(logrus.Fields{
+		"invitation_id": invitation.ID,
+		"user_id":       invitation.UserID,
+	}).Info("Invitation accepted")
+	return nil
+}
+
+// sendInvitation mengirim email undangan dan melaporkan apakah berhasil.
+// Kegagalan kirim tidak membatalkan undangan.
+func (s *invitationService) sendInvitation(invitation *entities.UserInvitation, tokenID, inviterName string) bool {
+	token, err := utils.GenerateInvitationToken(invitation.ID, tokenID, invitation.ExpiresAt)
+	if err != nil {
+		s.logger.Errorf("Failed to generate invitation token: %v", err)
+		return false
+	}
+
+	msg, err := mailer.InvitationMessage(invitation.User.Email, mailer.InvitationData{
+		Name:      invitation.User.Name,
+		InvitedBy: inviterName,
+		Link:      s.cfg.AppBaseURL + "/accept-invitation?token=" + url.QueryEscape(token),
+		ExpiresIn: s.cfg.InvitationExpire.String(),
+	})
+	if err != nil {
+		s.logger.Errorf("Failed to render invitation email: %v", err)
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), mailSendTimeout)
+	defer cancel()
+
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		s.logger.Errorf("Failed to send invitation email for invitation %d: %v", invitation.ID, err)
+		return false
+	}
+	return true
+}
+
+// inviterName adalah nama pengundang yang ditampilkan di email undangan
+func (s *invitationService) inviterName(invitedBy *uint) string {
+	if invitedBy == nil {
+		return defaultInviterName
+	}
+	inviter, err := s.userRepo.FindByID(*invitedBy)
+	if err != nil || inviter == nil {
+		return defaultInviterName
+	}
+	return inviter.Name
+}
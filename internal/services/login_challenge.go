@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// Login challenge adalah token acak berumur pendek yang diberikan /auth/login
+// ketika password sudah benar tetapi login belum boleh diselesaikan (masih perlu
+// kode two-factor atau password sudah kedaluwarsa). Token hanya disimpan dalam
+// bentuk hash.
+
+var ErrLoginChallengeInvalid = errors.New("login challenge is invalid or has expired")
+
+const (
+	challengePurposeMFA            = "mfa"
+	challengePurposePasswordChange = "password_change"
+	maxChallengeAttempts           = 5
+	challengeTokenByteSize         = 32
+)
+
+// incrChallengeAttemptsScript menambah hitungan percobaan hanya jika challenge
+// masih ada, sehingga challenge yang kedaluwarsa di antara HGETALL dan HINCRBY
+// tidak dibuat ulang tanpa TTL. HINCRBY pada key yang ada mempertahankan TTL.
+//
+// KEYS[1] = login_challenge:<hash>
+var incrChallengeAttemptsScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return -1
+end
+return redis.call('HINCRBY', KEYS[1], 'attempts', 1)
+`)
+
+type loginChallenge struct {
+	tokenHash string
+	purpose   string
+	userID    uint
+	meta      entities.SessionMeta
+}
+
+func loginChallengeKey(tokenHash string) string {
+	return fmt.Sprintf("login_challenge:%s", tokenHash)
+}
+
+func (s *authService) createLoginChallenge(ctx context.Context, purpose string, userID uint, meta entities.SessionMeta) (string, error) {
+	token, err := utils.GenerateRandomToken(challengeTokenByteSize)
+	if err != nil {
+		return "", err
+	}
+
+	key := loginChallengeKey(utils.HashToken(token))
+	_, err = s.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key,
+			"purpose", purpose,
+			"user_id", userID,
+			"device_name", meta.DeviceName,
+			"ip_address", meta.IPAddress,
+			"user_agent", meta.UserAgent,
+			"attempts", 0,
+		)
+		pipe.Expire(ctx, key, s.cfg.MFAChallengeExpire)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// loadLoginChallenge membaca challenge dan menambah hitungan percobaan. Setelah
+// maxChallengeAttempts percobaan, challenge dihapus dan user harus login ulang.
+func (s *authService) loadLoginChallenge(ctx context.Context, purpose, token string) (*loginChallenge, error) {
+	tokenHash := utils.HashToken(token)
+	key := loginChallengeKey(tokenHash)
+
+	fields, err := s.redisClient.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load login challenge: %w", err)
+	}
+	if len(fields) == 0 || fields["purpose"] != purpose {
+		return nil, ErrLoginChallengeInvalid
+	}
+
+	attempts, err := incrChallengeAttemptsScript.Run(ctx, s.redisClient, []string{key}).Int64()
+	if err != nil {
+		return nil, fmt.Errorf("failed to record challenge attempt: %w", err)
+	}
+	if attempts < 0 {
+		return nil, ErrLoginChallengeInvalid
+	}
+	if attempts > maxChallengeAttempts {
+		s.deleteLoginChallenge(ctx, tokenHash)
+		return nil, ErrLoginChallengeInvalid
+	}
+
+	userID, err := strconv.ParseUint(fields["user_id"], 10, 32)
+	if err != nil {
+		return nil, ErrLoginChallengeInvalid
+	}
+
+	return &loginChallenge{
+		tokenHash: tokenHash,
+		purpose:   purpose,
+		userID:    uint(userID),
+		meta: entities.SessionMeta{
+			DeviceName: fields["device_name"],
+			IPAddress:  fields["ip_address"],
+			UserAgent:  fields["user_agent"],
+		},
+	}, nil
+}
+
+func (s *authService) deleteLoginChallenge(ctx context.Context, tokenHash string) {
+	if err := s.redisClient.Del(ctx, loginChallengeKey(tokenHash)).Err(); err != nil {
+		s.logger.Warnf("Failed to delete login challenge: %v", err)
+	}
+}
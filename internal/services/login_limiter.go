@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/configs"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// LoginThrottledError dikembalikan ketika login ditolak karena terlalu banyak
+// percobaan gagal. RetryAfter menunjukkan kapan client boleh mencoba lagi.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+	Locked     bool
+}
+
+func (e *LoginThrottledError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("account temporarily locked, retry after %s", e.RetryAfter)
+	}
+	return fmt.Sprintf("too many login attempts, retry after %s", e.RetryAfter)
+}
+
+// LoginLimiter menghitung login yang gagal per email dan per IP client
+type LoginLimiter interface {
+	Check(ctx context.Context, email, ip string) error
+	RecordFailure(ctx context.Context, email, ip string) error
+	Reset(ctx context.Context, email string) error
+	Unlock(ctx context.Context, email string) error
+}
+
+type loginLimiter struct {
+	redisClient *redis.Client
+	cfg         *configs.Config
+	logger      *logrus.Logger
+}
+
+func NewLoginLimiter(redisClient *redis.Client, cfg *configs.Config, logger *logrus.Logger) LoginLimiter {
+	return &loginLimiter{
+		redisClient: redisClient,
+		cfg:         cfg,
+		logger:      logger,
+	}
+}
+
+// recordFailureScript menambah counter kegagalan lalu memasang lock atau delay.
+//
+// KEYS[1] = counter, KEYS[2] = lock, KEYS[3] = delay
+// ARGV[1] = window (ms), ARGV[2] = batas kegagalan sebelum lock,
+// ARGV[3] = durasi lock (ms), ARGV[4] = jumlah kegagalan sebelum delay,
+// ARGV[5] = delay awal (ms), ARGV[6] = delay maksimal (ms)
+//
+// Mengembalikan jumlah kegagalan saat ini, atau -1 jika lock baru dipasang.
+var recordFailureScript = redis.NewScript(`
+local failures = redis.call('INCR', KEYS[1])
+if failures == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+
+local maxAttempts = tonumber(ARGV[2])
+if maxAttempts > 0 and failures >= maxAttempts then
+	redis.call('SET', KEYS[2], '1', 'PX', ARGV[3])
+	redis.call('DEL', KEYS[1], KEYS[3])
+	return -1
+end
+
+local delayAfter = tonumber(ARGV[4])
+if delayAfter >= 0 and failures > delayAfter then
+	local delay = tonumber(ARGV[5]) * math.pow(2, failures - delayAfter - 1)
+	delay = math.min(delay, tonumber(ARGV[6]))
+	if delay > 0 then
+		redis.call('SET', KEYS[3], '1', 'PX', math.floor(delay))
+	end
+end
+
+return failures
+`)
+
+type limiterScope struct {
+	name        string
+	value       string
+	maxAttempts int
+}
+
+func (l *loginLimiter) scopes(email, ip string) []limiterScope {
+	scopes := []limiterScope{{
+		name:        "email",
+		value:       normalizeEmail(email),
+		maxAttempts: l.cfg.LoginMaxAttempts,
+	}}
+
+	if ip != "" {
+		scopes = append(scopes, limiterScope{
+			name:        "ip",
+			value:       ip,
+			maxAttempts: l.cfg.LoginMaxAttemptsPerIP,
+		})
+	}
+
+	return scopes
+}
+
+// Check menolak login jika email atau IP sedang dikunci atau masih dalam masa
+// tunggu progressive delay
+func (l *loginLimiter) Check(ctx context.Context, email, ip string) error {
+	var result *LoginThrottledError
+
+	for _, scope := range l.scopes(email, ip) {
+		for _, key := range []string{loginLockKey(scope), loginDelayKey(scope)} {
+			ttl, err := l.redisClient.PTTL(ctx, key).Result()
+			if err != nil {
+				return fmt.Errorf("failed to check login throttle: %w", err)
+			}
+			if ttl <= 0 {
+				continue
+			}
+
+			locked := key == loginLockKey(scope)
+			if result == nil || ttl > result.RetryAfter {
+				result = &LoginThrottledError{RetryAfter: ttl, Locked: locked}
+			}
+		}
+	}
+
+	if result != nil {
+		return result
+	}
+	return nil
+}
+
+func (l *loginLimiter) RecordFailure(ctx context.Context, email, ip string) error {
+	for _, scope := range l.scopes(email, ip) {
+		failures, err := recordFailureScript.Run(ctx, l.redisClient,
+			[]string{loginFailuresKey(scope), loginLockKey(scope), loginDelayKey(scope)},
+			l.cfg.LoginAttemptWindow.Milliseconds(),
+			scope.maxAttempts,
+			l.cfg.LoginLockoutDuration.Milliseconds(),
+			l.cfg.LoginDelayAfter,
+			l.cfg.LoginBaseDelay.Milliseconds(),
+			l.cfg.LoginMaxDelay.Milliseconds(),
+		).Int64()
+		if err != nil {
+			return fmt.Errorf("failed to record login failure: %w", err)
+		}
+
+		if failures < 0 {
+			l.logger.WithFields(logrus.Fields{
+				"scope": scope.name,
+				"value": scope.value,
+			}).Warnf("Login locked for %s after %d failed attempts", l.cfg.LoginLockoutDuration, scope.maxAttempts)
+		}
+	}
+
+	return nil
+}
+
+// Reset menghapus counter kegagalan email setelah login berhasil. Counter IP
+// sengaja tidak di-reset agar satu akun valid tidak bisa dipakai untuk
+// "membersihkan" IP yang sedang menebak password akun lain.
+func (l *loginLimiter) Reset(ctx context.Context, email string) error {
+	scope := l.scopes(email, "")[0]
+	return l.redisClient.Del(ctx, loginFailuresKey(scope), loginDelayKey(scope)).Err()
+}
+
+// Unlock dipakai admin untuk membuka kunci akun sebelum masa lock berakhir
+func (l *loginLimiter) Unlock(ctx context.Context, email string) error {
+	scope := l.scopes(email, "")[0]
+	return l.redisClient.Del(ctx, loginFailuresKey(scope), loginLockKey(scope), loginDelayKey(scope)).Err()
+}
+
+func loginFailuresKey(scope limiterScope) string {
+	return fmt.Sprintf("login_failures:%s:%s", scope.name, scope.value)
+}
+
+func loginLockKey(scope limiterScope) string {
+	return fmt.Sprintf("login_lock:%s:%s", scope.name, scope.value)
+}
+
+func loginDelayKey(scope limiterScope) string {
+	return fmt.Sprintf("login_delay:%s:%s", scope.name, scope.value)
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
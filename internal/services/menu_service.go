@@ -0,0 +1,290 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/configs"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/repositories"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrMenuNotFound       = errors.New("menu not found")
+	ErrMenuCodeExists     = errors.New("a menu with this code already exists under the same parent")
+	ErrMenuParentNotFound = errors.New("parent menu not found")
+	ErrMenuParentCycle    = errors.New("a menu cannot be moved under itself or one of its submenus")
+	ErrMenuUnknownRole    = errors.New("unknown role")
+)
+
+type MenuService interface {
+	// List mengembalikan semua menu (datar, urut priority) beserta role-nya
+	List() ([]entities.Menus, error)
+	// ListForRole mengembalikan menu yang boleh dilihat role, diambil dari
+	// cache Redis jika ada. Submenu dari menu yang tidak boleh dilihat ikut
+	// tersembunyi saat tree dibentuk.
+	ListForRole(role string) ([]entities.Menus, error)
+	Get(id uint) (*entities.Menus, error)
+	Create(req entities.MenuRequest) (*entities.Menus, error)
+	Update(id uint, req entities.MenuRequest) (*entities.Menus, error)
+	Delete(id uint) error
+	Reorder(req entities.MenuReorderRequest) error
+}
+
+// Cache menu per role memakai key berversi (menus:v<versi>:role:<role>).
+// Setiap perubahan menu atau role yang boleh melihatnya menaikkan versi,
+// sehingga semua cache lama langsung tidak terpakai dan habis sendiri lewat
+// TTL tanpa perlu mencari dan menghapus key satu per satu.
+const menuCacheVersionKey = "menus:version"
+
+type menuService struct {
+	menuRepo    repositories.MenuRepository
+	roleRepo    repositories.RoleRepository
+	redisClient *redis.Client
+	cfg         *configs.Config
+	logger      *logrus.Logger
+}
+
+func NewMenuService(menuRepo repositories.MenuRepository, roleRepo repositories.RoleRepository, redisClient *redis.Client, cfg *configs.Config, logger *logrus.Logger) MenuService {
+	return &menuService{
+		menuRepo:    menuRepo,
+		roleRepo:    roleRepo,
+		redisClient: redisClient,
+		cfg:         cfg,
+		logger:      logger,
+	}
+}
+
+func (s *menuService) List() ([]entities.Menus, error) {
+	menus, err := s.menuRepo.FindAll()
+	if err != nil {
+		s.logger.Errorf("Failed to list menus: %v", err)
+		return nil, errors.New("failed to list menus")
+	}
+	return menus, nil
+}
+
+func (s *menuService) ListForRole(role string) ([]entities.Menus, error) {
+	ctx := context.Background()
+
+	// Jika Redis bermasalah, menu tetap dilayani langsung dari database
+	key, err := s.cacheKey(ctx, role)
+	if err != nil {
+		s.logger.Warnf("Failed to read menu cache version: %v", err)
+	} else if cached, err := s.redisClient.Get(ctx, key).Bytes(); err == nil {
+		var menus []entities.Menus
+		if err := json.Unmarshal(cached, &menus); err == nil {
+			return menus, nil
+		}
+	} else if err != redis.Nil {
+		s.logger.Warnf("Failed to read menu cache of role %s: %v", role, err)
+	}
+
+	menus, err := s.menuRepo.FindByRole(role)
+	if err != nil {
+		s.logger.Errorf("Failed to list menus of role %s: %v", role, err)
+		return nil, errors.New("failed to list menus")
+	}
+
+	if key != "" {
+		if data, err := json.Marshal(menus); err == nil {
+			if err := s.redisClient.Set(ctx, key, data, s.cfg.MenuCacheExpire).Err(); err != nil {
+				s.logger.Warnf("Failed to cache menus of role %s: %v", role, err)
+			}
+		}
+	}
+
+	return menus, nil
+}
+
+func (s *menuService) Get(id uint) (*entities.Menus, error) {
+	menu, err := s.menuRepo.FindByID(id)
+	if err != nil {
+		s.logger.Errorf("Failed to find menu %d: %v", id, err)
+		return nil, errors.New("failed to get menu")
+	}
+	if menu == nil {
+		return nil, ErrMenuNotFound
+	}
+	return menu, nil
+}
+
+func (s *menuService) Create(req entities.MenuRequest) (*entities.Menus, error) {
+	menu := &entities.Menus{}
+	if err := s.apply(menu, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.menuRepo.Create(menu); err != nil {
+		s.logger.Errorf("Failed to create menu %s: %v", req.Code, err)
+		return nil, errors.New("failed to create menu")
+	}
+
+	s.invalidateCache()
+	s.logger.WithFields(logrus.Fields{
+		"menu_id": menu.ID,
+		"code":    menu.Code,
+		"roles":   menu.RoleNames(),
+	}).Info("Menu created")
+
+	return menu, nil
+}
+
+func (s *menuService) Update(id uint, req entities.MenuRequest) (*entities.Menus, error) {
+	menu, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.apply(menu, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.menuRepo.Update(menu); err != nil {
+		s.logger.Errorf("Failed to update menu %d: %v", id, err)
+		return nil, errors.New("failed to update menu")
+	}
+
+	s.invalidateCache()
+	s.logger.WithFields(logrus.Fields{
+		"menu_id": menu.ID,
+		"code":    menu.Code,
+		"roles":   menu.RoleNames(),
+	}).Info("Menu updated")
+
+	return menu, nil
+}
+
+// Delete menghapus menu beserta seluruh submenunya
+func (s *menuService) Delete(id uint) error {
+	menu, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.menuRepo.Delete(menu.ID); err != nil {
+		s.logger.Errorf("Failed to delete menu %d: %v", id, err)
+		return errors.New("failed to delete menu")
+	}
+
+	s.invalidateCache()
+	s.logger.WithFields(logrus.Fields{
+		"menu_id": menu.ID,
+		"code":    menu.Code,
+	}).Info("Menu deleted")
+	return nil
+}
+
+// Reorder mengubah priority beberapa menu dalam satu transaksi
+func (s *menuService) Reorder(req entities.MenuReorderRequest) error {
+	updated, err := s.menuRepo.UpdatePriorities(req.Items)
+	if err != nil {
+		s.logger.Errorf("Failed to reorder menus: %v", err)
+		return errors.New("failed to reorder menus")
+	}
+	if !updated {
+		return ErrMenuNotFound
+	}
+
+	s.invalidateCache()
+	s.logger.WithField("count", len(req.Items)).Info("Menus reordered")
+	return nil
+}
+
+func (s *menuService) invalidateCache() {
+	invalidateMenuCache(s.redisClient, s.logger)
+}
+
+// invalidateMenuCache menaikkan versi cache sehingga menu semua role dimuat
+// ulang dari database pada request berikutnya. Dipakai juga oleh RoleService
+// karena menghapus role ikut menghapus relasi menunya.
+func invalidateMenuCache(redisClient *redis.Client, logger *logrus.Logger) {
+	if err := redisClient.Incr(context.Background(), menuCacheVersionKey).Err(); err != nil {
+		logger.Warnf("Failed to invalidate menu cache: %v", err)
+	}
+}
+
+func (s *menuService) cacheKey(ctx context.Context, role string) (string, error) {
+	version, err := s.redisClient.Get(ctx, menuCacheVersionKey).Int64()
+	if err != nil && err != redis.Nil {
+		return "", err
+	}
+	return "menus:v" + strconv.FormatInt(version, 10) + ":role:" + role, nil
+}
+
+// apply memvalidasi request lalu menyalin nilainya ke menu
+func (s *menuService) apply(menu *entities.Menus, req entities.MenuRequest) error {
+	if req.ParentID != nil {
+		if err := s.checkParent(menu.ID, *req.ParentID); err != nil {
+			return err
+		}
+	}
+
+	existing, err := s.menuRepo.FindByCode(req.ParentID, req.Code)
+	if err != nil {
+		s.logger.Errorf("Failed to check menu code %s: %v", req.Code, err)
+		return errors.New("failed to check menu code")
+	}
+	if existing != nil && existing.ID != menu.ID {
+		return ErrMenuCodeExists
+	}
+
+	roles, err := s.roleRepo.FindByNames(req.Roles)
+	if err != nil {
+		s.logger.Errorf("Failed to find roles: %v", err)
+		return errors.New("failed to find roles")
+	}
+	found := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		found[role.Name] = true
+	}
+	for _, name := range req.Roles {
+		if !found[name] {
+			return fmt.Errorf("%w: %s", ErrMenuUnknownRole, name)
+		}
+	}
+
+	menu.ParentID = req.ParentID
+	menu.Code = req.Code
+	menu.Name = req.Name
+	menu.Path = req.Path
+	menu.Icon = req.Icon
+	menu.Priority = req.Priority
+	menu.Roles = roles
+	return nil
+}
+
+// checkParent memastikan induk ada dan bukan menu itu sendiri atau salah satu
+// submenunya. menuID 0 berarti menu baru.
+func (s *menuService) checkParent(menuID, parentID uint) error {
+	visited := make(map[uint]bool)
+	for id := &parentID; id != nil; {
+		if menuID != 0 && *id == menuID {
+			return ErrMenuParentCycle
+		}
+		if visited[*id] {
+			// Data lama sudah berputar; jangan perparah
+			return ErrMenuParentCycle
+		}
+		visited[*id] = true
+
+		parent, err := s.menuRepo.FindByID(*id)
+		if err != nil {
+			s.logger.Errorf("Failed to find parent menu %d: %v", *id, err)
+			return errors.New("failed to check parent menu")
+		}
+		if parent == nil {
+			if *id == parentID {
+				return ErrMenuParentNotFound
+			}
+			return nil
+		}
+		id = parent.ParentID
+	}
+	return nil
+}
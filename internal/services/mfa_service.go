@@ -0,0 +1,321 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/configs"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/repositories"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/utils"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrMFAAlreadyEnabled       = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnabled           = errors.New("two-factor authentication is not enabled")
+	ErrMFAEnrollmentNotStarted = errors.New("two-factor enrollment has not been started or has expired")
+	ErrMFARequiredForRole      = errors.New("two-factor authentication is required for this role")
+	ErrInvalidMFACode          = errors.New("invalid two-factor code")
+)
+
+const (
+	recoveryCodeCount    = 10
+	recoveryCodeAlphabet = "abcdefghijklmnopqrstuvwxyz234567"
+	mfaEnrollmentExpire  = 10 * time.Minute
+)
+
+type MFAStatus struct {
+	Enabled                bool       `json:"enabled"`
+	Required               bool       `json:"required"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	RemainingRecoveryCodes int64      `json:"remaining_recovery_codes"`
+}
+
+type MFAService interface {
+	Status(userID uint) (*MFAStatus, error)
+	BeginEnrollment(userID uint) (*entities.MFAEnrollment, error)
+	ConfirmEnrollment(userID uint, code string) (recoveryCodes []string, err error)
+	Disable(userID uint, code string) error
+	RegenerateRecoveryCodes(userID uint, code string) ([]string, error)
+	Verify(user *entities.Users, code string) error
+	IsRequired(role entities.Role) bool
+}
+
+type mfaService struct {
+	userRepo    repositories.UserRepository
+	mfaRepo     repositories.MFARepository
+	redisClient *redis.Client
+	cfg         *configs.Config
+	logger      *logrus.Logger
+}
+
+func NewMFAService(userRepo repositories.UserRepository, mfaRepo repositories.MFARepository, redisClient *redis.Client, cfg *configs.Config, logger *logrus.Logger) MFAService {
+	return &mfaService{
+		userRepo:    userRepo,
+		mfaRepo:     mfaRepo,
+		redisClient: redisClient,
+		cfg:         cfg,
+		logger:      logger,
+	}
+}
+
+func (s *mfaService) IsRequired(role entities.Role) bool {
+	return s.cfg.IsMFARequired(string(role))
+}
+
+func (s *mfaService) Status(userID uint) (*MFAStatus, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &MFAStatus{
+		Enabled:   user.MFAEnabled,
+		Required:  s.IsRequired(user.Role),
+		EnabledAt: user.MFAEnabledAt,
+	}
+
+	if user.MFAEnabled {
+		count, err := s.mfaRepo.CountUnusedRecoveryCodes(userID)
+		if err != nil {
+			s.logger.Errorf("Failed to count recovery codes of user %d: %v", userID, err)
+			return nil, errors.New("failed to get two-factor status")
+		}
+		status.RemainingRecoveryCodes = count
+	}
+
+	return status, nil
+}
+
+// BeginEnrollment membuat secret TOTP baru yang disimpan sementara di Redis
+// sampai user mengonfirmasinya dengan kode pertama dari aplikasi authenticator.
+func (s *mfaService) BeginEnrollment(userID uint) (*entities.MFAEnrollment, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.MFAEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	encrypted, err := utils.EncryptString(s.cfg.MFAEncryptionKey, secret)
+	if err != nil {
+		s.logger.Errorf("Failed to encrypt MFA secret: %v", err)
+		return nil, errors.New("failed to start enrollment")
+	}
+
+	err = s.redisClient.Set(context.Background(), mfaPendingKey(userID), encrypted, mfaEnrollmentExpire).Err()
+	if err != nil {
+		s.logger.Errorf("Failed to store pending MFA secret: %v", err)
+		return nil, errors.New("failed to start enrollment")
+	}
+
+	return &entities.MFAEnrollment{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(s.cfg.MFAIssuer, user.Email, secret),
+	}, nil
+}
+
+func (s *mfaService) ConfirmEnrollment(userID uint, code string) ([]string, error) {
+	ctx := context.Background()
+
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.MFAEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	encrypted, err := s.redisClient.Get(ctx, mfaPendingKey(userID)).Result()
+	if err == redis.Nil {
+		return nil, ErrMFAEnrollmentNotStarted
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pending secret: %w", err)
+	}
+
+	secret, err := utils.DecryptString(s.cfg.MFAEncryptionKey, encrypted)
+	if err != nil {
+		s.logger.Errorf("Failed to decrypt pending MFA secret: %v", err)
+		return nil, errors.New("failed to confirm enrollment")
+	}
+
+	if err := s.verifyTOTP(ctx, userID, secret, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate recovery codes: %w", err)
+	}
+
+	if err := s.mfaRepo.Enable(userID, encrypted, hashes); err != nil {
+		s.logger.Errorf("Failed to enable MFA for user %d: %v", userID, err)
+		return nil, errors.New("failed to confirm enrollment")
+	}
+
+	if err := s.redisClient.Del(ctx, mfaPendingKey(userID)).Err(); err != nil {
+		s.logger.Warnf("Failed to remove pending MFA secret: %v", err)
+	}
+
+	s.logger.WithField("user_id", userID).Info("Two-factor authentication enabled")
+	return codes, nil
+}
+
+func (s *mfaService) Disable(userID uint, code string) error {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return err
+	}
+	if !user.MFAEnabled {
+		return ErrMFANotEnabled
+	}
+	if s.IsRequired(user.Role) {
+		return ErrMFARequiredForRole
+	}
+
+	if err := s.Verify(user, code); err != nil {
+		return err
+	}
+
+	if err := s.mfaRepo.Disable(userID); err != nil {
+		s.logger.Errorf("Failed to disable MFA for user %d: %v", userID, err)
+		return errors.New("failed to disable two-factor authentication")
+	}
+
+	s.logger.WithField("user_id", userID).Info("Two-factor authentication disabled")
+	return nil
+}
+
+func (s *mfaService) RegenerateRecoveryCodes(userID uint, code string) ([]string, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.MFAEnabled {
+		return nil, ErrMFANotEnabled
+	}
+
+	if err := s.Verify(user, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate recovery codes: %w", err)
+	}
+
+	if err := s.mfaRepo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		s.logger.Errorf("Failed to replace recovery codes of user %d: %v", userID, err)
+		return nil, errors.New("failed to regenerate recovery codes")
+	}
+
+	return codes, nil
+}
+
+// Verify menerima kode TOTP 6 digit atau salah satu recovery code
+func (s *mfaService) Verify(user *entities.Users, code string) error {
+	if !user.MFAEnabled {
+		return ErrMFANotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == utils.TOTPDigits {
+		secret, err := utils.DecryptString(s.cfg.MFAEncryptionKey, user.MFASecret)
+		if err != nil {
+			s.logger.Errorf("Failed to decrypt MFA secret of user %d: %v", user.ID, err)
+			return errors.New("failed to verify two-factor code")
+		}
+		return s.verifyTOTP(context.Background(), user.ID, secret, code)
+	}
+
+	used, err := s.mfaRepo.UseRecoveryCode(user.ID, utils.HashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		s.logger.Errorf("Failed to use recovery code of user %d: %v", user.ID, err)
+		return errors.New("failed to verify two-factor code")
+	}
+	if !used {
+		return ErrInvalidMFACode
+	}
+
+	s.logger.WithField("user_id", user.ID).Warn("Recovery code used for two-factor authentication")
+	return nil
+}
+
+// verifyTOTP memvalidasi kode dan memastikan time step yang sama tidak bisa
+// dipakai dua kali (mencegah replay kode yang tertangkap).
+func (s *mfaService) verifyTOTP(ctx context.Context, userID uint, secret, code string) error {
+	step, ok := utils.ValidateTOTP(secret, code, time.Now())
+	if !ok {
+		return ErrInvalidMFACode
+	}
+
+	ttl := time.Duration(utils.TOTPPeriod*(2*utils.TOTPSkew+1)) * time.Second
+	fresh, err := s.redisClient.SetNX(ctx, fmt.Sprintf("mfa:%d:step:%d", userID, step), 1, ttl).Result()
+	if err != nil {
+		return fmt.Errorf("failed to record TOTP usage: %w", err)
+	}
+	if !fresh {
+		return ErrInvalidMFACode
+	}
+
+	return nil
+}
+
+func (s *mfaService) findUser(userID uint) (*entities.Users, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		s.logger.Errorf("Failed to find user %d: %v", userID, err)
+		return nil, errors.New("failed to get user")
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	return user, nil
+}
+
+func mfaPendingKey(userID uint) string {
+	return fmt.Sprintf("mfa:%d:pending", userID)
+}
+
+// generateRecoveryCodes menghasilkan recovery code berformat "xxxxx-xxxxx"
+// beserta hash-nya untuk disimpan di database
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw := make([]byte, 10)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+
+		chars := make([]byte, len(raw))
+		for j, b := range raw {
+			// Alfabet 32 karakter, jadi 5 bit terbawah tidak bias
+			chars[j] = recoveryCodeAlphabet[b&31]
+		}
+
+		code := string(chars[:5]) + "-" + string(chars[5:])
+		codes = append(codes, code)
+		hashes = append(hashes, utils.HashToken(normalizeRecoveryCode(code)))
+	}
+
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
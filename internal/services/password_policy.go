@@ -0,0 +1,211 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/configs"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/repositories"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/utils"
+	"github.com/anieswahdie1/ara-medika-api.git/pkg/validators"
+	"github.com/sirupsen/logrus"
+)
+
+//go:embed password_blocklist.txt
+var defaultPasswordBlocklist []byte
+
+// PasswordPolicyError berisi semua aturan kebijakan password yang dilanggar
+type PasswordPolicyError struct {
+	Violations []string
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return "password " + strings.Join(e.Violations, ", ")
+}
+
+// PasswordPolicyService menerapkan kebijakan password: panjang minimal, jenis
+// karakter, daftar password bocor, riwayat password dan umur maksimal.
+// Semua jalur yang mengganti password harus melewati service ini.
+type PasswordPolicyService interface {
+	// Validate memeriksa password baru. userID 0 untuk user yang belum ada
+	// sehingga riwayat password tidak diperiksa.
+	Validate(userID uint, password string) error
+	// SetPassword meng-hash lalu menyimpan password baru user beserta
+	// riwayatnya. Password harus sudah lolos Validate.
+	SetPassword(user *entities.Users, password string) error
+	// Record mencatat hash password yang disimpan di luar SetPassword
+	Record(userID uint, hashedPassword string)
+	// IsExpired menentukan apakah user wajib mengganti password saat login
+	IsExpired(user *entities.Users) bool
+}
+
+type passwordPolicyService struct {
+	userRepo    repositories.UserRepository
+	historyRepo repositories.PasswordHistoryRepository
+	blocklist   map[string]struct{}
+	cfg         *configs.Config
+	logger      *logrus.Logger
+}
+
+func NewPasswordPolicyService(
+	userRepo repositories.UserRepository,
+	historyRepo repositories.PasswordHistoryRepository,
+	cfg *configs.Config,
+	logger *logrus.Logger,
+) (PasswordPolicyService, error) {
+	blocklist := make(map[string]struct{})
+	loadPasswordBlocklist(bytes.NewReader(defaultPasswordBlocklist), blocklist)
+
+	if cfg.PasswordBlocklistFile != "" {
+		file, err := os.Open(cfg.PasswordBlocklistFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open password blocklist: %w", err)
+		}
+		defer file.Close()
+
+		if err := loadPasswordBlocklist(file, blocklist); err != nil {
+			return nil, fmt.Errorf("failed to read password blocklist: %w", err)
+		}
+	}
+
+	// strong_password di validator mengikuti konfigurasi yang sama
+	validators.SetPasswordRules(validators.PasswordRules{
+		MinLength:  cfg.PasswordMinLength,
+		MinClasses: cfg.PasswordMinClasses,
+	})
+
+	logger.Infof("Password policy loaded with %d blocklisted passwords", len(blocklist))
+
+	return &passwordPolicyService{
+		userRepo:    userRepo,
+		historyRepo: historyRepo,
+		blocklist:   blocklist,
+		cfg:         cfg,
+		logger:      logger,
+	}, nil
+}
+
+func (s *passwordPolicyService) Validate(userID uint, password string) error {
+	var violations []string
+
+	if utf8.RuneCountInString(password) < s.cfg.PasswordMinLength {
+		violations = append(violations, fmt.Sprintf("must be at least %d characters long", s.cfg.PasswordMinLength))
+	}
+	if validators.PasswordClasses(password) < s.cfg.PasswordMinClasses {
+		violations = append(violations, fmt.Sprintf(
+			"must contain at least %d of: uppercase letters, lowercase letters, numbers, symbols",
+			s.cfg.PasswordMinClasses,
+		))
+	}
+	if s.isBlocklisted(password) {
+		violations = append(violations, "is too common or has appeared in a data breach")
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+
+	// Riwayat diperiksa terakhir karena membandingkan hash cukup mahal
+	if userID != 0 && s.cfg.PasswordHistorySize > 0 {
+		history, err := s.historyRepo.FindRecent(userID, s.cfg.PasswordHistorySize)
+		if err != nil {
+			s.logger.Errorf("Failed to load password history of user %d: %v", userID, err)
+			return errors.New("failed to validate password")
+		}
+		for _, entry := range history {
+			if utils.CheckPassword(password, entry.PasswordHash) {
+				return &PasswordPolicyError{Violations: []string{
+					fmt.Sprintf("must not match any of your last %d passwords", s.cfg.PasswordHistorySize),
+				}}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *passwordPolicyService) SetPassword(user *entities.Users, password string) error {
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		s.logger.Errorf("Failed to hash new password: %v", err)
+		return errors.New("failed to process password")
+	}
+
+	now := time.Now()
+	user.Password = hashedPassword
+	user.PasswordChangedAt = &now
+	if err := s.userRepo.Update(user); err != nil {
+		s.logger.Errorf("Failed to update password of user %d: %v", user.ID, err)
+		return errors.New("failed to update password")
+	}
+
+	s.Record(user.ID, hashedPassword)
+	return nil
+}
+
+// Record gagal tidak membatalkan penggantian password, hanya dicatat di log
+func (s *passwordPolicyService) Record(userID uint, hashedPassword string) {
+	if s.cfg.PasswordHistorySize <= 0 {
+		return
+	}
+
+	entry := &entities.PasswordHistory{UserID: userID, PasswordHash: hashedPassword}
+	if err := s.historyRepo.Create(entry); err != nil {
+		s.logger.Errorf("Failed to record password history of user %d: %v", userID, err)
+		return
+	}
+	if err := s.historyRepo.Prune(userID, s.cfg.PasswordHistorySize); err != nil {
+		s.logger.Warnf("Failed to prune password history of user %d: %v", userID, err)
+	}
+}
+
+func (s *passwordPolicyService) IsExpired(user *entities.Users) bool {
+	if s.cfg.PasswordMaxAge <= 0 {
+		return false
+	}
+	// Umur password yang tidak diketahui dianggap sudah kedaluwarsa
+	if user.PasswordChangedAt == nil {
+		return true
+	}
+	return time.Since(*user.PasswordChangedAt) > s.cfg.PasswordMaxAge
+}
+
+// isBlocklisted mencocokkan password beserta bentuknya tanpa angka dan simbol
+// di akhir, sehingga variasi seperti "Password123!" ikut ditolak
+func (s *passwordPolicyService) isBlocklisted(password string) bool {
+	normalized := strings.ToLower(password)
+	if _, found := s.blocklist[normalized]; found {
+		return true
+	}
+
+	base := strings.TrimRightFunc(normalized, func(r rune) bool {
+		return unicode.IsNumber(r) || unicode.IsPunct(r) || unicode.IsSymbol(r)
+	})
+	if base == "" {
+		return false
+	}
+	_, found := s.blocklist[base]
+	return found
+}
+
+func loadPasswordBlocklist(r io.Reader, blocklist map[string]struct{}) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		blocklist[strings.ToLower(line)] = struct{}{}
+	}
+	return scanner.Err()
+}
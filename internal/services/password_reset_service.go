@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/configs"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/mailer"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/repositories"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/utils"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrPasswordResetTokenInvalid = errors.New("password reset token is invalid or has expired")
+	ErrPasswordResetUserInactive = errors.New("cannot reset the password of an inactive user")
+)
+
+const (
+	passwordResetTokenSize = 32
+	mailSendTimeout        = 30 * time.Second
+)
+
+type PasswordResetService interface {
+	RequestReset(email string) error
+	ResetPassword(token, newPassword string) error
+	// ForceReset dipakai admin: password lama langsung tidak berlaku, semua
+	// session dicabut dan tautan reset dikirim ke email user. emailSent false
+	// berarti password sudah di-reset tetapi email gagal terkirim.
+	ForceReset(userID uint) (emailSent bool, err error)
+}
+
+type passwordResetService struct {
+	userRepo       repositories.UserRepository
+	authService    AuthService
+	passwordPolicy PasswordPolicyService
+	mailer         mailer.Mailer
+	redisClient    *redis.Client
+	cfg            *configs.Config
+	logger         *logrus.Logger
+}
+
+func NewPasswordResetService(
+	userRepo repositories.UserRepository,
+	authService AuthService,
+	passwordPolicy PasswordPolicyService,
+	mailer mailer.Mailer,
+	redisClient *redis.Client,
+	cfg *configs.Config,
+	logger *logrus.Logger,
+) PasswordResetService {
+	return &passwordResetService{
+		userRepo:       userRepo,
+		authService:    authService,
+		passwordPolicy: passwordPolicy,
+		mailer:         mailer,
+		redisClient:    redisClient,
+		cfg:            cfg,
+		logger:         logger,
+	}
+}
+
+func passwordResetKey(tokenHash string) string {
+	return fmt.Sprintf("password_reset:%s", tokenHash)
+}
+
+func userPasswordResetKey(userID uint) string {
+	return fmt.Sprintf("user:%d:password_reset", userID)
+}
+
+// RequestReset membuat token reset dan mengirimkannya lewat email. Untuk
+// mencegah enumerasi akun, email yang tidak terdaftar atau milik user nonaktif
+// tidak menghasilkan error.
+func (s *passwordResetService) RequestReset(email string) error {
+	user, err := s.userRepo.FindByEmail(email)
+	if err != nil {
+		s.logger.Errorf("Failed to find user by email for password reset: %v", err)
+		return errors.New("failed to process password reset")
+	}
+	if user == nil {
+		s.logger.Infof("Password reset requested for unknown email %s", email)
+		return nil
+	}
+	if !user.Active {
+		s.logger.Infof("Password reset requested for inactive user %d", user.ID)
+		return nil
+	}
+
+	msg, err := s.createResetMessage(context.Background(), user)
+	if err != nil {
+		return err
+	}
+
+	// Email dikirim di background supaya waktu respons tidak membedakan email
+	// yang terdaftar dan yang tidak
+	go func() {
+		sendCtx, cancel := context.WithTimeout(context.Background(), mailSendTimeout)
+		defer cancel()
+
+		if err := s.mailer.Send(sendCtx, msg); err != nil {
+			s.logger.Errorf("Failed to send password reset email to user %d: %v", user.ID, err)
+		}
+	}()
+
+	return nil
+}
+
+// ForceReset mengganti password user dengan hash acak yang tidak diketahui
+// siapa pun, mencabut semua session, lalu mengirim tautan reset. Berbeda
+// dengan RequestReset, email dikirim langsung agar admin tahu hasilnya.
+func (s *passwordResetService) ForceReset(userID uint) (bool, error) {
+	ctx := context.Background()
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		s.logger.Errorf("Failed to find user %d for password reset: %v", userID, err)
+		return false, errors.New("failed to reset password")
+	}
+	if user == nil {
+		return false, errors.New("user not found")
+	}
+	if !user.Active {
+		return false, ErrPasswordResetUserInactive
+	}
+
+	placeholder, err := utils.GenerateRandomToken(passwordResetTokenSize)
+	if err != nil {
+		return false, fmt.Errorf("failed to generate placeholder password: %w", err)
+	}
+	hashedPassword, err := utils.HashPassword(placeholder)
+	if err != nil {
+		s.logger.Errorf("Failed to hash placeholder password: %v", err)
+		return false, errors.New("failed to reset password")
+	}
+	if err := s.userRepo.UpdatePasswordHash(user.ID, hashedPassword); err != nil {
+		s.logger.Errorf("Failed to clear password of user %d: %v", user.ID, err)
+		return false, errors.New("failed to reset password")
+	}
+
+	if err := s.authService.RevokeAllSessions(user.ID); err != nil {
+		s.logger.Errorf("Failed to revoke sessions after forced password reset of user %d: %v", user.ID, err)
+		return false, errors.New("password was reset but existing sessions could not be revoked")
+	}
+
+	msg, err := s.createResetMessage(ctx, user)
+	if err != nil {
+		return false, err
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, mailSendTimeout)
+	defer cancel()
+	if err := s.mailer.Send(sendCtx, msg); err != nil {
+		s.logger.Errorf("Failed to send password reset email to user %d: %v", user.ID, err)
+		return false, nil
+	}
+
+	s.logger.WithField("user_id", user.ID).Info("Password reset forced by admin")
+	return true, nil
+}
+
+// createResetMessage menyimpan token reset baru milik user dan menyiapkan
+// email berisi tautannya. Hanya token terbaru yang berlaku.
+func (s *passwordResetService) createResetMessage(ctx context.Context, user *entities.Users) (mailer.Message, error) {
+	token, err := utils.GenerateRandomToken(passwordResetTokenSize)
+	if err != nil {
+		return mailer.Message{}, fmt.Errorf("failed to generate reset token: %w", err)
+	}
+	tokenHash := utils.HashToken(token)
+
+	previous, err := s.redisClient.Get(ctx, userPasswordResetKey(user.ID)).Result()
+	if err != nil && err != redis.Nil {
+		return mailer.Message{}, fmt.Errorf("failed to load previous reset token: %w", err)
+	}
+
+	_, err = s.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if previous != "" {
+			pipe.Del(ctx, passwordResetKey(previous))
+		}
+		pipe.Set(ctx, passwordResetKey(tokenHash), user.ID, s.cfg.PasswordResetExpire)
+		pipe.Set(ctx, userPasswordResetKey(user.ID), tokenHash, s.cfg.PasswordResetExpire)
+		return nil
+	})
+	if err != nil {
+		s.logger.Errorf("Failed to store password reset token: %v", err)
+		return mailer.Message{}, errors.New("failed to process password reset")
+	}
+
+	msg, err := mailer.PasswordResetMessage(user.Email, mailer.PasswordResetData{
+		Name:      user.Name,
+		Link:      s.cfg.AppBaseURL + "/reset-password?token=" + url.QueryEscape(token),
+		ExpiresIn: s.cfg.PasswordResetExpire.String(),
+	})
+	if err != nil {
+		return mailer.Message{}, fmt.Errorf("failed to render reset email: %w", err)
+	}
+	return msg, nil
+}
+
+// ResetPassword memakai token (sekali pakai), mengganti password, lalu mencabut
+// semua session user. Token baru dipakai setelah password baru lolos kebijakan
+// password, sehingga user bisa mencoba lagi dengan tautan yang sama.
+func (s *passwordResetService) ResetPassword(token, newPassword string) error {
+	ctx := context.Background()
+	tokenHash := utils.HashToken(token)
+
+	value, err := s.redisClient.Get(ctx, passwordResetKey(tokenHash)).Result()
+	if err == redis.Nil {
+		return ErrPasswordResetTokenInvalid
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load reset token: %w", err)
+	}
+
+	userID, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return ErrPasswordResetTokenInvalid
+	}
+
+	user, err := s.userRepo.FindByID(uint(userID))
+	if err != nil {
+		s.logger.Errorf("Failed to find user %d for password reset: %v", userID, err)
+		return errors.New("failed to reset password")
+	}
+	if user == nil || !user.Active {
+		return ErrPasswordResetTokenInvalid
+	}
+
+	if err := s.passwordPolicy.Validate(user.ID, newPassword); err != nil {
+		return err
+	}
+
+	// Hapus token secara atomik; jika sudah terpakai oleh request lain, tolak
+	consumed, err := s.redisClient.Del(ctx, passwordResetKey(tokenHash)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to consume reset token: %w", err)
+	}
+	if consumed == 0 {
+		return ErrPasswordResetTokenInvalid
+	}
+
+	if err := s.passwordPolicy.SetPassword(user, newPassword); err != nil {
+		return errors.New("failed to reset password")
+	}
+
+	if err := s.redisClient.Del(ctx, userPasswordResetKey(user.ID)).Err(); err != nil {
+		s.logger.Warnf("Failed to clear password reset pointer of user %d: %v", user.ID, err)
+	}
+
+	if err := s.authService.RevokeAllSessions(user.ID); err != nil {
+		s.logger.Errorf("Failed to revoke sessions after password reset of user %d: %v", user.ID, err)
+		return errors.New("password was reset but existing sessions could not be revoked")
+	}
+
+	s.logger.WithField("user_id", user.ID).Info("Password reset completed")
+	return nil
+}
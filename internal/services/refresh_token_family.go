@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// Refresh token disimpan sebagai "family" di Redis. Satu family dibuat setiap
+// login dan berisi jti refresh token yang masih boleh dipakai. Setiap kali
+// /auth/refresh dipanggil, jti lama diganti dengan jti baru (rotasi). Jika jti
+// yang sudah pernah dipakai muncul lagi, berarti token bocor dan seluruh family
+// (termasuk access token yang diterbitkan dari family tersebut) dicabut.
+//
+// ID family sama dengan ID session, sehingga mencabut family berarti juga
+// mencabut session.
+
+var (
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+	ErrRefreshTokenRevoked = errors.New("refresh token family revoked or expired")
+)
+
+const (
+	rotateResultUnknownFamily = -1
+	rotateResultReused        = 0
+	rotateResultRotated       = 1
+)
+
+// rotateRefreshScript mengganti current_jti secara atomik hanya jika jti yang
+// dikirim client masih merupakan jti aktif milik family tersebut. Session milik
+// family ikut diperpanjang dan last_seen_at-nya diperbarui.
+//
+// KEYS[1] = refresh_family:<fid>, KEYS[2] = refresh_family:<fid>:tokens,
+// KEYS[3] = user:<id>:session:<fid>, KEYS[4] = user:<id>:sessions
+// ARGV[1] = jti yang dipresentasikan, ARGV[2] = jti baru, ARGV[3] = ttl (ms),
+// ARGV[4] = waktu sekarang (unix), ARGV[5] = waktu kedaluwarsa session (unix),
+// ARGV[6] = ID family
+var rotateRefreshScript = redis.NewScript(`
+local current = redis.call('HGET', KEYS[1], 'current_jti')
+if not current then
+	return -1
+end
+if current ~= ARGV[1] then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'current_jti', ARGV[2])
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+redis.call('PEXPIRE', KEYS[2], ARGV[3])
+if redis.call('EXISTS', KEYS[3]) == 1 then
+	redis.call('HSET', KEYS[3], 'last_seen_at', ARGV[4], 'expires_at', ARGV[5])
+	redis.call('PEXPIRE', KEYS[3], ARGV[3])
+	redis.call('ZADD', KEYS[4], ARGV[4], ARGV[6])
+	redis.call('PEXPIRE', KEYS[4], ARGV[3])
+end
+return 1
+`)
+
+// revokeSessionsScript mencabut beberapa session sekaligus secara atomik:
+// semua hash jti access token milik session dimasukkan ke blacklist, lalu
+// family, session, dan entri index dihapus. Key turunan dibentuk di dalam
+// script, jadi script ini mengasumsikan Redis non-cluster.
+//
+// Dengan "*", session impersonasi atas user tersebut dan session impersonasi
+// yang dimulai oleh user tersebut (sebagai actor) ikut dicabut.
+//
+// KEYS[1] = user:<id>:sessions, KEYS[2] = user:<id>:impersonations,
+// KEYS[3] = user:<id>:impersonating
+// ARGV[1] = user ID, ARGV[2] = ttl blacklist (detik), ARGV[3..] = ID session,
+// atau "*" untuk semua session di index user. Dengan "*", ARGV[4] (opsional)
+// adalah session yang tidak ikut dicabut.
+var revokeSessionsScript = redis.NewScript(`
+local function revoke(uid, sid)
+	local sessionKey = 'user:' .. uid .. ':session:' .. sid
+	local impersonator = redis.call('HGET', sessionKey, 'impersonator_id')
+	if impersonator then
+		redis.call('SREM', 'user:' .. impersonator .. ':impersonating', uid .. ':' .. sid)
+	end
+	local tokensKey = 'refresh_family:' .. sid .. ':tokens'
+	for _, hash in ipairs(redis.call('SMEMBERS', tokensKey)) do
+		redis.call('SET', 'blacklist:' .. hash, '1', 'EX', ARGV[2])
+	end
+	redis.call('DEL', 'refresh_family:' .. sid, tokensKey, sessionKey)
+	redis.call('ZREM', 'user:' .. uid .. ':sessions', sid)
+	redis.call('SREM', 'user:' .. uid .. ':impersonations', sid)
+end
+
+local revoked = 0
+if ARGV[3] == '*' then
+	for _, sid in ipairs(redis.call('ZRANGE', KEYS[1], 0, -1)) do
+		if sid ~= ARGV[4] then
+			revoke(ARGV[1], sid)
+			revoked = revoked + 1
+		end
+	end
+	for _, sid in ipairs(redis.call('SMEMBERS', KEYS[2])) do
+		revoke(ARGV[1], sid)
+		revoked = revoked + 1
+	end
+	for _, member in ipairs(redis.call('SMEMBERS', KEYS[3])) do
+		local uid, sid = string.match(member, '^(%d+):(.+)$')
+		if uid then
+			revoke(uid, sid)
+			revoked = revoked + 1
+		end
+	end
+	redis.call('DEL', KEYS[3])
+else
+	for i = 3, #ARGV do
+		revoke(ARGV[1], ARGV[i])
+		revoked = revoked + 1
+	end
+end
+return revoked
+`)
+
+func refreshFamilyKey(familyID string) string {
+	return fmt.Sprintf("refresh_family:%s", familyID)
+}
+
+func refreshFamilyTokensKey(familyID string) string {
+	return fmt.Sprintf("refresh_family:%s:tokens", familyID)
+}
+
+func (s *authService) createRefreshFamily(ctx context.Context, userID uint, familyID, tokenID string) error {
+	_, err := s.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, refreshFamilyKey(familyID),
+			"user_id", strconv.FormatUint(uint64(userID), 10),
+			"current_jti", tokenID,
+		)
+		pipe.Expire(ctx, refreshFamilyKey(familyID), s.cfg.JWTRefreshExpire)
+		return nil
+	})
+	return err
+}
+
+func (s *authService) rotateRefreshFamily(ctx context.Context, userID uint, familyID, presentedID, newID string) (int64, error) {
+	now := time.Now()
+	return rotateRefreshScript.Run(ctx, s.redisClient,
+		[]string{
+			refreshFamilyKey(familyID),
+			refreshFamilyTokensKey(familyID),
+			utils.SessionKey(userID, familyID),
+			utils.UserSessionsKey(userID),
+		},
+		presentedID, newID, s.cfg.JWTRefreshExpire.Milliseconds(),
+		now.Unix(), now.Add(s.cfg.JWTRefreshExpire).Unix(), familyID,
+	).Int64()
+}
+
+// revokeSessions mencabut session tertentu milik user
+func (s *authService) revokeSessions(ctx context.Context, userID uint, sessionIDs ...string) error {
+	if len(sessionIDs) == 0 {
+		return nil
+	}
+	return s.runRevokeSessions(ctx, userID, sessionIDs)
+}
+
+// revokeAllSessions mencabut semua session milik user
+func (s *authService) revokeAllSessions(ctx context.Context, userID uint) error {
+	return s.runRevokeSessions(ctx, userID, []string{"*"})
+}
+
+// revokeOtherSessions mencabut semua session milik user kecuali keepSessionID
+func (s *authService) revokeOtherSessions(ctx context.Context, userID uint, keepSessionID string) error {
+	return s.runRevokeSessions(ctx, userID, []string{"*", keepSessionID})
+}
+
+func (s *authService) runRevokeSessions(ctx context.Context, userID uint, sessionIDs []string) error {
+	args := make([]interface{}, 0, len(sessionIDs)+2)
+	args = append(args, userID, int64(s.cfg.JWTExpire.Seconds()))
+	for _, sessionID := range sessionIDs {
+		args = append(args, sessionID)
+	}
+
+	err := revokeSessionsScript.Run(ctx, s.redisClient,
+		[]string{
+			utils.UserSessionsKey(userID),
+			utils.UserImpersonationsKey(userID),
+			utils.ImpersonatorSessionsKey(userID),
+		},
+		args...,
+	).Err()
+	if err != nil {
+		return fmt.Errorf("failed to revoke sessions of user %d: %w", userID, err)
+	}
+
+	return nil
+}
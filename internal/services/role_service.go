@@ -0,0 +1,305 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/configs"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/repositories"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrRoleNotFound      = errors.New("role not found")
+	ErrRoleAlreadyExists = errors.New("role already exists")
+	ErrInvalidRoleName   = errors.New("role name must start with a letter and contain only lowercase letters, digits and underscores")
+	ErrSystemRole        = errors.New("system roles cannot be deleted")
+	ErrRoleInUse         = errors.New("role is still assigned to users; users in the trash must be restored or purged first")
+	ErrUnknownPermission = errors.New("unknown permission")
+)
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+
+type RoleService interface {
+	List() ([]entities.Roles, error)
+	Get(id uint) (*entities.Roles, error)
+	Create(req entities.RoleCreateRequest) (*entities.Roles, error)
+	Update(id uint, req entities.RoleUpdateRequest) (*entities.Roles, error)
+	Delete(id uint) error
+	SetPermissions(id uint, permissions []string) (*entities.Roles, error)
+	ListPermissions() ([]entities.Permissions, error)
+	// RolePermissions mengembalikan nama permission milik role, diambil dari
+	// cache Redis jika ada
+	RolePermissions(ctx context.Context, role string) ([]string, error)
+	RoleExists(name string) bool
+	// CanGrant memeriksa apakah user dengan actorRole boleh memberikan role
+	// kepada user lain
+	CanGrant(ctx context.Context, actorRole, role entities.Role) (bool, error)
+}
+
+type roleService struct {
+	roleRepo    repositories.RoleRepository
+	redisClient *redis.Client
+	cfg         *configs.Config
+	logger      *logrus.Logger
+}
+
+func NewRoleService(roleRepo repositories.RoleRepository, redisClient *redis.Client, cfg *configs.Config, logger *logrus.Logger) RoleService {
+	return &roleService{
+		roleRepo:    roleRepo,
+		redisClient: redisClient,
+		cfg:         cfg,
+		logger:      logger,
+	}
+}
+
+func (s *roleService) List() ([]entities.Roles, error) {
+	roles, err := s.roleRepo.FindAll()
+	if err != nil {
+		s.logger.Errorf("Failed to list roles: %v", err)
+		return nil, errors.New("failed to list roles")
+	}
+	return roles, nil
+}
+
+func (s *roleService) Get(id uint) (*entities.Roles, error) {
+	role, err := s.roleRepo.FindByID(id)
+	if err != nil {
+		s.logger.Errorf("Failed to find role %d: %v", id, err)
+		return nil, errors.New("failed to get role")
+	}
+	if role == nil {
+		return nil, ErrRoleNotFound
+	}
+	return role, nil
+}
+
+func (s *roleService) Create(req entities.RoleCreateRequest) (*entities.Roles, error) {
+	if !roleNamePattern.MatchString(req.Name) {
+		return nil, ErrInvalidRoleName
+	}
+
+	existing, err := s.roleRepo.FindByName(req.Name)
+	if err != nil {
+		s.logger.Errorf("Failed to check role %s: %v", req.Name, err)
+		return nil, errors.New("failed to create role")
+	}
+	if existing != nil {
+		return nil, ErrRoleAlreadyExists
+	}
+
+	permissions, err := s.resolvePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &entities.Roles{
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: permissions,
+	}
+	if err := s.roleRepo.Create(role); err != nil {
+		s.logger.Errorf("Failed to create role %s: %v", req.Name, err)
+		return nil, errors.New("failed to create role")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"role":        role.Name,
+		"permissions": role.PermissionNames(),
+	}).Info("Role created")
+
+	return role, nil
+}
+
+func (s *roleService) Update(id uint, req entities.RoleUpdateRequest) (*entities.Roles, error) {
+	role, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	role.Description = req.Description
+	if err := s.roleRepo.Update(role); err != nil {
+		s.logger.Errorf("Failed to update role %d: %v", id, err)
+		return nil, errors.New("failed to update role")
+	}
+	return role, nil
+}
+
+// Delete menghapus role buatan admin. Role sistem dan role yang masih dipakai
+// user tidak bisa dihapus.
+func (s *roleService) Delete(id uint) error {
+	role, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	if role.IsSystem {
+		return ErrSystemRole
+	}
+
+	count, err := s.roleRepo.CountUsers(role.Name)
+	if err != nil {
+		s.logger.Errorf("Failed to count users of role %s: %v", role.Name, err)
+		return errors.New("failed to delete role")
+	}
+	if count > 0 {
+		return ErrRoleInUse
+	}
+
+	if err := s.roleRepo.Delete(role.ID); err != nil {
+		s.logger.Errorf("Failed to delete role %s: %v", role.Name, err)
+		return errors.New("failed to delete role")
+	}
+
+	s.invalidateCache(role.Name)
+	// role_menus ikut terhapus; role baru dengan nama yang sama tidak boleh
+	// mendapat menu dari cache lama
+	invalidateMenuCache(s.redisClient, s.logger)
+	s.logger.WithField("role", role.Name).Info("Role deleted")
+	return nil
+}
+
+// SetPermissions mengganti seluruh permission role. Cache dihapus sehingga
+// perubahan berlaku pada request berikutnya tanpa user perlu login ulang.
+func (s *roleService) SetPermissions(id uint, names []string) (*entities.Roles, error) {
+	role, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := s.resolvePermissions(names)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.roleRepo.ReplacePermissions(role, permissions); err != nil {
+		s.logger.Errorf("Failed to update permissions of role %s: %v", role.Name, err)
+		return nil, errors.New("failed to update role permissions")
+	}
+	role.Permissions = permissions
+
+	s.invalidateCache(role.Name)
+	s.logger.WithFields(logrus.Fields{
+		"role":        role.Name,
+		"permissions": role.PermissionNames(),
+	}).Info("Role permissions updated")
+
+	return role, nil
+}
+
+func (s *roleService) ListPermissions() ([]entities.Permissions, error) {
+	permissions, err := s.roleRepo.FindAllPermissions()
+	if err != nil {
+		s.logger.Errorf("Failed to list permissions: %v", err)
+		return nil, errors.New("failed to list permissions")
+	}
+	return permissions, nil
+}
+
+func (s *roleService) RolePermissions(ctx context.Context, role string) ([]string, error) {
+	key := rolePermissionsKey(role)
+
+	cached, err := s.redisClient.Get(ctx, key).Bytes()
+	if err == nil {
+		var permissions []string
+		if err := json.Unmarshal(cached, &permissions); err == nil {
+			return permissions, nil
+		}
+	} else if err != redis.Nil {
+		// Redis bermasalah: tetap layani request dari database
+		s.logger.Warnf("Failed to read permission cache of role %s: %v", role, err)
+	}
+
+	permissions, err := s.roleRepo.FindPermissionNamesByRole(role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load permissions of role %s: %w", role, err)
+	}
+	if permissions == nil {
+		permissions = []string{}
+	}
+
+	if data, err := json.Marshal(permissions); err == nil {
+		if err := s.redisClient.Set(ctx, key, data, s.cfg.PermissionCacheExpire).Err(); err != nil {
+			s.logger.Warnf("Failed to cache permissions of role %s: %v", role, err)
+		}
+	}
+
+	return permissions, nil
+}
+
+func (s *roleService) RoleExists(name string) bool {
+	role, err := s.roleRepo.FindByName(name)
+	if err != nil {
+		s.logger.Errorf("Failed to check role %s: %v", name, err)
+		return false
+	}
+	return role != nil
+}
+
+// CanGrant menerapkan hierarki role: super_admin boleh memberikan role apa
+// pun, sedangkan role lain tidak boleh memberikan admin atau super_admin dan
+// hanya boleh memberikan role yang permission-nya juga ia miliki, sehingga
+// role buatan tidak bisa dipakai untuk menaikkan hak akses.
+func (s *roleService) CanGrant(ctx context.Context, actorRole, role entities.Role) (bool, error) {
+	if actorRole == entities.SuperAdmin {
+		return true, nil
+	}
+	if role == entities.Admin || role == entities.SuperAdmin {
+		return false, nil
+	}
+
+	actorPermissions, err := s.RolePermissions(ctx, string(actorRole))
+	if err != nil {
+		return false, err
+	}
+	rolePermissions, err := s.RolePermissions(ctx, string(role))
+	if err != nil {
+		return false, err
+	}
+
+	held := make(map[string]bool, len(actorPermissions))
+	for _, permission := range actorPermissions {
+		held[permission] = true
+	}
+	for _, permission := range rolePermissions {
+		if !held[permission] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// resolvePermissions memastikan semua nama permission terdaftar
+func (s *roleService) resolvePermissions(names []string) ([]entities.Permissions, error) {
+	permissions, err := s.roleRepo.FindPermissionsByNames(names)
+	if err != nil {
+		s.logger.Errorf("Failed to find permissions: %v", err)
+		return nil, errors.New("failed to find permissions")
+	}
+
+	found := make(map[string]bool, len(permissions))
+	for _, permission := range permissions {
+		found[permission.Name] = true
+	}
+	for _, name := range names {
+		if !found[name] {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownPermission, name)
+		}
+	}
+
+	return permissions, nil
+}
+
+func (s *roleService) invalidateCache(role string) {
+	if err := s.redisClient.Del(context.Background(), rolePermissionsKey(role)).Err(); err != nil {
+		s.logger.Warnf("Failed to invalidate permission cache of role %s: %v", role, err)
+	}
+}
+
+func rolePermissionsKey(role string) string {
+	return "permissions:role:" + role
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+const defaultDeviceName = "Unknown device"
+
+func (s *authService) createSession(ctx context.Context, userID uint, sessionID string, meta entities.SessionMeta) error {
+	now := time.Now()
+	deviceName := meta.DeviceName
+	if deviceName == "" {
+		deviceName = defaultDeviceName
+	}
+
+	key := utils.SessionKey(userID, sessionID)
+	indexKey := utils.UserSessionsKey(userID)
+	_, err := s.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key,
+			"device_name", deviceName,
+			"ip_address", meta.IPAddress,
+			"user_agent", meta.UserAgent,
+			"created_at", now.Unix(),
+			"last_seen_at", now.Unix(),
+			"expires_at", now.Add(s.cfg.JWTRefreshExpire).Unix(),
+		)
+		pipe.Expire(ctx, key, s.cfg.JWTRefreshExpire)
+		pipe.ZAdd(ctx, indexKey, redis.Z{Score: float64(now.Unix()), Member: sessionID})
+		pipe.Expire(ctx, indexKey, s.cfg.JWTRefreshExpire)
+		return nil
+	})
+	return err
+}
+
+func (s *authService) ListSessions(userID uint) ([]entities.Session, error) {
+	sessions, err := s.loadSessions(context.Background(), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Session yang paling baru aktif ditampilkan lebih dulu
+	result := make([]entities.Session, 0, len(sessions))
+	for i := len(sessions) - 1; i >= 0; i-- {
+		result = append(result, sessions[i])
+	}
+	return result, nil
+}
+
+func (s *authService) RevokeSession(userID uint, sessionID string) error {
+	ctx := context.Background()
+
+	_, err := s.redisClient.ZScore(ctx, utils.UserSessionsKey(userID), sessionID).Result()
+	if err == redis.Nil {
+		return ErrSessionNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check session: %w", err)
+	}
+
+	return s.revokeSessions(ctx, userID, sessionID)
+}
+
+// loadSessions membaca session user dari index, urut dari yang paling lama
+// tidak aktif. Entri index yang session-nya sudah kedaluwarsa dibersihkan.
+func (s *authService) loadSessions(ctx context.Context, userID uint) ([]entities.Session, error) {
+	indexKey := utils.UserSessionsKey(userID)
+
+	sessionIDs, err := s.redisClient.ZRange(ctx, indexKey, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session index: %w", err)
+	}
+	if len(sessionIDs) == 0 {
+		return []entities.Session{}, nil
+	}
+
+	cmds := make([]*redis.MapStringStringCmd, len(sessionIDs))
+	_, err = s.redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, sessionID := range sessionIDs {
+			cmds[i] = pipe.HGetAll(ctx, utils.SessionKey(userID, sessionID))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sessions: %w", err)
+	}
+
+	var (
+		sessions = make([]entities.Session, 0, len(sessionIDs))
+		expired  = make([]interface{}, 0)
+	)
+	for i, cmd := range cmds {
+		fields := cmd.Val()
+		if len(fields) == 0 {
+			expired = append(expired, sessionIDs[i])
+			continue
+		}
+		sessions = append(sessions, parseSession(userID, sessionIDs[i], fields))
+	}
+
+	if len(expired) > 0 {
+		if err := s.redisClient.ZRem(ctx, indexKey, expired...).Err(); err != nil {
+			s.logger.Warnf("Failed to prune expired sessions of user %d: %v", userID, err)
+		}
+	}
+
+	return sessions, nil
+}
+
+// enforceSessionLimit mencabut session yang paling lama tidak aktif sampai
+// tersisa ruang untuk satu session baru sesuai batas role user.
+func (s *authService) enforceSessionLimit(ctx context.Context, userID uint, role string) error {
+	max := s.cfg.MaxSessionsForRole(role)
+	if max <= 0 {
+		return nil
+	}
+
+	sessions, err := s.loadSessions(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	excess := len(sessions) - max + 1
+	if excess <= 0 {
+		return nil
+	}
+
+	evicted := make([]string, 0, excess)
+	for _, session := range sessions[:excess] {
+		evicted = append(evicted, session.ID)
+	}
+
+	if err := s.revokeSessions(ctx, userID, evicted...); err != nil {
+		return err
+	}
+
+	s.logger.WithField("user_id", userID).
+		Infof("Evicted %d session(s), role %s allows %d concurrent sessions", len(evicted), role, max)
+	return nil
+}
+
+func parseSession(userID uint, sessionID string, fields map[string]string) entities.Session {
+	return entities.Session{
+		ID:         sessionID,
+		UserID:     userID,
+		DeviceName: fields["device_name"],
+		IPAddress:  fields["ip_address"],
+		UserAgent:  fields["user_agent"],
+		CreatedAt:  parseUnix(fields["created_at"]),
+		LastSeenAt: parseUnix(fields["last_seen_at"]),
+		ExpiresAt:  parseUnix(fields["expires_at"]),
+	}
+}
+
+func parseUnix(value string) time.Time {
+	sec, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
@@ -0,0 +1,405 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg" // registrasi decoder untuk image.DecodeConfig
+	_ "image/png"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/configs"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/mailer"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/requests"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/repositories"
+	"github.com/anieswahdie1/ara-medika-api.git/pkg/validators"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// MaxSignatureSize adalah ukuran maksimal gambar tanda tangan
+	MaxSignatureSize      = 512 << 10
+	maxSignatureDimension = 2000
+
+	licenseDateFormat = "2006-01-02"
+)
+
+var (
+	ErrStaffProfileNotFound     = errors.New("staff profile not found")
+	ErrStaffProfileExists       = errors.New("user already has a staff profile")
+	ErrStaffUserNotFound        = errors.New("user not found")
+	ErrLicenseNumberTaken       = errors.New("number is already used by another staff profile")
+	ErrInvalidSignature         = errors.New("signature must be a PNG or JPEG image of at most 512 KB and 2000x2000 pixels")
+	ErrSignatureNotFound        = errors.New("signature not found")
+	ErrInvalidStaffProfileQuery = errors.New("invalid staff profile query")
+)
+
+type StaffProfileListResult struct {
+	Profiles []entities.StaffProfile
+	Total    int64
+	Page     int
+	Limit    int
+}
+
+// StaffProfileService mengelola data profesi tenaga kesehatan (profesi, STR,
+// SIP, NIP, telepon dan tanda tangan). Profil selalu diakses lewat user id
+// karena setiap user punya paling banyak satu profil.
+type StaffProfileService interface {
+	Get(userID uint) (*entities.StaffProfile, error)
+	List(request requests.StaffProfileListRequest) (*StaffProfileListResult, error)
+	Create(userID uint, req entities.StaffProfileRequest) (*entities.StaffProfile, error)
+	Update(userID uint, req entities.StaffProfileRequest) (*entities.StaffProfile, error)
+	Delete(userID uint) error
+	SetSignature(userID uint, data []byte) error
+	Signature(userID uint) ([]byte, string, error)
+	// NotifyExpiringLicenses mengirim email ke user aktif dengan permission
+	// staff_profiles:write berisi STR/SIP yang akan berakhir dalam
+	// LICENSE_EXPIRY_WARNING_DAYS hari dan belum pernah dilaporkan, lalu
+	// mengembalikan jumlah lisensi yang dilaporkan
+	NotifyExpiringLicenses(ctx context.Context) (int, error)
+}
+
+type staffProfileService struct {
+	profileRepo repositories.StaffProfileRepository
+	userRepo    repositories.UserRepository
+	roleRepo    repositories.RoleRepository
+	mailer      mailer.Mailer
+	cfg         *configs.Config
+	logger      *logrus.Logger
+}
+
+func NewStaffProfileService(profileRepo repositories.StaffProfileRepository, userRepo repositories.UserRepository, roleRepo repositories.RoleRepository, mailer mailer.Mailer, cfg *configs.Config, logger *logrus.Logger) StaffProfileService {
+	return &staffProfileService{
+		profileRepo: profileRepo,
+		roleRepo:    roleRepo,
+		userRepo:    userRepo,
+		mailer:      mailer,
+		cfg:         cfg,
+		logger:      logger,
+	}
+}
+
+func (s *staffProfileService) Get(userID uint) (*entities.StaffProfile, error) {
+	profile, err := s.profileRepo.FindByUserID(userID)
+	if err != nil {
+		s.logger.Errorf("Failed to find staff profile of user %d: %v", userID, err)
+		return nil, errors.New("failed to get staff profile")
+	}
+	if profile == nil {
+		return nil, ErrStaffProfileNotFound
+	}
+	return profile, nil
+}
+
+func (s *staffProfileService) List(request requests.StaffProfileListRequest) (*StaffProfileListResult, error) {
+	if request.Page < 1 {
+		request.Page = 1
+	}
+	if request.Limit < 1 {
+		request.Limit = defaultUserPageSize
+	}
+	if request.Limit > maxUserPageSize {
+		request.Limit = maxUserPageSize
+	}
+
+	switch entities.Profession(request.Profession) {
+	case "", entities.ProfessionDoctor, entities.ProfessionDentist, entities.ProfessionNurse,
+		entities.ProfessionMidwife, entities.ProfessionPharmacist:
+	default:
+		return nil, fmt.Errorf("%w: unknown profession %q", ErrInvalidStaffProfileQuery, request.Profession)
+	}
+	switch request.LicenseStatus {
+	case "", entities.LicenseExpiring, entities.LicenseExpired:
+	default:
+		return nil, fmt.Errorf("%w: license_status must be expiring or expired", ErrInvalidStaffProfileQuery)
+	}
+
+	today := entities.DateOf(time.Now())
+	profiles, total, err := s.profileRepo.FindProfiles(repositories.StaffProfileQuery{
+		Profession:     request.Profession,
+		Search:         strings.TrimSpace(request.Search),
+		LicenseStatus:  request.LicenseStatus,
+		Today:          today,
+		ExpiringBefore: today.AddDate(0, 0, s.cfg.LicenseExpiryWarningDays+1),
+		Offset:         (request.Page - 1) * request.Limit,
+		Limit:          request.Limit,
+	})
+	if err != nil {
+		s.logger.Errorf("Failed to find staff profiles: %v", err)
+		return nil, errors.New("failed to list staff profiles")
+	}
+
+	return &StaffProfileListResult{
+		Profiles: profiles,
+		Total:    total,
+		Page:     request.Page,
+		Limit:    request.Limit,
+	}, nil
+}
+
+func (s *staffProfileService) Create(userID uint, req entities.StaffProfileRequest) (*entities.StaffProfile, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		s.logger.Errorf("Failed to find user %d: %v", userID, err)
+		return nil, errors.New("failed to create staff profile")
+	}
+	if user == nil {
+		return nil, ErrStaffUserNotFound
+	}
+
+	existing, err := s.profileRepo.FindByUserID(userID)
+	if err != nil {
+		s.logger.Errorf("Failed to find staff profile of user %d: %v", userID, err)
+		return nil, errors.New("failed to create staff profile")
+	}
+	if existing != nil {
+		return nil, ErrStaffProfileExists
+	}
+
+	profile := &entities.StaffProfile{UserID: userID}
+	applyStaffProfileRequest(profile, req)
+	if err := s.checkDuplicateNumber(profile); err != nil {
+		return nil, err
+	}
+
+	if err := s.profileRepo.Create(profile); err != nil {
+		s.logger.Errorf("Failed to create staff profile of user %d: %v", userID, err)
+		return nil, errors.New("failed to create staff profile")
+	}
+	return s.Get(userID)
+}
+
+// Update mengganti seluruh data profil kecuali tanda tangan. Tanda lisensi
+// sudah dilaporkan dihapus jika nomor atau tanggal berakhirnya berubah,
+// sehingga lisensi yang diperpanjang akan dilaporkan lagi saat mendekati
+// tanggal berakhir yang baru.
+func (s *staffProfileService) Update(userID uint, req entities.StaffProfileRequest) (*entities.StaffProfile, error) {
+	profile, err := s.Get(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := *profile
+	applyStaffProfileRequest(profile, req)
+	if profile.STRNumber != previous.STRNumber || !sameDate(profile.STRExpiresAt, previous.STRExpiresAt) {
+		profile.STRAlertedAt = nil
+	}
+	if profile.SIPNumber != previous.SIPNumber || !sameDate(profile.SIPExpiresAt, previous.SIPExpiresAt) {
+		profile.SIPAlertedAt = nil
+	}
+
+	if err := s.checkDuplicateNumber(profile); err != nil {
+		return nil, err
+	}
+
+	if err := s.profileRepo.Update(profile); err != nil {
+		s.logger.Errorf("Failed to update staff profile of user %d: %v", userID, err)
+		return nil, errors.New("failed to update staff profile")
+	}
+	return s.Get(userID)
+}
+
+func (s *staffProfileService) Delete(userID uint) error {
+	deleted, err := s.profileRepo.DeleteByUserID(userID)
+	if err != nil {
+		s.logger.Errorf("Failed to delete staff profile of user %d: %v", userID, err)
+		return errors.New("failed to delete staff profile")
+	}
+	if !deleted {
+		return ErrStaffProfileNotFound
+	}
+	return nil
+}
+
+// SetSignature menyimpan gambar tanda tangan setelah memastikan isinya
+// benar-benar PNG atau JPEG, tidak hanya berdasarkan nama file
+func (s *staffProfileService) SetSignature(userID uint, data []byte) error {
+	if len(data) == 0 || len(data) > MaxSignatureSize {
+		return ErrInvalidSignature
+	}
+
+	contentType := http.DetectContentType(data)
+	if contentType != "image/png" && contentType != "image/jpeg" {
+		return ErrInvalidSignature
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || config.Width > maxSignatureDimension || config.Height > maxSignatureDimension {
+		return ErrInvalidSignature
+	}
+
+	updated, err := s.profileRepo.UpdateSignature(userID, data, contentType)
+	if err != nil {
+		s.logger.Errorf("Failed to save signature of user %d: %v", userID, err)
+		return errors.New("failed to save signature")
+	}
+	if !updated {
+		return ErrStaffProfileNotFound
+	}
+	return nil
+}
+
+func (s *staffProfileService) Signature(userID uint) ([]byte, string, error) {
+	data, contentType, err := s.profileRepo.FindSignature(userID)
+	if err != nil {
+		s.logger.Errorf("Failed to load signature of user %d: %v", userID, err)
+		return nil, "", errors.New("failed to load signature")
+	}
+	if len(data) == 0 || contentType == "" {
+		return nil, "", ErrSignatureNotFound
+	}
+	return data, contentType, nil
+}
+
+func (s *staffProfileService) NotifyExpiringLicenses(ctx context.Context) (int, error) {
+	if s.cfg.LicenseExpiryWarningDays <= 0 {
+		return 0, nil
+	}
+
+	today := entities.DateOf(time.Now())
+	expiresBefore := today.AddDate(0, 0, s.cfg.LicenseExpiryWarningDays+1)
+	profiles, err := s.profileRepo.FindUnalertedLicenses(expiresBefore)
+	if err != nil {
+		return 0, fmt.Errorf("find expiring licenses: %w", err)
+	}
+
+	var (
+		items          []mailer.LicenseExpiryItem
+		strIDs, sipIDs []uint
+	)
+	for _, profile := range profiles {
+		licenses := []struct {
+			kind      string
+			number    string
+			expiresAt *time.Time
+			alertedAt *time.Time
+			ids       *[]uint
+		}{
+			{entities.LicenseSTR, profile.STRNumber, profile.STRExpiresAt, profile.STRAlertedAt, &strIDs},
+			{entities.LicenseSIP, profile.SIPNumber, profile.SIPExpiresAt, profile.SIPAlertedAt, &sipIDs},
+		}
+		for _, license := range licenses {
+			if license.number == "" || license.expiresAt == nil || license.alertedAt != nil || !license.expiresAt.Before(expiresBefore) {
+				continue
+			}
+
+			items = append(items, mailer.LicenseExpiryItem{
+				StaffName:  profile.User.Name,
+				Profession: string(profile.Profession),
+				Type:       license.kind,
+				Number:     license.number,
+				ExpiresAt:  license.expiresAt.Format(licenseDateFormat),
+				Expired:    license.expiresAt.Before(today),
+			})
+			*license.ids = append(*license.ids, profile.ID)
+		}
+	}
+	if len(items) == 0 {
+		return 0, nil
+	}
+
+	// Penerima adalah user aktif yang boleh mengelola profil staf, termasuk
+	// role buatan yang diberi staff_profiles:write
+	roles, err := s.roleRepo.FindRoleNamesByPermission(entities.PermissionStaffProfilesWrite)
+	if err != nil {
+		return 0, fmt.Errorf("find roles: %w", err)
+	}
+
+	active := true
+	var admins []entities.Users
+	if len(roles) > 0 {
+		err = s.userRepo.StreamUsers(repositories.UserListQuery{
+			Roles:  roles,
+			Active: &active,
+		}, func(user *entities.Users) error {
+			admins = append(admins, *user)
+			return nil
+		})
+		if err != nil {
+			return 0, fmt.Errorf("find admins: %w", err)
+		}
+	}
+	if len(admins) == 0 {
+		s.logger.Warnf("%d licenses are expiring but there is no active user with %s to notify", len(items), entities.PermissionStaffProfilesWrite)
+		return 0, nil
+	}
+
+	sent := 0
+	for _, admin := range admins {
+		msg, err := mailer.LicenseExpiryMessage(admin.Email, mailer.LicenseExpiryData{
+			Name:        admin.Name,
+			WarningDays: s.cfg.LicenseExpiryWarningDays,
+			Licenses:    items,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("render license expiry email: %w", err)
+		}
+
+		sendCtx, cancel := context.WithTimeout(ctx, mailSendTimeout)
+		err = s.mailer.Send(sendCtx, msg)
+		cancel()
+		if err != nil {
+			s.logger.Errorf("Failed to send license expiry email to user %d: %v", admin.ID, err)
+			continue
+		}
+		sent++
+	}
+	if sent == 0 {
+		return 0, errors.New("failed to send license expiry email to any admin")
+	}
+
+	if err := s.profileRepo.MarkAlerted(strIDs, sipIDs, time.Now()); err != nil {
+		return len(items), fmt.Errorf("mark licenses as alerted: %w", err)
+	}
+	return len(items), nil
+}
+
+func (s *staffProfileService) checkDuplicateNumber(profile *entities.StaffProfile) error {
+	field, err := s.profileRepo.FindDuplicateNumber(profile)
+	if err != nil {
+		s.logger.Errorf("Failed to check license numbers of user %d: %v", profile.UserID, err)
+		return errors.New("failed to check license numbers")
+	}
+	if field != "" {
+		return fmt.Errorf("%s %w", field, ErrLicenseNumberTaken)
+	}
+	return nil
+}
+
+// applyStaffProfileRequest menyalin request yang sudah divalidasi ke profil
+// dengan nomor dalam bentuk ternormalisasi
+func applyStaffProfileRequest(profile *entities.StaffProfile, req entities.StaffProfileRequest) {
+	profile.Profession = req.Profession
+	profile.Specialty = strings.TrimSpace(req.Specialty)
+	profile.STRNumber = validators.NormalizeDigits(req.STRNumber)
+	profile.STRExpiresAt = parseLicenseDate(req.STRExpiresAt)
+	profile.SIPNumber = strings.ToUpper(strings.TrimSpace(req.SIPNumber))
+	profile.SIPExpiresAt = parseLicenseDate(req.SIPExpiresAt)
+	profile.NIP = validators.NormalizeDigits(req.NIP)
+	profile.Phone = ""
+	if req.Phone != "" {
+		profile.Phone = validators.NormalizePhone(req.Phone)
+	}
+}
+
+func parseLicenseDate(value string) *time.Time {
+	if value == "" {
+		return nil
+	}
+	date, err := time.Parse(licenseDateFormat, value)
+	if err != nil {
+		return nil
+	}
+	return &date
+}
+
+func sameDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Format(licenseDateFormat) == b.Format(licenseDateFormat)
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	TokenTypeHintAccessToken  = "access_token"
+	TokenTypeHintRefreshToken = "refresh_token"
+)
+
+// TokenIntrospection adalah hasil introspeksi token sesuai RFC 7662. Untuk
+// token yang tidak aktif hanya field active yang diisi.
+type TokenIntrospection struct {
+	Active    bool   `json:"active"`
+	TokenType string `json:"token_type,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+	Username  string `json:"username,omitempty"`
+	Role      string `json:"role,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+	TokenID   string `json:"jti,omitempty"`
+	SessionID string `json:"sid,omitempty"`
+}
+
+// IntrospectToken memeriksa access atau refresh token dengan aturan yang sama
+// seperti AuthMiddleware dan /auth/refresh: signature dan masa berlaku,
+// blacklist, session yang masih aktif, serta jti refresh token yang belum
+// dirotasi.
+func (s *authService) IntrospectToken(token, tokenTypeHint string) (*TokenIntrospection, error) {
+	ctx := context.Background()
+
+	for _, tokenType := range tokenTypeOrder(tokenTypeHint) {
+		var (
+			result *TokenIntrospection
+			err    error
+		)
+		if tokenType == TokenTypeHintRefreshToken {
+			result, err = s.introspectRefreshToken(ctx, token)
+		} else {
+			result, err = s.introspectAccessToken(ctx, token)
+		}
+		if err != nil || result != nil {
+			return result, err
+		}
+	}
+
+	return &TokenIntrospection{Active: false}, nil
+}
+
+// introspectAccessToken mengembalikan nil jika token bukan access token
+func (s *authService) introspectAccessToken(ctx context.Context, token string) (*TokenIntrospection, error) {
+	claims, err := utils.ValidateToken(token)
+	if err != nil {
+		return nil, nil
+	}
+
+	status, err := utils.CheckTokenStatus(ctx, s.redisClient, claims, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check token status: %w", err)
+	}
+	if status != utils.TokenActive {
+		return &TokenIntrospection{Active: false}, nil
+	}
+
+	return &TokenIntrospection{
+		Active:    true,
+		TokenType: TokenTypeHintAccessToken,
+		Subject:   strconv.FormatUint(uint64(claims.UserID), 10),
+		Username:  claims.Email,
+		Role:      claims.Role,
+		ExpiresAt: claims.ExpiresAt.Unix(),
+		IssuedAt:  claims.IssuedAt.Unix(),
+		TokenID:   claims.ID,
+		SessionID: claims.FamilyID,
+	}, nil
+}
+
+// introspectRefreshToken mengembalikan nil jika token bukan refresh token
+func (s *authService) introspectRefreshToken(ctx context.Context, token string) (*TokenIntrospection, error) {
+	claims, err := utils.ParseRefreshToken(token)
+	if err != nil {
+		return nil, nil
+	}
+
+	currentID, err := s.redisClient.HGet(ctx, refreshFamilyKey(claims.FamilyID), "current_jti").Result()
+	if err == redis.Nil || (err == nil && currentID != claims.ID) {
+		return &TokenIntrospection{Active: false}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load refresh token family: %w", err)
+	}
+
+	user, err := s.userRepo.FindByID(claims.UserID())
+	if err != nil {
+		return nil, fmt.Errorf("user lookup failed: %w", err)
+	}
+	if user == nil || !user.Active {
+		return &TokenIntrospection{Active: false}, nil
+	}
+
+	return &TokenIntrospection{
+		Active:    true,
+		TokenType: TokenTypeHintRefreshToken,
+		Subject:   claims.Subject,
+		Username:  user.Email,
+		Role:      string(user.Role),
+		ExpiresAt: claims.ExpiresAt.Unix(),
+		IssuedAt:  claims.IssuedAt.Unix(),
+		TokenID:   claims.ID,
+		SessionID: claims.FamilyID,
+	}, nil
+}
+
+// RevokeToken mencabut token sesuai RFC 7009. Refresh token mencabut seluruh
+// session beserta access token-nya, sedangkan access token hanya masuk
+// blacklist. Token yang tidak valid atau sudah kedaluwarsa diabaikan.
+func (s *authService) RevokeToken(token, tokenTypeHint string) error {
+	ctx := context.Background()
+
+	for _, tokenType := range tokenTypeOrder(tokenTypeHint) {
+		if tokenType == TokenTypeHintRefreshToken {
+			claims, err := utils.ParseRefreshToken(token)
+			if err != nil {
+				continue
+			}
+			if err := s.revokeSessions(ctx, claims.UserID(), claims.FamilyID); err != nil {
+				s.logger.Errorf("Failed to revoke refresh token family: %v", err)
+				return errors.New("failed to revoke token")
+			}
+			return nil
+		}
+
+		claims, err := utils.ValidateToken(token)
+		if err != nil {
+			continue
+		}
+		if err := s.blacklistAccessToken(ctx, claims); err != nil {
+			s.logger.Errorf("Failed to blacklist token: %v", err)
+			return errors.New("failed to revoke token")
+		}
+		return nil
+	}
+
+	return nil
+}
+
+// blacklistAccessToken memasukkan jti access token ke blacklist sampai token
+// kedaluwarsa
+func (s *authService) blacklistAccessToken(ctx context.Context, claims *utils.JWTClaims) error {
+	expiry := time.Until(claims.ExpiresAt.Time)
+	if expiry <= 0 {
+		return nil
+	}
+	return s.redisClient.Set(ctx, utils.BlacklistKey(claims.ID), "1", expiry).Err()
+}
+
+// tokenTypeOrder menentukan urutan jenis token yang dicoba. token_type_hint
+// hanya petunjuk, jadi jenis lainnya tetap dicoba (RFC 7662 bagian 2.1).
+func tokenTypeOrder(hint string) []string {
+	if hint == TokenTypeHintRefreshToken {
+		return []string{TokenTypeHintRefreshToken, TokenTypeHintAccessToken}
+	}
+	return []string{TokenTypeHintAccessToken, TokenTypeHintRefreshToken}
+}
@@ -0,0 +1,394 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/configs"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/repositories"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/spreadsheet"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/utils"
+	"github.com/anieswahdie1/ara-medika-api.git/pkg/validators"
+	"github.com/go-playground/validator/v10"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrImportFileInvalid    = errors.New("import file could not be read")
+	ErrImportMissingColumns = errors.New("import file is missing required columns")
+	ErrImportEmpty          = errors.New("import file has no data rows")
+	ErrImportTooManyRows    = errors.New("import file has too many rows")
+	ErrImportHasInvalidRows = errors.New("import contains invalid rows; no users were created")
+	ErrImportReportNotFound = errors.New("import report not found or has expired")
+)
+
+var (
+	importRequiredColumns = []string{"name", "email", "role"}
+	importReportHeader    = []string{"row", "name", "email", "role", "status", "errors", "user_id", "email_sent"}
+)
+
+// MaxUserImportFileSize adalah ukuran maksimal file yang diunggah
+const MaxUserImportFileSize = 5 << 20
+
+const (
+	UserImportRowValid   = "valid"
+	UserImportRowInvalid = "invalid"
+	UserImportRowInvited = "invited"
+)
+
+// UserImportRow adalah hasil satu baris file. Row adalah nomor baris di file
+// (header adalah baris 1).
+type UserImportRow struct {
+	Row          int
+	Name         string
+	Email        string
+	Role         string
+	Status       string
+	Errors       []string
+	UserID       uint
+	InvitationID uint
+	EmailSent    bool
+	// EmailQueued true jika email undangan masih dikirim di background
+	EmailQueued bool
+}
+
+type UserImportResult struct {
+	ImportID  string
+	DryRun    bool
+	Committed bool
+	Total     int
+	Valid     int
+	Invalid   int
+	Rows      []UserImportRow
+}
+
+type UserImportService interface {
+	// Import membaca file CSV/XLSX berkolom name, email dan role lalu
+	// memvalidasi setiap baris. Jika dryRun false dan semua baris valid, semua
+	// user dibuat dalam satu transaksi dan diundang lewat email. Jika ada baris
+	// yang tidak valid, hasil validasi dikembalikan bersama
+	// ErrImportHasInvalidRows.
+	Import(actorID uint, actorRole entities.Role, filename string, data []byte, dryRun bool) (*UserImportResult, error)
+	// Report mengembalikan laporan CSV hasil import milik actorID
+	Report(actorID uint, importID string) ([]byte, error)
+}
+
+type userImportService struct {
+	userRepo          repositories.UserRepository
+	roleService       RoleService
+	invitationService InvitationService
+	redisClient       *redis.Client
+	cfg               *configs.Config
+	logger            *logrus.Logger
+}
+
+func NewUserImportService(
+	userRepo repositories.UserRepository,
+	roleService RoleService,
+	invitationService InvitationService,
+	redisClient *redis.Client,
+	cfg *configs.Config,
+	logger *logrus.Logger,
+) UserImportService {
+	return &userImportService{
+		userRepo:          userRepo,
+		roleService:       roleService,
+		invitationService: invitationService,
+		redisClient:       redisClient,
+		cfg:               cfg,
+		logger:            logger,
+	}
+}
+
+func userImportKey(importID string) string {
+	return "user_import:" + importID
+}
+
+func (s *userImportService) Import(actorID uint, actorRole entities.Role, filename string, data []byte, dryRun bool) (*UserImportResult, error) {
+	records, err := spreadsheet.Read(filename, data, s.cfg.UserImportMaxRows)
+	if err != nil {
+		if err == spreadsheet.ErrUnsupportedFormat {
+			return nil, err
+		}
+		if errors.Is(err, spreadsheet.ErrTooManyRows) {
+			return nil, fmt.Errorf("%w: maximum is %d", ErrImportTooManyRows, s.cfg.UserImportMaxRows)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrImportFileInvalid, err)
+	}
+
+	rows, err := s.parseRows(records)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &UserImportResult{
+		ImportID: utils.NewTokenID(),
+		DryRun:   dryRun,
+		Total:    len(rows),
+		Rows:     rows,
+	}
+	if err := s.validateRows(actorRole, result.Rows); err != nil {
+		return nil, err
+	}
+	for _, row := range result.Rows {
+		if row.Status == UserImportRowValid {
+			result.Valid++
+		} else {
+			result.Invalid++
+		}
+	}
+
+	if !dryRun && result.Invalid == 0 {
+		if err := s.commit(actorID, actorRole, result); err != nil {
+			return nil, err
+		}
+	}
+
+	s.storeReport(actorID, result)
+
+	s.logger.WithFields(logrus.Fields{
+		"import_id": result.ImportID,
+		"actor_id":  actorID,
+		"dry_run":   dryRun,
+		"total":     result.Total,
+		"invalid":   result.Invalid,
+		"committed": result.Committed,
+	}).Info("User import processed")
+
+	if !dryRun && result.Invalid > 0 {
+		return result, ErrImportHasInvalidRows
+	}
+	return result, nil
+}
+
+func (s *userImportService) Report(actorID uint, importID string) ([]byte, error) {
+	values, err := s.redisClient.HMGet(context.Background(), userImportKey(importID), "actor_id", "report").Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load import report: %w", err)
+	}
+
+	owner, _ := values[0].(string)
+	report, _ := values[1].(string)
+	if owner == "" || report == "" {
+		return nil, ErrImportReportNotFound
+	}
+	// Laporan berisi data calon user; hanya yang menjalankan import yang boleh
+	// mengunduhnya, dan keberadaannya tidak dibocorkan ke user lain
+	if owner != strconv.FormatUint(uint64(actorID), 10) {
+		return nil, ErrImportReportNotFound
+	}
+	return []byte(report), nil
+}
+
+// parseRows memetakan kolom berdasarkan header (tanpa membedakan huruf besar
+// kecil) dan melewati baris kosong
+func (s *userImportService) parseRows(records [][]string) ([]UserImportRow, error) {
+	if len(records) == 0 {
+		return nil, ErrImportEmpty
+	}
+
+	columns := make(map[string]int)
+	for i, header := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(header))] = i
+	}
+	var missing []string
+	for _, name := range importRequiredColumns {
+		if _, ok := columns[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrImportMissingColumns, strings.Join(missing, ", "))
+	}
+
+	cell := func(record []string, column string) string {
+		if i := columns[column]; i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var rows []UserImportRow
+	for i, record := range records[1:] {
+		row := UserImportRow{
+			Row:   i + 2,
+			Name:  cell(record, "name"),
+			Email: cell(record, "email"),
+			Role:  strings.ToLower(cell(record, "role")),
+		}
+		if row.Name == "" && row.Email == "" && row.Role == "" {
+			continue
+		}
+		if len(rows) == s.cfg.UserImportMaxRows {
+			return nil, fmt.Errorf("%w: maximum is %d", ErrImportTooManyRows, s.cfg.UserImportMaxRows)
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, ErrImportEmpty
+	}
+	return rows, nil
+}
+
+// validateRows memakai aturan yang sama dengan undangan satu per satu:
+// validasi field, hierarki role, email unik di file dan belum terdaftar
+func (s *userImportService) validateRows(actorRole entities.Role, rows []UserImportRow) error {
+	emails := make([]string, 0, len(rows))
+	for _, row := range rows {
+		emails = append(emails, row.Email)
+	}
+	registered, err := s.userRepo.FindRegisteredEmails(emails)
+	if err != nil {
+		s.logger.Errorf("Error checking email existence: %v", err)
+		return errors.New("failed to check email availability")
+	}
+	isRegistered := make(map[string]bool, len(registered))
+	for _, email := range registered {
+		isRegistered[email] = true
+	}
+
+	grantable := make(map[string]bool)
+	firstRow := make(map[string]int)
+	for i := range rows {
+		row := &rows[i]
+		req := entities.InvitationCreateRequest{Name: row.Name, Email: row.Email, Role: entities.Role(row.Role)}
+
+		if err := validators.Validate.Struct(req); err != nil {
+			row.Errors = append(row.Errors, describeValidationErrors(err)...)
+		} else {
+			allowed, checked := grantable[row.Role]
+			if !checked {
+				allowed, err = s.roleService.CanGrant(context.Background(), actorRole, req.Role)
+				if err != nil {
+					s.logger.Errorf("Failed to check role hierarchy: %v", err)
+					return errors.New("failed to check role")
+				}
+				grantable[row.Role] = allowed
+			}
+			if !allowed {
+				row.Errors = append(row.Errors, ErrInvitationRoleNotAllowed.Error())
+			}
+		}
+
+		email := strings.ToLower(row.Email)
+		if email != "" {
+			if first, ok := firstRow[email]; ok {
+				row.Errors = append(row.Errors, fmt.Sprintf("email is duplicated in row %d", first))
+			} else {
+				firstRow[email] = row.Row
+			}
+			if isRegistered[email] {
+				row.Errors = append(row.Errors, ErrEmailAlreadyRegistered.Error())
+			}
+		}
+
+		row.Status = UserImportRowValid
+		if len(row.Errors) > 0 {
+			row.Status = UserImportRowInvalid
+		}
+	}
+	return nil
+}
+
+func (s *userImportService) commit(actorID uint, actorRole entities.Role, result *UserImportResult) error {
+	reqs := make([]entities.InvitationCreateRequest, len(result.Rows))
+	for i, row := range result.Rows {
+		reqs[i] = entities.InvitationCreateRequest{Name: row.Name, Email: row.Email, Role: entities.Role(row.Role)}
+	}
+
+	invitations, err := s.invitationService.InviteMany(actorID, actorRole, reqs)
+	if err != nil {
+		return err
+	}
+
+	for i, invitation := range invitations {
+		row := &result.Rows[i]
+		row.Status = UserImportRowInvited
+		row.UserID = invitation.Invitation.UserID
+		row.InvitationID = invitation.Invitation.ID
+		row.EmailSent = invitation.EmailSent
+		row.EmailQueued = invitation.EmailQueued
+	}
+	result.Committed = true
+	return nil
+}
+
+// storeReport menyimpan laporan CSV di Redis. Kegagalan tidak membatalkan
+// import; laporan hanya tidak bisa diunduh.
+func (s *userImportService) storeReport(actorID uint, result *UserImportResult) {
+	records := make([][]string, 0, len(result.Rows)+1)
+	records = append(records, importReportHeader)
+	for _, row := range result.Rows {
+		userID, emailSent := "", ""
+		if row.Status == UserImportRowInvited {
+			userID = strconv.FormatUint(uint64(row.UserID), 10)
+			emailSent = strconv.FormatBool(row.EmailSent)
+			if row.EmailQueued {
+				emailSent = "queued"
+			}
+		}
+		records = append(records, []string{
+			strconv.Itoa(row.Row),
+			row.Name,
+			row.Email,
+			row.Role,
+			row.Status,
+			strings.Join(row.Errors, "; "),
+			userID,
+			emailSent,
+		})
+	}
+
+	var report bytes.Buffer
+	if err := spreadsheet.WriteCSV(&report, records); err != nil {
+		s.logger.Errorf("Failed to render import report %s: %v", result.ImportID, err)
+		return
+	}
+
+	ctx := context.Background()
+	key := userImportKey(result.ImportID)
+	_, err := s.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, "actor_id", actorID, "report", report.String())
+		pipe.Expire(ctx, key, s.cfg.UserImportReportExpire)
+		return nil
+	})
+	if err != nil {
+		s.logger.Errorf("Failed to store import report %s: %v", result.ImportID, err)
+	}
+}
+
+// describeValidationErrors mengubah error validator menjadi pesan yang bisa
+// dibaca admin di laporan import
+func describeValidationErrors(err error) []string {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return []string{err.Error()}
+	}
+
+	messages := make([]string, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		field := strings.ToLower(fieldErr.Field())
+		switch fieldErr.Tag() {
+		case "required":
+			messages = append(messages, field+" is required")
+		case "email":
+			messages = append(messages, field+" must be a valid email address")
+		case "min":
+			messages = append(messages, fmt.Sprintf("%s must be at least %s characters", field, fieldErr.Param()))
+		case "max":
+			messages = append(messages, fmt.Sprintf("%s must be at most %s characters", field, fieldErr.Param()))
+		case "role":
+			messages = append(messages, fmt.Sprintf("role %q does not exist", fieldErr.Value()))
+		default:
+			messages = append(messages, fmt.Sprintf("%s is invalid (%s)", field, fieldErr.Tag()))
+		}
+	}
+	return messages
+}
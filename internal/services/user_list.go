@@ -0,0 +1,257 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/requests"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/repositories"
+)
+
+const (
+	defaultUserPageSize = 10
+	maxUserPageSize     = 100
+	defaultUserSort     = "-created_at"
+)
+
+var ErrInvalidListQuery = errors.New("invalid list query")
+
+// UserListResult adalah satu halaman daftar user. Total hanya terisi pada
+// paginasi offset (tanpa cursor).
+type UserListResult struct {
+	Users      []entities.Users
+	Total      *int64
+	Page       int
+	Limit      int
+	HasNext    bool
+	NextCursor string
+}
+
+// userSortColumns adalah field yang boleh dipakai di parameter sort. Nilainya
+// menandai kolom bertipe waktu, yang perlu di-parse saat cursor dibaca.
+var userSortColumns = map[string]bool{
+	"id":         false,
+	"name":       false,
+	"email":      false,
+	"role":       false,
+	"created_at": true,
+	"updated_at": true,
+}
+
+// userListCursor menyimpan nilai kolom urutan dari baris terakhir suatu
+// halaman. Sort ikut disimpan agar cursor tidak dipakai dengan urutan lain.
+type userListCursor struct {
+	Sort   string `json:"s"`
+	Values []any  `json:"v"`
+}
+
+func (s *userService) ListUsers(request requests.UserListRequest) (*UserListResult, error) {
+	if request.Page < 1 {
+		request.Page = 1
+	}
+	if request.Limit < 1 {
+		request.Limit = defaultUserPageSize
+	}
+	if request.Limit > maxUserPageSize {
+		request.Limit = maxUserPageSize
+	}
+
+	query, sortKey, err := newUserListQuery(request)
+	if err != nil {
+		return nil, err
+	}
+	// Satu baris tambahan untuk mengetahui apakah masih ada halaman berikutnya
+	query.Limit = request.Limit + 1
+
+	if request.Cursor != "" {
+		query.After, err = decodeUserCursor(request.Cursor, sortKey, query.Sort)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		query.Offset = (request.Page - 1) * request.Limit
+		query.CountTotal = true
+	}
+
+	users, total, err := s.userRepo.FindUsers(query)
+	if err != nil {
+		s.logger.Errorf("Failed to find users: %v", err)
+		return nil, errors.New("failed to list users")
+	}
+
+	result := &UserListResult{Limit: request.Limit}
+	if len(users) > request.Limit {
+		users = users[:request.Limit]
+		result.HasNext = true
+		result.NextCursor = encodeUserCursor(sortKey, query.Sort, &users[len(users)-1])
+	}
+	result.Users = users
+	if query.CountTotal {
+		result.Total = &total
+		result.Page = request.Page
+	}
+
+	return result, nil
+}
+
+// newUserListQuery menerjemahkan filter dan urutan yang sama untuk daftar dan
+// ekspor user. Paginasi diatur oleh pemanggil. Nilai kedua adalah bentuk
+// normal sort untuk dicocokkan dengan cursor.
+func newUserListQuery(request requests.UserListRequest) (repositories.UserListQuery, string, error) {
+	sortKey, sort, err := parseUserSort(request.Sort)
+	if err != nil {
+		return repositories.UserListQuery{}, "", err
+	}
+
+	query := repositories.UserListQuery{
+		Search:      strings.TrimSpace(request.Search),
+		CreatedFrom: request.CreatedFrom,
+		CreatedTo:   request.CreatedTo,
+		Sort:        sort,
+	}
+
+	for _, role := range request.Role {
+		for _, name := range strings.Split(role, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				query.Roles = append(query.Roles, name)
+			}
+		}
+	}
+
+	switch request.Status {
+	case "":
+	case "active", "inactive":
+		active := request.Status == "active"
+		query.Active = &active
+	default:
+		return repositories.UserListQuery{}, "", fmt.Errorf("%w: status must be active or inactive", ErrInvalidListQuery)
+	}
+
+	return query, sortKey, nil
+}
+
+// parseUserSort mengubah parameter sort menjadi kolom urutan yang selalu
+// diakhiri id, serta bentuk normalnya untuk dicocokkan dengan cursor
+func parseUserSort(raw string) (string, []repositories.SortField, error) {
+	if strings.TrimSpace(raw) == "" {
+		raw = defaultUserSort
+	}
+
+	var (
+		fields []repositories.SortField
+		keys   []string
+	)
+	seen := make(map[string]bool)
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		desc := strings.HasPrefix(item, "-")
+		column := strings.TrimPrefix(item, "-")
+
+		if _, ok := userSortColumns[column]; !ok {
+			return "", nil, fmt.Errorf("%w: unknown sort field %q", ErrInvalidListQuery, column)
+		}
+		if seen[column] {
+			return "", nil, fmt.Errorf("%w: duplicate sort field %q", ErrInvalidListQuery, column)
+		}
+		seen[column] = true
+
+		fields = append(fields, repositories.SortField{Column: column, Desc: desc})
+		keys = append(keys, item)
+	}
+
+	// id sebagai pemecah seri mengikuti arah field pertama
+	if !seen["id"] {
+		fields = append(fields, repositories.SortField{Column: "id", Desc: fields[0].Desc})
+	}
+
+	return strings.Join(keys, ","), fields, nil
+}
+
+func encodeUserCursor(sortKey string, sort []repositories.SortField, user *entities.Users) string {
+	cursor := userListCursor{Sort: sortKey, Values: make([]any, len(sort))}
+	for i, field := range sort {
+		cursor.Values[i] = userSortValue(user, field.Column)
+	}
+
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeUserCursor(raw, sortKey string, sort []repositories.SortField) ([]any, error) {
+	invalid := fmt.Errorf("%w: invalid cursor", ErrInvalidListQuery)
+
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, invalid
+	}
+
+	var cursor userListCursor
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&cursor); err != nil {
+		return nil, invalid
+	}
+	if cursor.Sort != sortKey {
+		return nil, fmt.Errorf("%w: cursor was created with a different sort", ErrInvalidListQuery)
+	}
+	if len(cursor.Values) != len(sort) {
+		return nil, invalid
+	}
+
+	values := make([]any, len(sort))
+	for i, field := range sort {
+		switch {
+		case field.Column == "id":
+			number, ok := cursor.Values[i].(json.Number)
+			if !ok {
+				return nil, invalid
+			}
+			id, err := number.Int64()
+			if err != nil || id < 0 {
+				return nil, invalid
+			}
+			values[i] = id
+		case userSortColumns[field.Column]:
+			text, ok := cursor.Values[i].(string)
+			if !ok {
+				return nil, invalid
+			}
+			t, err := time.Parse(time.RFC3339Nano, text)
+			if err != nil {
+				return nil, invalid
+			}
+			values[i] = t
+		default:
+			text, ok := cursor.Values[i].(string)
+			if !ok {
+				return nil, invalid
+			}
+			values[i] = text
+		}
+	}
+	return values, nil
+}
+
+func userSortValue(user *entities.Users, column string) any {
+	switch column {
+	case "id":
+		return user.ID
+	case "name":
+		return user.Name
+	case "email":
+		return user.Email
+	case "role":
+		return string(user.Role)
+	case "created_at":
+		return user.CreatedAt.Format(time.RFC3339Nano)
+	case "updated_at":
+		return user.UpdatedAt.Format(time.RFC3339Nano)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/requests"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/repositories"
+)
+
+func TestParseUserSort(t *testing.T) {
+	tests := []struct {
+		raw     string
+		wantKey string
+		want    []repositories.SortField
+		wantErr bool
+	}{
+		{
+			raw:     "",
+			wantKey: "-created_at",
+			want:    []repositories.SortField{{Column: "created_at", Desc: true}, {Column: "id", Desc: true}},
+		},
+		{
+			raw:     " name , -email ",
+			wantKey: "name,-email",
+			want:    []repositories.SortField{{Column: "name"}, {Column: "email", Desc: true}, {Column: "id"}},
+		},
+		{
+			raw:     "role,-id",
+			wantKey: "role,-id",
+			want:    []repositories.SortField{{Column: "role"}, {Column: "id", Desc: true}},
+		},
+		{raw: "password", wantErr: true},
+		{raw: "name,-name", wantErr: true},
+		{raw: "name,", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			key, sort, err := parseUserSort(tt.raw)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidListQuery) {
+					t.Fatalf("err = %v, want ErrInvalidListQuery", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if key != tt.wantKey {
+				t.Errorf("key = %q, want %q", key, tt.wantKey)
+			}
+			if !reflect.DeepEqual(sort, tt.want) {
+				t.Errorf("sort = %+v, want %+v", sort, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserCursorRoundTrip(t *testing.T) {
+	createdAt := time.Date(2025, 3, 14, 9, 26, 53, 589793000, time.UTC)
+	user := &entities.Users{
+		Model: entities.Model{ID: 42, CreatedAt: createdAt, UpdatedAt: createdAt.Add(time.Hour)},
+		Name:  "Budi",
+		Email: "budi@example.com",
+		Role:  entities.Role("admin"),
+	}
+
+	tests := []struct {
+		sort string
+		want []any
+	}{
+		{"", []any{createdAt, int64(42)}},
+		{"name,-email", []any{"Budi", "budi@example.com", int64(42)}},
+		{"role,-updated_at,id", []any{"admin", createdAt.Add(time.Hour), int64(42)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			key, sort, err := parseUserSort(tt.sort)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			values, err := decodeUserCursor(encodeUserCursor(key, sort, user), key, sort)
+			if err != nil {
+				t.Fatalf("decodeUserCursor: %v", err)
+			}
+			if len(values) != len(tt.want) {
+				t.Fatalf("values = %v, want %v", values, tt.want)
+			}
+			for i, want := range tt.want {
+				if wantTime, ok := want.(time.Time); ok {
+					if got, ok := values[i].(time.Time); !ok || !got.Equal(wantTime) {
+						t.Errorf("values[%d] = %v, want %v", i, values[i], want)
+					}
+					continue
+				}
+				if values[i] != want {
+					t.Errorf("values[%d] = %#v, want %#v", i, values[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeUserCursorRejectsInvalid(t *testing.T) {
+	key, sort, err := parseUserSort("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	encode := func(json string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(json))
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"not base64", "!!!"},
+		{"not JSON", encode("nope")},
+		{"different sort", encode(`{"s":"name","v":["Budi",1]}`)},
+		{"too few values", encode(`{"s":"-created_at","v":["2025-03-14T09:26:53Z"]}`)},
+		{"bad time", encode(`{"s":"-created_at","v":["yesterday",1]}`)},
+		{"time as number", encode(`{"s":"-created_at","v":[1,1]}`)},
+		{"id as string", encode(`{"s":"-created_at","v":["2025-03-14T09:26:53Z","1"]}`)},
+		{"negative id", encode(`{"s":"-created_at","v":["2025-03-14T09:26:53Z",-1]}`)},
+		{"fractional id", encode(`{"s":"-created_at","v":["2025-03-14T09:26:53Z",1.5]}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeUserCursor(tt.cursor, key, sort); !errors.Is(err, ErrInvalidListQuery) {
+				t.Errorf("err = %v, want ErrInvalidListQuery", err)
+			}
+		})
+	}
+}
+
+func TestNewUserListQuery(t *testing.T) {
+	query, _, err := newUserListQuery(requests.UserListRequest{
+		Search: "  budi ",
+		Role:   []string{"admin, user", " ", "nurse"},
+		Status: "inactive",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.Search != "budi" {
+		t.Errorf("search = %q, want %q", query.Search, "budi")
+	}
+	if want := []string{"admin", "user", "nurse"}; !reflect.DeepEqual(query.Roles, want) {
+		t.Errorf("roles = %q, want %q", query.Roles, want)
+	}
+	if query.Active == nil || *query.Active {
+		t.Errorf("active = %v, want false", query.Active)
+	}
+
+	if _, _, err := newUserListQuery(requests.UserListRequest{Status: "deleted"}); !errors.Is(err, ErrInvalidListQuery) {
+		t.Errorf("err = %v, want ErrInvalidListQuery", err)
+	}
+}
@@ -1,39 +1,82 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"io"
+	"time"
 
+	"github.com/anieswahdie1/ara-medika-api.git/internal/configs"
 	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
 	"github.com/anieswahdie1/ara-medika-api.git/internal/models/requests"
 	"github.com/anieswahdie1/ara-medika-api.git/internal/repositories"
 	"github.com/anieswahdie1/ara-medika-api.git/internal/utils"
 	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
 type UserService interface {
-	CreateUser(user *entities.Users) error
+	CreateUser(user *entities.Users, creatorRole entities.Role) error
 	GetUserByID(id uint) (*entities.Users, error)
 	GetUserByEmail(email string) (*entities.Users, error)
 	UpdateUser(user *entities.Users) error
-	DeleteUser(id uint) error
-	ListUsers(request requests.BaseGetListRequest) ([]entities.Users, error)
-	ChangePassword(userID uint, oldPassword, newPassword string) error
-	ListMenus(roles string) ([]entities.Menus, error)
+	// UpdateProfile mengubah profil user yang sedang login; mengganti email
+	// harus disertai password saat ini
+	UpdateProfile(userID uint, req entities.ProfileUpdateRequest) (*entities.Users, error)
+	DeleteUser(actorID, id uint) error
+	SetActive(actorID, id uint, active bool) (*entities.Users, error)
+	// ListUsers mengembalikan satu halaman user sesuai filter, urutan dan
+	// cursor. Query yang tidak valid menghasilkan ErrInvalidListQuery.
+	ListUsers(request requests.UserListRequest) (*UserListResult, error)
+	ExportUsers(request UserExportRequest, w io.Writer) (int, error)
+	// User yang dihapus masuk trash dan bisa di-restore sampai dihapus
+	// permanen, baik manual lewat PurgeUser maupun otomatis setelah masa
+	// retensi lewat PurgeExpiredUsers.
+	ListDeletedUsers(request requests.BaseGetListRequest) (*DeletedUserListResult, error)
+	GetDeletedUser(id uint) (*entities.Users, error)
+	RestoreUser(actorID, id uint) (*entities.Users, error)
+	PurgeUser(actorID, id uint) error
+	PurgeExpiredUsers(ctx context.Context) ([]entities.Users, error)
+	ChangePassword(userID uint, sessionID, oldPassword, newPassword string) error
+	ChangeRole(actorID uint, actorRole entities.Role, userID uint, role entities.Role) (*entities.Users, error)
 }
 
+var (
+	ErrIncorrectPassword        = errors.New("incorrect old password")
+	ErrPasswordRequired         = errors.New("current_password is required to change the email")
+	ErrIncorrectCurrentPassword = errors.New("incorrect current password")
+	ErrRoleNotAllowed           = errors.New("not allowed to assign this role")
+	ErrRoleChangeNotAllowed     = errors.New("not allowed to change the role of this user")
+	ErrCannotChangeOwnRole      = errors.New("cannot change your own role")
+	ErrCannotModifySelf         = errors.New("cannot deactivate or delete your own account")
+	ErrLastSuperAdmin           = errors.New("the last active super_admin cannot be demoted, deactivated or deleted")
+)
+
 type userService struct {
-	userRepo repositories.UserRepository
-	logger   *logrus.Logger
+	userRepo       repositories.UserRepository
+	passwordPolicy PasswordPolicyService
+	authService    AuthService
+	roleService    RoleService
+	cfg            *configs.Config
+	logger         *logrus.Logger
 }
 
-func NewUserService(userRepo repositories.UserRepository, logger *logrus.Logger) UserService {
+func NewUserService(userRepo repositories.UserRepository, passwordPolicy PasswordPolicyService, authService AuthService, roleService RoleService, cfg *configs.Config, logger *logrus.Logger) UserService {
 	return &userService{
-		userRepo: userRepo,
-		logger:   logger,
+		userRepo:       userRepo,
+		passwordPolicy: passwordPolicy,
+		authService:    authService,
+		roleService:    roleService,
+		cfg:            cfg,
+		logger:         logger,
 	}
 }
 
-func (s *userService) CreateUser(user *entities.Users) error {
+func (s *userService) CreateUser(user *entities.Users, creatorRole entities.Role) error {
+	if err := s.checkCanGrant(creatorRole, user.Role); err != nil {
+		return err
+	}
+
 	// Check if email already exists
 	existingUser, err := s.userRepo.FindByEmail(user.Email)
 	if err != nil {
@@ -41,7 +84,11 @@ func (s *userService) CreateUser(user *entities.Users) error {
 		return errors.New("failed to check email availability")
 	}
 	if existingUser != nil {
-		return errors.New("email already registered")
+		return ErrEmailAlreadyRegistered
+	}
+
+	if err := s.passwordPolicy.Validate(0, user.Password); err != nil {
+		return err
 	}
 
 	// Hash password
@@ -51,8 +98,19 @@ func (s *userService) CreateUser(user *entities.Users) error {
 		return err
 	}
 
+	now := time.Now()
 	user.Password = hashedPassword
-	return s.userRepo.Create(user)
+	user.PasswordChangedAt = &now
+	if err := s.userRepo.Create(user); err != nil {
+		// Email didaftarkan request lain di antara pengecekan dan penyimpanan
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrEmailAlreadyRegistered
+		}
+		return err
+	}
+
+	s.passwordPolicy.Record(user.ID, hashedPassword)
+	return nil
 }
 
 func (s *userService) GetUserByID(id uint) (*entities.Users, error) {
@@ -89,14 +147,78 @@ func (s *userService) UpdateUser(user *entities.Users) error {
 		return errors.New("user not found")
 	}
 
-	// Prevent updating certain fields
-	user.Password = existingUser.Password
-	user.Role = existingUser.Role
+	// Hanya nama dan email yang boleh diubah lewat sini; field kosong tidak
+	// mengubah nilai lama. Password, role, status dan MFA punya alur sendiri.
+	if user.Name != "" {
+		existingUser.Name = user.Name
+	}
+	if user.Email != "" && user.Email != existingUser.Email {
+		// Pengecekan tidak membedakan huruf besar/kecil, jadi user boleh
+		// mengganti kapitalisasi emailnya sendiri
+		other, err := s.userRepo.FindByEmail(user.Email)
+		if err != nil {
+			s.logger.Errorf("Error checking email existence: %v", err)
+			return errors.New("failed to check email availability")
+		}
+		if other != nil && other.ID != existingUser.ID {
+			return ErrEmailAlreadyRegistered
+		}
+
+		// Email baru belum pernah diverifikasi
+		existingUser.Email = user.Email
+		existingUser.EmailVerifiedAt = nil
+	}
+
+	if err := s.userRepo.Update(existingUser); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrEmailAlreadyRegistered
+		}
+		s.logger.Errorf("Failed to update user %d: %v", user.ID, err)
+		return errors.New("failed to update user")
+	}
+
+	*user = *existingUser
+	return nil
+}
+
+func (s *userService) UpdateProfile(userID uint, req entities.ProfileUpdateRequest) (*entities.Users, error) {
+	existingUser, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		s.logger.Errorf("Failed to find user %d for profile update: %v", userID, err)
+		return nil, errors.New("failed to find user")
+	}
+	if existingUser == nil {
+		return nil, errors.New("user not found")
+	}
+
+	// Token yang bocor tidak boleh cukup untuk mengganti email lalu mengambil
+	// alih akun lewat lupa password
+	if req.Email != "" && req.Email != existingUser.Email {
+		if req.CurrentPassword == "" {
+			return nil, ErrPasswordRequired
+		}
+		if !utils.CheckPassword(req.CurrentPassword, existingUser.Password) {
+			return nil, ErrIncorrectCurrentPassword
+		}
+	}
 
-	return s.userRepo.Update(user)
+	user := &entities.Users{
+		Model: entities.Model{ID: userID},
+		Name:  req.Name,
+		Email: req.Email,
+	}
+	if err := s.UpdateUser(user); err != nil {
+		return nil, err
+	}
+	return user, nil
 }
 
-func (s *userService) DeleteUser(id uint) error {
+// DeleteUser menghapus (soft delete) user lain lalu mencabut semua session-nya
+func (s *userService) DeleteUser(actorID, id uint) error {
+	if actorID == id {
+		return ErrCannotModifySelf
+	}
+
 	// Check if user exists first
 	existingUser, err := s.userRepo.FindByID(id)
 	if err != nil {
@@ -107,26 +229,75 @@ func (s *userService) DeleteUser(id uint) error {
 		return errors.New("user not found")
 	}
 
-	return s.userRepo.Delete(id)
+	deleted, err := s.userRepo.Delete(id)
+	if err != nil {
+		s.logger.Errorf("Failed to delete user %d: %v", id, err)
+		return errors.New("failed to delete user")
+	}
+	if !deleted {
+		return ErrLastSuperAdmin
+	}
+
+	if err := s.authService.RevokeAllSessions(id); err != nil {
+		s.logger.Errorf("Failed to revoke sessions of deleted user %d: %v", id, err)
+		return errors.New("user was deleted but sessions could not be revoked")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"user_id":    id,
+		"deleted_by": actorID,
+	}).Info("User deleted, sessions revoked")
+	return nil
 }
 
-func (s *userService) ListUsers(request requests.BaseGetListRequest) ([]entities.Users, error) {
-	if request.Page == 0 {
-		request.Page = 1
+// SetActive mengaktifkan atau menonaktifkan user lain. User nonaktif tidak
+// bisa login dan semua session-nya langsung dicabut.
+func (s *userService) SetActive(actorID, id uint, active bool) (*entities.Users, error) {
+	if actorID == id {
+		return nil, ErrCannotModifySelf
 	}
 
-	if request.Limit == 0 {
-		request.Limit = 10
+	user, err := s.userRepo.FindByID(id)
+	if err != nil {
+		s.logger.Errorf("Failed to find user %d for status change: %v", id, err)
+		return nil, errors.New("failed to find user")
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
 	}
-	users, err := s.userRepo.FindUsers(request)
+	if user.Active == active {
+		return user, nil
+	}
+
+	updated, err := s.userRepo.SetActive(id, active)
 	if err != nil {
-		s.logger.Errorf("Failed to find users: %v", err)
-		return nil, errors.New("failed to list users")
+		s.logger.Errorf("Failed to change status of user %d: %v", id, err)
+		return nil, errors.New("failed to change user status")
+	}
+	if !updated {
+		return nil, ErrLastSuperAdmin
 	}
-	return users, nil
+	user.Active = active
+
+	if !active {
+		if err := s.authService.RevokeAllSessions(id); err != nil {
+			s.logger.Errorf("Failed to revoke sessions of deactivated user %d: %v", id, err)
+			return nil, errors.New("user was deactivated but sessions could not be revoked")
+		}
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"user_id":    id,
+		"active":     active,
+		"changed_by": actorID,
+	}).Info("User status changed")
+
+	return user, nil
 }
 
-func (s *userService) ChangePassword(userID uint, oldPassword, newPassword string) error {
+// ChangePassword mengganti password user yang sedang login. Setelah berhasil,
+// semua session lain dicabut sehingga hanya sessionID yang tetap aktif.
+func (s *userService) ChangePassword(userID uint, sessionID, oldPassword, newPassword string) error {
 	user, err := s.userRepo.FindByID(userID)
 	if err != nil {
 		s.logger.Errorf("Failed to find user %d for password change: %v", userID, err)
@@ -138,25 +309,96 @@ func (s *userService) ChangePassword(userID uint, oldPassword, newPassword strin
 
 	// Verify old password
 	if !utils.CheckPassword(oldPassword, user.Password) {
-		return errors.New("incorrect old password")
+		return ErrIncorrectPassword
+	}
+
+	if err := s.passwordPolicy.Validate(user.ID, newPassword); err != nil {
+		return err
+	}
+	if utils.CheckPassword(newPassword, user.Password) {
+		return &PasswordPolicyError{Violations: []string{"must differ from the current password"}}
+	}
+
+	if err := s.passwordPolicy.SetPassword(user, newPassword); err != nil {
+		return err
+	}
+
+	if err := s.authService.InvalidateOtherSessions(user.ID, sessionID); err != nil {
+		s.logger.Errorf("Failed to revoke other sessions after password change of user %d: %v", user.ID, err)
+		return errors.New("password was changed but other sessions could not be revoked")
+	}
+
+	s.logger.WithField("user_id", user.ID).Info("Password changed, other sessions revoked")
+	return nil
+}
+
+// ChangeRole mengganti role user lain sesuai hierarki role (lihat
+// RoleService.CanGrant): role lama dan role baru sama-sama harus boleh
+// diberikan oleh actor. Semua session user dicabut agar claim role yang baru
+// langsung berlaku.
+func (s *userService) ChangeRole(actorID uint, actorRole entities.Role, userID uint, role entities.Role) (*entities.Users, error) {
+	if actorID == userID {
+		return nil, ErrCannotChangeOwnRole
 	}
 
-	// Hash new password
-	hashedPassword, err := utils.HashPassword(newPassword)
+	user, err := s.userRepo.FindByID(userID)
 	if err != nil {
-		s.logger.Errorf("Failed to hash new password: %v", err)
-		return errors.New("failed to process password")
+		s.logger.Errorf("Failed to find user %d for role change: %v", userID, err)
+		return nil, errors.New("failed to find user")
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
 	}
 
-	user.Password = hashedPassword
-	return s.userRepo.Update(user)
+	if err := s.checkCanGrant(actorRole, user.Role); err != nil {
+		if err == ErrRoleNotAllowed {
+			return nil, ErrRoleChangeNotAllowed
+		}
+		return nil, err
+	}
+	if err := s.checkCanGrant(actorRole, role); err != nil {
+		return nil, err
+	}
+
+	if user.Role == role {
+		return user, nil
+	}
+
+	updated, err := s.userRepo.UpdateRole(user.ID, role)
+	if err != nil {
+		s.logger.Errorf("Failed to change role of user %d: %v", user.ID, err)
+		return nil, errors.New("failed to change role")
+	}
+	if !updated {
+		return nil, ErrLastSuperAdmin
+	}
+
+	previousRole := user.Role
+	user.Role = role
+
+	if err := s.authService.RevokeAllSessions(user.ID); err != nil {
+		s.logger.Errorf("Failed to revoke sessions after role change of user %d: %v", user.ID, err)
+		return nil, errors.New("role was changed but sessions could not be revoked")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"user_id":       user.ID,
+		"previous_role": previousRole,
+		"role":          role,
+		"changed_by":    actorID,
+	}).Info("User role changed, sessions revoked")
+
+	return user, nil
 }
 
-func (s *userService) ListMenus(roles string) ([]entities.Menus, error) {
-	menus, err := s.userRepo.FindAllMenus(roles)
+func (s *userService) checkCanGrant(actorRole, role entities.Role) error {
+	allowed, err := s.roleService.CanGrant(context.Background(), actorRole, role)
 	if err != nil {
-		s.logger.Errorf("Failed to list menus: %v", err)
-		return nil, errors.New("failed to list menus")
+		s.logger.Errorf("Failed to check role hierarchy: %v", err)
+		return errors.New("failed to check role")
+	}
+	if !allowed {
+		return ErrRoleNotAllowed
 	}
-	return menus, nil
+	return nil
 }
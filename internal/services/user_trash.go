@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/entities"
+	"github.com/anieswahdie1/ara-medika-api.git/internal/models/requests"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// userPurgeBatchSize membatasi jumlah user yang dihapus permanen dalam satu
+// query agar purge tidak mengunci tabel terlalu lama
+const userPurgeBatchSize = 200
+
+var (
+	ErrDeletedUserNotFound = errors.New("deleted user not found")
+	ErrEmailTaken          = errors.New("email is already used by another user")
+)
+
+// DeletedUserListResult adalah satu halaman trash. PurgeAt berisi waktu purge
+// otomatis per user id, kosong jika purge otomatis dimatikan.
+type DeletedUserListResult struct {
+	Users   []entities.Users
+	PurgeAt map[uint]time.Time
+	Total   int64
+	Page    int
+	Limit   int
+}
+
+func (s *userService) ListDeletedUsers(request requests.BaseGetListRequest) (*DeletedUserListResult, error) {
+	if request.Page < 1 {
+		request.Page = 1
+	}
+	if request.Limit < 1 {
+		request.Limit = defaultUserPageSize
+	}
+	if request.Limit > maxUserPageSize {
+		request.Limit = maxUserPageSize
+	}
+
+	offset := (request.Page - 1) * request.Limit
+	users, total, err := s.userRepo.FindDeleted(strings.TrimSpace(request.Search), offset, request.Limit)
+	if err != nil {
+		s.logger.Errorf("Failed to find deleted users: %v", err)
+		return nil, errors.New("failed to list deleted users")
+	}
+
+	result := &DeletedUserListResult{
+		Users:   users,
+		PurgeAt: make(map[uint]time.Time, len(users)),
+		Total:   total,
+		Page:    request.Page,
+		Limit:   request.Limit,
+	}
+	if s.cfg.UserPurgeRetention > 0 {
+		for _, user := range users {
+			result.PurgeAt[user.ID] = user.DeletedAt.Time.Add(s.cfg.UserPurgeRetention)
+		}
+	}
+	return result, nil
+}
+
+func (s *userService) GetDeletedUser(id uint) (*entities.Users, error) {
+	user, err := s.userRepo.FindDeletedByID(id)
+	if err != nil {
+		s.logger.Errorf("Failed to find deleted user %d: %v", id, err)
+		return nil, errors.New("failed to find deleted user")
+	}
+	if user == nil {
+		return nil, ErrDeletedUserNotFound
+	}
+	return user, nil
+}
+
+// RestoreUser mengeluarkan user dari trash dengan status aktif seperti sebelum
+// dihapus. Restore ditolak jika emailnya sudah dipakai user lain.
+func (s *userService) RestoreUser(actorID, id uint) (*entities.Users, error) {
+	user, err := s.GetDeletedUser(id)
+	if err != nil {
+		return nil, err
+	}
+
+	other, err := s.userRepo.FindByEmail(user.Email)
+	if err != nil {
+		s.logger.Errorf("Failed to check email of deleted user %d: %v", id, err)
+		return nil, errors.New("failed to restore user")
+	}
+	if other != nil {
+		return nil, ErrEmailTaken
+	}
+
+	restored, err := s.userRepo.Restore(id)
+	if err != nil {
+		// Email dipakai user lain di antara pengecekan dan restore
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrEmailTaken
+		}
+		s.logger.Errorf("Failed to restore user %d: %v", id, err)
+		return nil, errors.New("failed to restore user")
+	}
+	if !restored {
+		return nil, ErrDeletedUserNotFound
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"user_id":     id,
+		"restored_by": actorID,
+	}).Info("User restored from trash")
+
+	return s.userRepo.FindByID(id)
+}
+
+// PurgeUser menghapus permanen user yang ada di trash tanpa menunggu masa
+// retensi
+func (s *userService) PurgeUser(actorID, id uint) error {
+	purged, err := s.userRepo.Purge(id)
+	if err != nil {
+		s.logger.Errorf("Failed to purge user %d: %v", id, err)
+		return errors.New("failed to purge user")
+	}
+	if !purged {
+		return ErrDeletedUserNotFound
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"user_id":   id,
+		"purged_by": actorID,
+	}).Info("User purged")
+	return nil
+}
+
+// PurgeExpiredUsers menghapus permanen user yang sudah berada di trash lebih
+// lama dari USER_PURGE_RETENTION dan mengembalikan user yang terhapus (hanya
+// ID dan Email yang terisi). Pembatalan ctx diperiksa di antara batch; user
+// yang sudah terhapus tetap dikembalikan bersama error-nya.
+func (s *userService) PurgeExpiredUsers(ctx context.Context) ([]entities.Users, error) {
+	if s.cfg.UserPurgeRetention <= 0 {
+		return nil, nil
+	}
+
+	before := time.Now().Add(-s.cfg.UserPurgeRetention)
+	var purged []entities.Users
+	for {
+		if err := ctx.Err(); err != nil {
+			return purged, err
+		}
+		batch, err := s.userRepo.PurgeDeletedBefore(before, userPurgeBatchSize)
+		if err != nil {
+			return purged, err
+		}
+		purged = append(purged, batch...)
+		if len(batch) < userPurgeBatchSize {
+			return purged, nil
+		}
+	}
+}
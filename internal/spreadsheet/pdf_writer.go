@@ -0,0 +1,330 @@
+package spreadsheet
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Halaman A4 landscape dalam satuan point
+const (
+	pdfPageWidth    = 842.0
+	pdfPageHeight   = 595.0
+	pdfMargin       = 36.0
+	pdfFontSize     = 9.0
+	pdfTitleSize    = 14.0
+	pdfRowHeight    = 14.0
+	pdfCellPadding  = 3.0
+	pdfTitleHeight  = 34.0
+	pdfFooterHeight = 18.0
+)
+
+// Nomor objek tetap; halaman dan isinya memakai nomor berikutnya
+const (
+	pdfCatalogObject  = 1
+	pdfPagesObject    = 2
+	pdfFontObject     = 3
+	pdfBoldFontObject = 4
+	pdfInfoObject     = 5
+)
+
+// Lebar karakter ASCII 32-126 font Helvetica dan Helvetica-Bold (per 1000
+// unit), dari metrik standar Adobe. Karakter lain dianggap selebar angka.
+var (
+	helveticaWidths = [95]int{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	}
+	helveticaBoldWidths = [95]int{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}
+)
+
+// Karakter di luar Latin-1 yang punya tempat di WinAnsiEncoding
+var winAnsiExtras = map[rune]byte{
+	'€': 0x80, '…': 0x85, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '–': 0x96, '—': 0x97,
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+type pdfWriter struct {
+	out        *countingWriter
+	opts       WriterOptions
+	offsets    map[int]int64
+	nextObject int
+	pages      []int
+	header     []string
+	columns    []float64
+	rows       [][]string
+}
+
+// NewPDFWriter menulis tabel sebagai PDF A4 landscape. Setiap halaman
+// ditulis begitu penuh sehingga hanya satu halaman yang ada di memori. Baris
+// header diulang di setiap halaman; teks yang terlalu panjang dipotong.
+// Font standar PDF hanya mendukung karakter Latin-1, karakter lain diganti
+// "?".
+func NewPDFWriter(w io.Writer, opts WriterOptions) (RowWriter, error) {
+	p := &pdfWriter{
+		out:        &countingWriter{w: w},
+		opts:       opts,
+		offsets:    make(map[int]int64),
+		nextObject: pdfInfoObject + 1,
+	}
+
+	if _, err := io.WriteString(p.out, "%PDF-1.4\n%\xe2\xe3\xcf\xd3\n"); err != nil {
+		return nil, err
+	}
+
+	objects := []struct {
+		number int
+		body   string
+	}{
+		{pdfCatalogObject, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pdfPagesObject)},
+		{pdfFontObject, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>"},
+		{pdfBoldFontObject, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>"},
+		{pdfInfoObject, fmt.Sprintf("<< /Title %s /Producer (Ara Medika API) /CreationDate (D:%s) >>",
+			pdfString(opts.Title), time.Now().UTC().Format("20060102150405Z"))},
+	}
+	for _, object := range objects {
+		if err := p.writeObject(object.number, object.body); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+func (p *pdfWriter) WriteRow(values []string) error {
+	if p.header == nil {
+		p.header = append([]string{}, values...)
+		p.columns = p.columnWidths(len(values))
+		return nil
+	}
+
+	p.rows = append(p.rows, append([]string{}, values...))
+	if len(p.rows) >= p.rowsPerPage() {
+		return p.flushPage()
+	}
+	return nil
+}
+
+func (p *pdfWriter) Close() error {
+	if len(p.rows) > 0 || len(p.pages) == 0 {
+		if err := p.flushPage(); err != nil {
+			return err
+		}
+	}
+
+	kids := make([]string, len(p.pages))
+	for i, page := range p.pages {
+		kids[i] = strconv.Itoa(page) + " 0 R"
+	}
+	pagesBody := fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(p.pages))
+	if err := p.writeObject(pdfPagesObject, pagesBody); err != nil {
+		return err
+	}
+
+	xrefOffset := p.out.n
+	var b strings.Builder
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", p.nextObject)
+	for number := 1; number < p.nextObject; number++ {
+		fmt.Fprintf(&b, "%010d 00000 n \n", p.offsets[number])
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		p.nextObject, pdfCatalogObject, pdfInfoObject, xrefOffset)
+
+	_, err := io.WriteString(p.out, b.String())
+	return err
+}
+
+// rowsPerPage menghitung jumlah baris data yang muat di halaman berikutnya.
+// Halaman pertama menyisakan tempat untuk judul.
+func (p *pdfWriter) rowsPerPage() int {
+	available := pdfPageHeight - 2*pdfMargin - pdfFooterHeight - pdfRowHeight
+	if len(p.pages) == 0 && p.opts.Title != "" {
+		available -= pdfTitleHeight
+	}
+	return int(available / pdfRowHeight)
+}
+
+func (p *pdfWriter) columnWidths(count int) []float64 {
+	weights := p.opts.ColumnWidths
+	if len(weights) != count {
+		weights = make([]float64, count)
+		for i := range weights {
+			weights[i] = 1
+		}
+	}
+
+	total := 0.0
+	for _, weight := range weights {
+		total += weight
+	}
+	widths := make([]float64, count)
+	for i, weight := range weights {
+		widths[i] = (pdfPageWidth - 2*pdfMargin) * weight / total
+	}
+	return widths
+}
+
+func (p *pdfWriter) flushPage() error {
+	var content bytes.Buffer
+	y := pdfPageHeight - pdfMargin
+
+	if len(p.pages) == 0 && p.opts.Title != "" {
+		y -= pdfTitleSize
+		writeText(&content, "F2", pdfTitleSize, pdfMargin, y, p.opts.Title)
+		if p.opts.Subtitle != "" {
+			writeText(&content, "F1", pdfFontSize, pdfMargin, y-pdfRowHeight, p.opts.Subtitle)
+		}
+		y = pdfPageHeight - pdfMargin - pdfTitleHeight
+	}
+
+	if p.header != nil {
+		y -= pdfRowHeight
+		p.writeCells(&content, "F2", helveticaBoldWidths, y, p.header)
+		fmt.Fprintf(&content, "0.5 w %.2f %.2f m %.2f %.2f l S\n",
+			pdfMargin, y-4, pdfPageWidth-pdfMargin, y-4)
+
+		for _, row := range p.rows {
+			y -= pdfRowHeight
+			p.writeCells(&content, "F1", helveticaWidths, y, row)
+		}
+	}
+
+	pageNumber := "Page " + strconv.Itoa(len(p.pages)+1)
+	writeText(&content, "F1", pdfFontSize,
+		pdfPageWidth-pdfMargin-textWidth(pageNumber, helveticaWidths, pdfFontSize), pdfMargin, pageNumber)
+
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write(content.Bytes()); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	contentObject := p.allocateObject()
+	streamBody := fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", compressed.Len(), compressed.Bytes())
+	if err := p.writeObject(contentObject, streamBody); err != nil {
+		return err
+	}
+
+	pageObject := p.allocateObject()
+	pageBody := fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %g %g] /Resources << /Font << /F1 %d 0 R /F2 %d 0 R >> >> /Contents %d 0 R >>",
+		pdfPagesObject, pdfPageWidth, pdfPageHeight, pdfFontObject, pdfBoldFontObject, contentObject)
+	if err := p.writeObject(pageObject, pageBody); err != nil {
+		return err
+	}
+
+	p.pages = append(p.pages, pageObject)
+	p.rows = p.rows[:0]
+	return nil
+}
+
+func (p *pdfWriter) writeCells(content *bytes.Buffer, font string, widths [95]int, y float64, values []string) {
+	x := pdfMargin
+	for i, width := range p.columns {
+		if i < len(values) {
+			text := fitText(values[i], widths, pdfFontSize, width-2*pdfCellPadding)
+			writeText(content, font, pdfFontSize, x+pdfCellPadding, y, text)
+		}
+		x += width
+	}
+}
+
+func (p *pdfWriter) allocateObject() int {
+	number := p.nextObject
+	p.nextObject++
+	return number
+}
+
+func (p *pdfWriter) writeObject(number int, body string) error {
+	p.offsets[number] = p.out.n
+	_, err := fmt.Fprintf(p.out, "%d 0 obj\n%s\nendobj\n", number, body)
+	return err
+}
+
+func writeText(content *bytes.Buffer, font string, size, x, y float64, text string) {
+	if text == "" {
+		return
+	}
+	fmt.Fprintf(content, "BT /%s %g Tf %.2f %.2f Td %s Tj ET\n", font, size, x, y, pdfString(text))
+}
+
+// fitText memotong teks dan menambahkan "..." jika lebih lebar dari maxWidth
+func fitText(text string, widths [95]int, size, maxWidth float64) string {
+	if textWidth(text, widths, size) <= maxWidth {
+		return text
+	}
+
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		candidate := strings.TrimRight(string(runes), " ") + "..."
+		if textWidth(candidate, widths, size) <= maxWidth {
+			return candidate
+		}
+	}
+	return ""
+}
+
+func textWidth(text string, widths [95]int, size float64) float64 {
+	total := 0
+	for _, r := range text {
+		if r >= 32 && r <= 126 {
+			total += widths[r-32]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// pdfString mengubah teks menjadi string literal PDF berenkoding WinAnsi
+func pdfString(text string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, r := range text {
+		var c byte
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			c = byte(r)
+		case r >= 32 && r <= 126, r >= 0xA0 && r <= 0xFF:
+			c = byte(r)
+		default:
+			if extra, ok := winAnsiExtras[r]; ok {
+				c = extra
+			} else {
+				c = '?'
+			}
+		}
+		b.WriteByte(c)
+	}
+	b.WriteByte(')')
+	return b.String()
+}
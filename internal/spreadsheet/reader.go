@@ -0,0 +1,403 @@
+// Package spreadsheet membaca dan menulis tabel sederhana dalam format CSV dan
+// XLSX, serta menulis laporan tabel PDF, tanpa dependensi eksternal. Hanya
+// nilai sel yang didukung; format, rumus dan gambar diabaikan.
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported file format, use .csv or .xlsx")
+	// ErrTooManyRows dikembalikan jika file berisi lebih dari maxRows baris
+	// data (baris berisi setidaknya satu nilai, tidak termasuk header)
+	ErrTooManyRows = errors.New("file has too many rows")
+
+	errInvalidXLSX = errors.New("invalid XLSX")
+)
+
+const (
+	// maxXLSXPartSize membatasi ukuran XML yang didekompresi agar file zip
+	// kecil tidak bisa menghabiskan memori
+	maxXLSXPartSize = 64 << 20
+
+	// maxXLSXColumns membatasi indeks kolom. Tabel yang dibaca paket ini
+	// hanya punya beberapa kolom, dan referensi seperti "XFD1" tidak boleh
+	// membuat ribuan sel kosong.
+	maxXLSXColumns = 50
+
+	// maxXLSXRows adalah jumlah baris maksimal worksheet Excel, termasuk
+	// baris kosong
+	maxXLSXRows = 1 << 20
+)
+
+// Read membaca baris dari file CSV atau XLSX sesuai ekstensi nama file. Untuk
+// XLSX hanya sheet pertama yang dibaca. Baris pertama dianggap header; jika
+// maxRows lebih dari 0, pembacaan berhenti dengan ErrTooManyRows begitu
+// ditemukan lebih dari maxRows baris data.
+func Read(filename string, data []byte, maxRows int) ([][]string, error) {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".csv":
+		return ReadCSV(bytes.NewReader(data), maxRows)
+	case ".xlsx":
+		return ReadXLSX(bytes.NewReader(data), int64(len(data)), maxRows)
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+// ReadCSV membaca CSV dengan pemisah koma atau titik koma (ekspor Excel
+// berbahasa Indonesia memakai titik koma)
+func ReadCSV(r io.Reader, maxRows int) ([][]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	limit := rowLimit{max: maxRows}
+	var rows [][]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		if err := limit.add(len(rows), record); err != nil {
+			return nil, err
+		}
+		rows = append(rows, record)
+	}
+	return rows, nil
+}
+
+// rowLimit menghitung baris data (selain header) yang berisi nilai
+type rowLimit struct {
+	max   int
+	count int
+}
+
+func (l *rowLimit) add(index int, values []string) error {
+	if index == 0 || l.max <= 0 || isBlank(values) {
+		return nil
+	}
+	l.count++
+	if l.count > l.max {
+		return fmt.Errorf("%w: maximum is %d", ErrTooManyRows, l.max)
+	}
+	return nil
+}
+
+func isBlank(values []string) bool {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxRichText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxRichText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var b strings.Builder
+	for _, run := range t.Runs {
+		b.WriteString(run.Text)
+	}
+	return b.String()
+}
+
+type xlsxCell struct {
+	Value  string        `xml:"v"`
+	Inline *xlsxRichText `xml:"is"`
+}
+
+// ReadXLSX membaca sheet pertama workbook XLSX. Sheet dibaca elemen demi
+// elemen sehingga memori yang dipakai sebanding dengan jumlah baris yang
+// diterima, bukan dengan ukuran XML.
+func ReadXLSX(r io.ReaderAt, size int64, maxRows int) ([][]string, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidXLSX, err)
+	}
+
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	var sharedStrings []string
+	if file, ok := files["xl/sharedStrings.xml"]; ok {
+		if sharedStrings, err = readSharedStrings(file); err != nil {
+			return nil, err
+		}
+	}
+
+	sheetFile, ok := files[firstSheetPath(files)]
+	if !ok {
+		return nil, fmt.Errorf("%w: workbook has no worksheet", errInvalidXLSX)
+	}
+	return readSheet(sheetFile, sharedStrings, maxRows)
+}
+
+func readSharedStrings(file *zip.File) ([]string, error) {
+	var items []string
+	err := walkXMLFile(file, func(decoder *xml.Decoder, start xml.StartElement) error {
+		if start.Name.Local != "si" {
+			return nil
+		}
+		var item xlsxRichText
+		if err := decoder.DecodeElement(&item, &start); err != nil {
+			return err
+		}
+		items = append(items, item.String())
+		return nil
+	})
+	return items, err
+}
+
+func readSheet(file *zip.File, sharedStrings []string, maxRows int) ([][]string, error) {
+	var (
+		rows   [][]string
+		values []string
+		cells  int
+		limit  = rowLimit{max: maxRows}
+	)
+	err := walkXMLFile(file, func(decoder *xml.Decoder, start xml.StartElement) error {
+		if start.Name.Local != "row" {
+			return nil
+		}
+		if len(rows) >= maxXLSXRows {
+			return fmt.Errorf("%w: maximum is %d", ErrTooManyRows, maxXLSXRows)
+		}
+
+		values, cells = nil, 0
+		err := walkRow(decoder, func(start xml.StartElement) error {
+			value, column, err := readCell(decoder, start, cells, sharedStrings)
+			if err != nil {
+				return err
+			}
+			cells++
+			for len(values) <= column {
+				values = append(values, "")
+			}
+			values[column] = value
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if err := limit.add(len(rows), values); err != nil {
+			return err
+		}
+		rows = append(rows, values)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// walkRow memanggil fn untuk setiap elemen <c> sampai akhir elemen <row>
+func walkRow(decoder *xml.Decoder, fn func(start xml.StartElement) error) error {
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "c" {
+				if err := fn(t); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := decoder.Skip(); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// readCell membaca satu elemen <c>. position adalah urutan sel di baris,
+// dipakai sebagai kolom jika sel tidak punya referensi.
+func readCell(decoder *xml.Decoder, start xml.StartElement, position int, sharedStrings []string) (string, int, error) {
+	var ref, cellType string
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "r":
+			ref = attr.Value
+		case "t":
+			cellType = attr.Value
+		}
+	}
+
+	column := position
+	if ref != "" {
+		var err error
+		if column, err = columnIndex(ref); err != nil {
+			return "", 0, err
+		}
+	}
+	if column >= maxXLSXColumns {
+		return "", 0, fmt.Errorf("%w: more than %d columns", errInvalidXLSX, maxXLSXColumns)
+	}
+
+	var cell xlsxCell
+	if err := decoder.DecodeElement(&cell, &start); err != nil {
+		return "", 0, err
+	}
+
+	switch cellType {
+	case "s":
+		index, err := strconv.Atoi(cell.Value)
+		if err != nil || index < 0 || index >= len(sharedStrings) {
+			return "", 0, fmt.Errorf("%w: bad shared string in cell %s", errInvalidXLSX, ref)
+		}
+		return sharedStrings[index], column, nil
+	case "inlineStr":
+		if cell.Inline != nil {
+			return cell.Inline.String(), column, nil
+		}
+		return "", column, nil
+	default:
+		return cell.Value, column, nil
+	}
+}
+
+// firstSheetPath mencari lokasi sheet pertama lewat workbook.xml dan
+// relasinya, dengan fallback ke lokasi standar
+func firstSheetPath(files map[string]*zip.File) string {
+	const fallback = "xl/worksheets/sheet1.xml"
+
+	workbookFile, ok := files["xl/workbook.xml"]
+	if !ok {
+		return fallback
+	}
+	relsFile, ok := files["xl/_rels/workbook.xml.rels"]
+	if !ok {
+		return fallback
+	}
+
+	var workbook xlsxWorkbook
+	var rels xlsxRelationships
+	if decodeXMLFile(workbookFile, &workbook) != nil || decodeXMLFile(relsFile, &rels) != nil || len(workbook.Sheets) == 0 {
+		return fallback
+	}
+
+	for _, rel := range rels.Relationships {
+		if rel.ID != workbook.Sheets[0].ID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/")
+		}
+		return path.Join("xl", rel.Target)
+	}
+	return fallback
+}
+
+func decodeXMLFile(file *zip.File, v any) error {
+	rc, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("%w: %v", errInvalidXLSX, err)
+	}
+	defer rc.Close()
+
+	if err := xml.NewDecoder(io.LimitReader(rc, maxXLSXPartSize)).Decode(v); err != nil {
+		return fmt.Errorf("%w: %s: %v", errInvalidXLSX, file.Name, err)
+	}
+	return nil
+}
+
+// walkXMLFile memanggil fn untuk setiap elemen pembuka di file. fn boleh
+// membaca isi elemen dari decoder.
+func walkXMLFile(file *zip.File, fn func(decoder *xml.Decoder, start xml.StartElement) error) error {
+	rc, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("%w: %v", errInvalidXLSX, err)
+	}
+	defer rc.Close()
+
+	decoder := xml.NewDecoder(io.LimitReader(rc, maxXLSXPartSize))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %s: %v", errInvalidXLSX, file.Name, err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if err := fn(decoder, start); err != nil {
+			if errors.Is(err, ErrTooManyRows) || errors.Is(err, errInvalidXLSX) {
+				return err
+			}
+			return fmt.Errorf("%w: %s: %v", errInvalidXLSX, file.Name, err)
+		}
+	}
+}
+
+// columnIndex mengubah referensi sel seperti "C12" menjadi indeks kolom (2).
+// Kolom Excel paling banyak tiga huruf (XFD).
+func columnIndex(ref string) (int, error) {
+	index := 0
+	for i, char := range ref {
+		if char >= 'A' && char <= 'Z' && i < 3 {
+			index = index*26 + int(char-'A'+1)
+			continue
+		}
+		if i == 0 || char < '0' || char > '9' {
+			break
+		}
+		return index - 1, nil
+	}
+	return 0, fmt.Errorf("%w: bad cell reference %q", errInvalidXLSX, ref)
+}
@@ -0,0 +1,218 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		maxRows int
+		want    [][]string
+		wantErr error
+	}{
+		{
+			name:  "comma separated",
+			input: "name,email\nBudi,budi@example.com\n",
+			want:  [][]string{{"name", "email"}, {"Budi", "budi@example.com"}},
+		},
+		{
+			name:  "semicolon separated with BOM",
+			input: "\xef\xbb\xbfname;email\nBudi;budi@example.com\n",
+			want:  [][]string{{"name", "email"}, {"Budi", "budi@example.com"}},
+		},
+		{
+			name:    "blank rows do not count towards the limit",
+			input:   "name\nA\n,\nB\n",
+			maxRows: 2,
+			want:    [][]string{{"name"}, {"A"}, {"", ""}, {"B"}},
+		},
+		{
+			name:    "too many rows",
+			input:   "name\nA\nB\nC\n",
+			maxRows: 2,
+			wantErr: ErrTooManyRows,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadCSV(strings.NewReader(tt.input), tt.maxRows)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rows = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadXLSXRoundTrip(t *testing.T) {
+	want := [][]string{
+		{"name", "email", "role"},
+		{"Budi", "budi@example.com", "admin"},
+		{"Siti <RN>", "siti@example.com", "user"},
+	}
+
+	var buf bytes.Buffer
+	writer, err := NewXLSXWriter(&buf, "Users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range want {
+		if err := writer.WriteRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadXLSX(bytes.NewReader(buf.Bytes()), int64(buf.Len()), 10)
+	if err != nil {
+		t.Fatalf("ReadXLSX: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rows = %q, want %q", got, want)
+	}
+}
+
+func TestReadXLSX(t *testing.T) {
+	const sharedStrings = `<sst><si><t>name</t></si><si><r><t>Bu</t></r><r><t>di</t></r></si></sst>`
+
+	tests := []struct {
+		name    string
+		sheet   string
+		maxRows int
+		want    [][]string
+		wantErr error
+	}{
+		{
+			name: "shared, rich, inline and plain values",
+			sheet: `<row><c r="A1" t="s"><v>0</v></c></row>` +
+				`<row><c r="A2" t="s"><v>1</v></c><c r="C2" t="inlineStr"><is><t>x</t></is></c><c r="D2"><v>42</v></c></row>`,
+			want: [][]string{{"name"}, {"Budi", "", "x", "42"}},
+		},
+		{
+			name:  "cells without reference use their position",
+			sheet: `<row><c><v>a</v></c><c><v>b</v></c></row>`,
+			want:  [][]string{{"a", "b"}},
+		},
+		{
+			name:    "column beyond the limit",
+			sheet:   `<row><c r="XFD1"><v>a</v></c></row>`,
+			wantErr: errInvalidXLSX,
+		},
+		{
+			name:    "too many cells without reference",
+			sheet:   `<row>` + strings.Repeat(`<c><v>a</v></c>`, maxXLSXColumns+1) + `</row>`,
+			wantErr: errInvalidXLSX,
+		},
+		{
+			name:    "malformed reference",
+			sheet:   `<row><c r="AAAA1"><v>a</v></c></row>`,
+			wantErr: errInvalidXLSX,
+		},
+		{
+			name:    "bad shared string index",
+			sheet:   `<row><c r="A1" t="s"><v>7</v></c></row>`,
+			wantErr: errInvalidXLSX,
+		},
+		{
+			name:    "too many rows",
+			sheet:   `<row><c><v>h</v></c></row>` + strings.Repeat(`<row><c><v>a</v></c></row>`, 4),
+			maxRows: 3,
+			wantErr: ErrTooManyRows,
+		},
+		{
+			name:    "empty rows do not count towards the limit",
+			sheet:   `<row><c><v>h</v></c></row>` + strings.Repeat(`<row/>`, 10) + `<row><c><v>a</v></c></row>`,
+			maxRows: 1,
+			want:    append(append([][]string{{"h"}}, make([][]string, 10)...), []string{"a"}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := buildXLSX(t, map[string]string{
+				"xl/sharedStrings.xml":     sharedStrings,
+				"xl/worksheets/sheet1.xml": `<worksheet><sheetData>` + tt.sheet + `</sheetData></worksheet>`,
+			})
+
+			got, err := ReadXLSX(bytes.NewReader(data), int64(len(data)), tt.maxRows)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rows = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColumnIndex(t *testing.T) {
+	tests := []struct {
+		ref     string
+		want    int
+		wantErr bool
+	}{
+		{ref: "A1", want: 0},
+		{ref: "C12", want: 2},
+		{ref: "AA3", want: 26},
+		{ref: "XFD1048576", want: 16383},
+		{ref: "1", wantErr: true},
+		{ref: "A", wantErr: true},
+		{ref: "AAAA1", wantErr: true},
+		{ref: "a1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := columnIndex(tt.ref)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("columnIndex(%q) err = %v, wantErr %v", tt.ref, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("columnIndex(%q) = %d, want %d", tt.ref, got, tt.want)
+		}
+	}
+}
+
+func buildXLSX(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
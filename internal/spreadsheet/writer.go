@@ -0,0 +1,124 @@
+package spreadsheet
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+)
+
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+	FormatPDF  Format = "pdf"
+)
+
+var ErrUnsupportedExportFormat = errors.New("unsupported export format, use csv, xlsx or pdf")
+
+// ParseFormat menerima nama format tanpa membedakan huruf besar kecil
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(strings.ToLower(strings.TrimSpace(name))); format {
+	case FormatCSV, FormatXLSX, FormatPDF:
+		return format, nil
+	}
+	return "", ErrUnsupportedExportFormat
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case FormatPDF:
+		return "application/pdf"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// RowWriter menulis tabel baris demi baris langsung ke io.Writer sehingga
+// data besar tidak perlu dimuat ke memori. Baris pertama adalah header.
+// Close wajib dipanggil untuk menyelesaikan file.
+type RowWriter interface {
+	WriteRow(values []string) error
+	Close() error
+}
+
+// WriterOptions dipakai oleh format yang mendukungnya. Title menjadi judul
+// PDF dan nama sheet XLSX; Subtitle dicetak di bawah judul PDF.
+// ColumnWidths adalah bobot lebar kolom PDF (default sama rata).
+type WriterOptions struct {
+	Title        string
+	Subtitle     string
+	ColumnWidths []float64
+}
+
+func NewWriter(format Format, w io.Writer, opts WriterOptions) (RowWriter, error) {
+	switch format {
+	case FormatCSV:
+		return NewCSVWriter(w)
+	case FormatXLSX:
+		return NewXLSXWriter(w, opts.Title)
+	case FormatPDF:
+		return NewPDFWriter(w, opts)
+	}
+	return nil, ErrUnsupportedExportFormat
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+	rows   int
+}
+
+// NewCSVWriter menulis CSV dengan BOM UTF-8 agar Excel membaca karakter
+// non-ASCII dengan benar
+func NewCSVWriter(w io.Writer) (RowWriter, error) {
+	if _, err := io.WriteString(w, "\xef\xbb\xbf"); err != nil {
+		return nil, err
+	}
+	return &csvWriter{writer: csv.NewWriter(w)}, nil
+}
+
+func (c *csvWriter) WriteRow(values []string) error {
+	safe := make([]string, len(values))
+	for i, value := range values {
+		safe[i] = escapeFormula(value)
+	}
+	if err := c.writer.Write(safe); err != nil {
+		return err
+	}
+
+	c.rows++
+	if c.rows%500 == 0 {
+		c.writer.Flush()
+	}
+	return c.writer.Error()
+}
+
+func (c *csvWriter) Close() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+// WriteCSV menulis seluruh baris sebagai CSV
+func WriteCSV(w io.Writer, rows [][]string) error {
+	writer, err := NewCSVWriter(w)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := writer.WriteRow(row); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// escapeFormula mencegah nilai seperti "=HYPERLINK(...)" dieksekusi sebagai
+// rumus saat CSV dibuka di aplikasi spreadsheet
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
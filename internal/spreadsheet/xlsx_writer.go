@@ -0,0 +1,161 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`</Types>`
+
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`</Relationships>`
+
+	// Style 1 (huruf tebal) dipakai untuk baris header
+	xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+		`</styleSheet>`
+
+	xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>` +
+		`<sheetData>`
+
+	xlsxSheetEnd = `</sheetData></worksheet>`
+)
+
+type xlsxWriter struct {
+	archive *zip.Writer
+	sheet   *bufio.Writer
+	rows    int
+}
+
+// NewXLSXWriter menulis workbook dengan satu sheet. Semua nilai ditulis
+// sebagai teks (inline string) sehingga tidak perlu shared strings yang
+// harus dikumpulkan di memori.
+func NewXLSXWriter(w io.Writer, sheetName string) (RowWriter, error) {
+	archive := zip.NewWriter(w)
+
+	workbook := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="` + escapeXML(xlsxSheetName(sheetName)) + `" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", workbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, part := range parts {
+		file, err := archive.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(file, part.body); err != nil {
+			return nil, err
+		}
+	}
+
+	// Sheet ditulis terakhir dan dibiarkan terbuka sampai Close
+	file, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	sheet := bufio.NewWriter(file)
+	if _, err := sheet.WriteString(xlsxSheetStart); err != nil {
+		return nil, err
+	}
+
+	return &xlsxWriter{archive: archive, sheet: sheet}, nil
+}
+
+func (x *xlsxWriter) WriteRow(values []string) error {
+	x.rows++
+	rowNumber := strconv.Itoa(x.rows)
+
+	style := ""
+	if x.rows == 1 {
+		style = ` s="1"`
+	}
+
+	var b strings.Builder
+	b.WriteString(`<row r="` + rowNumber + `">`)
+	for i, value := range values {
+		b.WriteString(`<c r="` + columnName(i) + rowNumber + `" t="inlineStr"` + style + `><is><t xml:space="preserve">`)
+		b.WriteString(escapeXML(value))
+		b.WriteString(`</t></is></c>`)
+	}
+	b.WriteString(`</row>`)
+
+	_, err := x.sheet.WriteString(b.String())
+	return err
+}
+
+func (x *xlsxWriter) Close() error {
+	if _, err := x.sheet.WriteString(xlsxSheetEnd); err != nil {
+		return err
+	}
+	if err := x.sheet.Flush(); err != nil {
+		return err
+	}
+	return x.archive.Close()
+}
+
+// columnName mengubah indeks kolom (0) menjadi nama kolom Excel (A)
+func columnName(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
+
+// xlsxSheetName membuang karakter yang tidak boleh ada di nama sheet dan
+// memotongnya menjadi 31 karakter
+func xlsxSheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return -1
+		}
+		return r
+	}, name)
+	if runes := []rune(strings.TrimSpace(name)); len(runes) > 31 {
+		name = string(runes[:31])
+	}
+	if strings.TrimSpace(name) == "" {
+		return "Sheet1"
+	}
+	return name
+}
+
+func escapeXML(value string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(value))
+	return b.String()
+}
@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+)
+
+// EncryptString mengenkripsi plaintext dengan AES-256-GCM. Key diturunkan dari
+// passphrase menggunakan SHA-256, hasilnya di-encode base64 (nonce + ciphertext).
+func EncryptString(passphrase, plaintext string) (string, error) {
+	gcm, err := newGCM(passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptString membalik EncryptString
+func DecryptString(passphrase, encoded string) (string, error) {
+	gcm, err := newGCM(passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("ciphertext too short")
+	}
+
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// HashToken mengembalikan sha256 hex dari token acak (recovery code, challenge
+// token, dll) untuk disimpan at rest
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func newGCM(passphrase string) (cipher.AEAD, error) {
+	if passphrase == "" {
+		return nil, errors.New("encryption key is not configured")
+	}
+
+	key := sha256.Sum256([]byte(passphrase))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// GenerateRandomToken menghasilkan token acak base64url tanpa padding
+func GenerateRandomToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -16,6 +16,8 @@ func ConnectDB(cfg *configs.Config) (*gorm.DB, error) {
 
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info), // aktifkan log
+		// Pelanggaran constraint unik dikembalikan sebagai gorm.ErrDuplicatedKey
+		TranslateError: true,
 	})
 
 	if err != nil {
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"strconv"
 	"time"
@@ -9,71 +11,220 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+const (
+	TokenTypeAccess     = "access"
+	TokenTypeRefresh    = "refresh"
+	TokenTypeInvitation = "invite"
+)
+
 type JWTClaims struct {
-	UserID uint   `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
+	UserID    uint   `json:"user_id"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	FamilyID  string `json:"fid,omitempty"`
+	TokenType string `json:"typ"`
+	Actor     *Actor `json:"act,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Actor adalah pihak yang sebenarnya bertindak ketika token diterbitkan untuk
+// impersonasi (claim "act", RFC 8693)
+type Actor struct {
+	Subject string `json:"sub"`
+	Email   string `json:"email"`
+}
+
+// ActorID mengembalikan ID user dari subject actor
+func (a *Actor) ActorID() uint {
+	id, _ := strconv.ParseUint(a.Subject, 10, 32)
+	return uint(id)
+}
+
+// RefreshClaims adalah claims untuk refresh token. ID (jti) bersifat unik per
+// token, sedangkan FamilyID sama untuk semua refresh token hasil rotasi dari
+// satu login.
+type RefreshClaims struct {
+	FamilyID  string `json:"fid"`
+	TokenType string `json:"typ"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(cfg *configs.Config, userID uint, email, role string) (string, error) {
+// InvitationClaims adalah claims untuk tautan undangan. Subject berisi ID
+// undangan, sedangkan jti dicocokkan dengan hash yang tersimpan di database
+// sehingga tautan lama tidak berlaku setelah undangan dikirim ulang.
+type InvitationClaims struct {
+	TokenType string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+// NewTokenID menghasilkan ID acak untuk jti dan token family
+func NewTokenID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func GenerateToken(cfg *configs.Config, userID uint, email, role, familyID, tokenID string) (string, error) {
 	claims := JWTClaims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
+		UserID:    userID,
+		Email:     email,
+		Role:      role,
+		FamilyID:  familyID,
+		TokenType: TokenTypeAccess,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(cfg.JWTExpire)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(cfg.JWTSecret))
+	ring, err := currentKeyRing()
+	if err != nil {
+		return "", err
+	}
+	return ring.sign(claims)
 }
 
-func GenerateRefreshToken(cfg *configs.Config, userID uint) (string, error) {
-	claims := jwt.RegisteredClaims{
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(cfg.JWTRefreshExpire)),
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
-		Subject:   string(userID),
+// GenerateImpersonationToken membuat access token berumur pendek atas nama
+// userID dengan actor sebagai pihak yang melakukan impersonasi. Token ini
+// tidak memiliki refresh token.
+func GenerateImpersonationToken(userID uint, email, role, sessionID, tokenID string, actor Actor, expire time.Duration) (string, error) {
+	claims := JWTClaims{
+		UserID:    userID,
+		Email:     email,
+		Role:      role,
+		FamilyID:  sessionID,
+		TokenType: TokenTypeAccess,
+		Actor:     &actor,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expire)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(cfg.JWTSecret))
+	ring, err := currentKeyRing()
+	if err != nil {
+		return "", err
+	}
+	return ring.sign(claims)
 }
 
-func ValidateToken(cfg *configs.Config, tokenString string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(cfg.JWTSecret), nil
-	})
+func GenerateRefreshToken(cfg *configs.Config, userID uint, familyID, tokenID string) (string, error) {
+	claims := RefreshClaims{
+		FamilyID:  familyID,
+		TokenType: TokenTypeRefresh,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(cfg.JWTRefreshExpire)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Subject:   strconv.FormatUint(uint64(userID), 10),
+		},
+	}
+
+	ring, err := currentKeyRing()
+	if err != nil {
+		return "", err
+	}
+	return ring.sign(claims)
+}
+
+func ValidateToken(tokenString string) (*JWTClaims, error) {
+	ring, err := currentKeyRing()
+	if err != nil {
+		return nil, err
+	}
 
+	token, err := ring.parse(tokenString, &JWTClaims{})
 	if err != nil {
 		return nil, err
 	}
 
 	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
+		if claims.TokenType != TokenTypeAccess {
+			return nil, errors.New("not an access token")
+		}
 		return claims, nil
 	}
 
 	return nil, errors.New("invalid token")
 }
 
-func ParseRefreshToken(cfg *configs.Config, refreshToken string) (uint, error) {
-	token, err := jwt.ParseWithClaims(refreshToken, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(cfg.JWTSecret), nil
-	})
+func ParseRefreshToken(refreshToken string) (*RefreshClaims, error) {
+	ring, err := currentKeyRing()
+	if err != nil {
+		return nil, err
+	}
 
+	token, err := ring.parse(refreshToken, &RefreshClaims{})
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*jwt.RegisteredClaims); ok && token.Valid {
-		userID, err := strconv.ParseUint(claims.Subject, 10, 32)
-		if err != nil {
-			return 0, errors.New("invalid user ID in token")
-		}
-		return uint(userID), nil
+	claims, ok := token.Claims.(*RefreshClaims)
+	if !ok || !token.Valid || claims.TokenType != TokenTypeRefresh {
+		return nil, errors.New("invalid token")
+	}
+
+	if claims.ID == "" || claims.FamilyID == "" {
+		return nil, errors.New("refresh token is missing jti or family")
+	}
+
+	if _, err := strconv.ParseUint(claims.Subject, 10, 32); err != nil {
+		return nil, errors.New("invalid user ID in token")
+	}
+
+	return claims, nil
+}
+
+// UserID mengembalikan ID user dari subject refresh token
+func (c *RefreshClaims) UserID() uint {
+	userID, _ := strconv.ParseUint(c.Subject, 10, 32)
+	return uint(userID)
+}
+
+func GenerateInvitationToken(invitationID uint, tokenID string, expiresAt time.Time) (string, error) {
+	claims := InvitationClaims{
+		TokenType: TokenTypeInvitation,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			Subject:   strconv.FormatUint(uint64(invitationID), 10),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	ring, err := currentKeyRing()
+	if err != nil {
+		return "", err
+	}
+	return ring.sign(claims)
+}
+
+// ParseInvitationToken memverifikasi tautan undangan dan mengembalikan ID
+// undangan beserta jti-nya
+func ParseInvitationToken(token string) (invitationID uint, tokenID string, err error) {
+	ring, err := currentKeyRing()
+	if err != nil {
+		return 0, "", err
+	}
+
+	parsed, err := ring.parse(token, &InvitationClaims{})
+	if err != nil {
+		return 0, "", err
+	}
+
+	claims, ok := parsed.Claims.(*InvitationClaims)
+	if !ok || !parsed.Valid || claims.TokenType != TokenTypeInvitation || claims.ID == "" {
+		return 0, "", errors.New("invalid token")
+	}
+
+	id, err := strconv.ParseUint(claims.Subject, 10, 32)
+	if err != nil {
+		return 0, "", errors.New("invalid invitation ID in token")
 	}
 
-	return 0, errors.New("invalid token")
+	return uint(id), claims.ID, nil
 }
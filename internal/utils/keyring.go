@@ -0,0 +1,277 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/configs"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// KeyRing menyimpan key untuk menandatangani dan memverifikasi JWT.
+//
+// Setiap file <kid>.pem di JWT_KEYS_DIR menjadi satu key dengan kid sesuai nama
+// filenya. File berisi private key bisa dipakai untuk sign, sedangkan file yang
+// hanya berisi public key hanya dipakai untuk verifikasi. Key yang dipakai untuk
+// sign dipilih lewat JWT_ACTIVE_KID.
+//
+// Prosedur rotasi tanpa membuat user logout:
+//  1. Buat key baru di JWT_KEYS_DIR (misalnya `go run ./cmd/keygen`) lalu
+//     restart. Key baru langsung muncul di JWKS tetapi belum dipakai sign,
+//     sehingga service lain sempat memperbarui cache JWKS-nya.
+//  2. Ubah JWT_ACTIVE_KID ke key baru lalu restart. Token lama tetap valid
+//     karena key sebelumnya masih ada di ring.
+//  3. Setelah JWT_REFRESH_EXPIRE berlalu, hapus file key lama.
+//
+// Jika JWT_KEYS_DIR kosong, token ditandatangani HS256 dengan JWT_SECRET
+// seperti sebelumnya.
+type KeyRing struct {
+	active *signingKey
+	keys   map[string]*signingKey
+	legacy []byte
+}
+
+type signingKey struct {
+	id         string
+	method     jwt.SigningMethod
+	privateKey crypto.Signer
+	publicKey  crypto.PublicKey
+}
+
+// JWK adalah representasi public key menurut RFC 7517
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+var (
+	keyRingMu sync.RWMutex
+	keyRing   *KeyRing
+)
+
+// InitKeyRing memuat key ring dari konfigurasi. Harus dipanggil sekali saat
+// aplikasi start sebelum token dibuat atau divalidasi.
+func InitKeyRing(cfg *configs.Config) error {
+	ring, err := LoadKeyRing(cfg)
+	if err != nil {
+		return err
+	}
+
+	keyRingMu.Lock()
+	keyRing = ring
+	keyRingMu.Unlock()
+	return nil
+}
+
+func currentKeyRing() (*KeyRing, error) {
+	keyRingMu.RLock()
+	defer keyRingMu.RUnlock()
+
+	if keyRing == nil {
+		return nil, errors.New("jwt key ring is not initialized")
+	}
+	return keyRing, nil
+}
+
+func LoadKeyRing(cfg *configs.Config) (*KeyRing, error) {
+	if cfg.JWTKeysDir == "" {
+		if cfg.JWTSecret == "" {
+			return nil, errors.New("either JWT_KEYS_DIR or JWT_SECRET must be set")
+		}
+		return &KeyRing{legacy: []byte(cfg.JWTSecret)}, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(cfg.JWTKeysDir, "*.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jwt keys: %w", err)
+	}
+
+	ring := &KeyRing{keys: make(map[string]*signingKey)}
+	for _, file := range files {
+		key, err := loadSigningKey(file)
+		if err != nil {
+			return nil, err
+		}
+		ring.keys[key.id] = key
+	}
+
+	active, ok := ring.keys[cfg.JWTActiveKID]
+	if !ok {
+		return nil, fmt.Errorf("active jwt key %q not found in %s", cfg.JWTActiveKID, cfg.JWTKeysDir)
+	}
+	if active.privateKey == nil {
+		return nil, fmt.Errorf("active jwt key %q has no private key", active.id)
+	}
+	ring.active = active
+
+	// Selama migrasi dari HS256, token lama tanpa kid masih diterima
+	if cfg.JWTAcceptLegacyHS256 && cfg.JWTSecret != "" {
+		ring.legacy = []byte(cfg.JWTSecret)
+	}
+
+	return ring, nil
+}
+
+func loadSigningKey(path string) (*signingKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read jwt key %s: %w", path, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("jwt key %s is not PEM encoded", path)
+	}
+
+	key := &signingKey{id: strings.TrimSuffix(filepath.Base(path), ".pem")}
+
+	var parsed any
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("jwt key %s has unsupported PEM type %q", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse jwt key %s: %w", path, err)
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.method, key.privateKey, key.publicKey = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.method, key.publicKey = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.method, key.privateKey, key.publicKey = jwt.SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.method, key.publicKey = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("jwt key %s must be an RSA or Ed25519 key", path)
+	}
+
+	if rsaKey, ok := key.publicKey.(*rsa.PublicKey); ok && rsaKey.N.BitLen() < 2048 {
+		return nil, fmt.Errorf("jwt key %s: RSA keys must be at least 2048 bits", path)
+	}
+
+	return key, nil
+}
+
+// sign menandatangani claims dengan key aktif dan mencantumkan kid di header
+func (r *KeyRing) sign(claims jwt.Claims) (string, error) {
+	if r.active == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(r.legacy)
+	}
+
+	token := jwt.NewWithClaims(r.active.method, claims)
+	token.Header["kid"] = r.active.id
+	return token.SignedString(r.active.privateKey)
+}
+
+// parse memverifikasi token terhadap key sesuai kid. Algoritma token harus
+// sama dengan algoritma key tersebut, sehingga token "alg: none" atau HS256
+// yang ditandatangani dengan public key ditolak.
+func (r *KeyRing) parse(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenString, claims, r.keyFunc, jwt.WithValidMethods(r.validMethods()))
+}
+
+func (r *KeyRing) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	if kid == "" {
+		if r.legacy == nil || token.Method.Alg() != jwt.SigningMethodHS256.Alg() {
+			return nil, errors.New("token is missing kid")
+		}
+		return r.legacy, nil
+	}
+
+	key, ok := r.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s for key %q", token.Method.Alg(), kid)
+	}
+	return key.publicKey, nil
+}
+
+func (r *KeyRing) validMethods() []string {
+	seen := make(map[string]bool)
+	methods := make([]string, 0, 2)
+
+	if r.legacy != nil {
+		seen[jwt.SigningMethodHS256.Alg()] = true
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	for _, key := range r.keys {
+		if alg := key.method.Alg(); !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+	return methods
+}
+
+// JWKS mengembalikan public key dari semua key di ring. Pada mode HS256 daftar
+// key kosong karena secret tidak boleh dipublikasikan.
+func (r *KeyRing) JWKS() JWKSet {
+	set := JWKSet{Keys: make([]JWK, 0, len(r.keys))}
+
+	for _, key := range r.keys {
+		jwk := JWK{
+			KeyID:     key.id,
+			Use:       "sig",
+			Algorithm: key.method.Alg(),
+		}
+
+		switch k := key.publicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(k.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(k)
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].KeyID < set.Keys[j].KeyID })
+	return set
+}
+
+// CurrentJWKS mengembalikan JWKS dari key ring yang sedang aktif
+func CurrentJWKS() (JWKSet, error) {
+	ring, err := currentKeyRing()
+	if err != nil {
+		return JWKSet{}, err
+	}
+	return ring.JWKS(), nil
+}
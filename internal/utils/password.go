@@ -1,20 +1,119 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/configs"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
-// HashPassword mengubah password plaintext menjadi hash menggunakan bcrypt
-func HashPassword(password string) (string, error) {
-	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return "", err
+const (
+	PasswordHashArgon2id = "argon2id"
+	PasswordHashBcrypt   = "bcrypt"
+)
+
+// PasswordHasher adalah satu algoritma hash password. Algoritma dan
+// parameternya ikut tersimpan di hash (format PHC untuk argon2id, format
+// modular crypt untuk bcrypt), sehingga hash lama tetap bisa diverifikasi
+// setelah algoritma atau parameter default diganti.
+type PasswordHasher interface {
+	ID() string
+	Hash(password string) (string, error)
+	// Matches menentukan apakah hash dibuat dengan algoritma ini
+	Matches(hashedPassword string) bool
+	Verify(password, hashedPassword string) (bool, error)
+	// NeedsRehash bernilai true jika hash dibuat dengan parameter yang berbeda
+	// dari parameter hasher ini
+	NeedsRehash(hashedPassword string) bool
+}
+
+// Argon2Params adalah parameter argon2id. Memory dalam KiB.
+type Argon2Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+var DefaultArgon2Params = Argon2Params{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+var (
+	passwordHasherMu sync.RWMutex
+	// Hash baru dibuat dengan passwordHasher; passwordHashers dipakai untuk
+	// memverifikasi hash dengan algoritma apa pun yang didukung
+	passwordHasher  PasswordHasher = &argon2idHasher{params: DefaultArgon2Params}
+	passwordHashers                = []PasswordHasher{passwordHasher, &bcryptHasher{cost: bcrypt.DefaultCost}}
+)
+
+// InitPasswordHasher memilih algoritma dan parameter hash password dari
+// konfigurasi. Hash lama dengan algoritma atau parameter lain tetap bisa
+// diverifikasi dan akan di-hash ulang saat user berhasil login.
+func InitPasswordHasher(cfg *configs.Config) error {
+	// Batasan parameter mengikuti RFC 9106: memory minimal 8*p KiB
+	if cfg.Argon2Parallelism < 1 || cfg.Argon2Parallelism > 255 ||
+		cfg.Argon2Iterations < 1 || cfg.Argon2Memory < 8*cfg.Argon2Parallelism {
+		return errors.New("invalid argon2id parameters")
 	}
-	return string(hashedBytes), nil
+	argon := &argon2idHasher{params: Argon2Params{
+		Memory:      uint32(cfg.Argon2Memory),
+		Iterations:  uint32(cfg.Argon2Iterations),
+		Parallelism: uint8(cfg.Argon2Parallelism),
+		SaltLength:  DefaultArgon2Params.SaltLength,
+		KeyLength:   DefaultArgon2Params.KeyLength,
+	}}
+
+	if cfg.BcryptCost < bcrypt.MinCost || cfg.BcryptCost > bcrypt.MaxCost {
+		return fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+	bcryptH := &bcryptHasher{cost: cfg.BcryptCost}
+
+	var current PasswordHasher
+	switch cfg.PasswordHashAlgorithm {
+	case PasswordHashArgon2id:
+		current = argon
+	case PasswordHashBcrypt:
+		current = bcryptH
+	default:
+		return fmt.Errorf("unsupported password hash algorithm %q", cfg.PasswordHashAlgorithm)
+	}
+
+	passwordHasherMu.Lock()
+	passwordHasher = current
+	passwordHashers = []PasswordHasher{argon, bcryptH}
+	passwordHasherMu.Unlock()
+	return nil
+}
+
+func currentPasswordHasher() (PasswordHasher, []PasswordHasher) {
+	passwordHasherMu.RLock()
+	defer passwordHasherMu.RUnlock()
+	return passwordHasher, passwordHashers
 }
 
-// CheckPassword memverifikasi password plaintext dengan hash yang tersimpan
+// HashPassword mengubah password plaintext menjadi hash dengan algoritma yang
+// sedang aktif
+func HashPassword(password string) (string, error) {
+	current, _ := currentPasswordHasher()
+	return current.Hash(password)
+}
+
+// CheckPassword memverifikasi password plaintext dengan hash yang tersimpan.
+// Algoritma dikenali dari format hash.
 func CheckPassword(password, hashedPassword string) bool {
 	// Validasi input
 	if len(hashedPassword) == 0 {
@@ -22,14 +121,160 @@ func CheckPassword(password, hashedPassword string) bool {
 		return false
 	}
 
-	// Bandingkan password
+	_, hashers := currentPasswordHasher()
+	for _, hasher := range hashers {
+		if !hasher.Matches(hashedPassword) {
+			continue
+		}
+
+		ok, err := hasher.Verify(password, hashedPassword)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"algorithm": hasher.ID(),
+				"error":     err,
+			}).Debug("Password comparison failed")
+		}
+		return ok
+	}
+
+	logrus.Warn("Unknown password hash format")
+	return false
+}
+
+// PasswordNeedsRehash menentukan apakah hash perlu dibuat ulang karena
+// algoritma atau parameternya berbeda dari konfigurasi saat ini
+func PasswordNeedsRehash(hashedPassword string) bool {
+	current, _ := currentPasswordHasher()
+	if !current.Matches(hashedPassword) {
+		return true
+	}
+	return current.NeedsRehash(hashedPassword)
+}
+
+type bcryptHasher struct {
+	cost int
+}
+
+func (h *bcryptHasher) ID() string {
+	return PasswordHashBcrypt
+}
+
+func (h *bcryptHasher) Hash(password string) (string, error) {
+	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashedBytes), nil
+}
+
+func (h *bcryptHasher) Matches(hashedPassword string) bool {
+	return strings.HasPrefix(hashedPassword, "$2a$") ||
+		strings.HasPrefix(hashedPassword, "$2b$") ||
+		strings.HasPrefix(hashedPassword, "$2y$")
+}
+
+func (h *bcryptHasher) Verify(password, hashedPassword string) (bool, error) {
 	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
-			"error": err,
-		}).Debug("Password comparison failed")
-		return false
+		return false, err
+	}
+	return true, nil
+}
+
+func (h *bcryptHasher) NeedsRehash(hashedPassword string) bool {
+	cost, err := bcrypt.Cost([]byte(hashedPassword))
+	return err != nil || cost != h.cost
+}
+
+// argon2idHasher menyimpan hash dalam format PHC:
+// $argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<hash>
+type argon2idHasher struct {
+	params Argon2Params
+}
+
+func (h *argon2idHasher) ID() string {
+	return PasswordHashArgon2id
+}
+
+func (h *argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, h.params.KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		h.params.Memory, h.params.Iterations, h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (h *argon2idHasher) Matches(hashedPassword string) bool {
+	return strings.HasPrefix(hashedPassword, "$argon2id$")
+}
+
+func (h *argon2idHasher) Verify(password, hashedPassword string) (bool, error) {
+	params, salt, key, err := decodeArgon2idHash(hashedPassword)
+	if err != nil {
+		return false, err
+	}
+
+	other := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+func (h *argon2idHasher) NeedsRehash(hashedPassword string) bool {
+	params, salt, _, err := decodeArgon2idHash(hashedPassword)
+	if err != nil {
+		return true
+	}
+	return params.Memory != h.params.Memory ||
+		params.Iterations != h.params.Iterations ||
+		params.Parallelism != h.params.Parallelism ||
+		params.KeyLength != h.params.KeyLength ||
+		uint32(len(salt)) != h.params.SaltLength
+}
+
+func decodeArgon2idHash(hashedPassword string) (Argon2Params, []byte, []byte, error) {
+	var params Argon2Params
+
+	parts := strings.Split(hashedPassword, "$")
+	if len(parts) != 6 || parts[1] != PasswordHashArgon2id {
+		return params, nil, nil, errors.New("invalid argon2id hash format")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2id version: %w", err)
+	}
+	if version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2id version %d", version)
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2id parameters: %w", err)
+	}
+	// argon2.IDKey panic untuk parameter di bawah batas minimum
+	if params.Parallelism < 1 || params.Iterations < 1 || params.Memory < 8*uint32(params.Parallelism) {
+		return params, nil, nil, errors.New("invalid argon2id parameters")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2id salt: %w", err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2id hash: %w", err)
+	}
+	if len(key) == 0 {
+		return params, nil, nil, errors.New("invalid argon2id hash: empty key")
 	}
 
-	return true
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	return params, salt, key, nil
 }
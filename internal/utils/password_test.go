@@ -0,0 +1,243 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anieswahdie1/ara-medika-api.git/internal/configs"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testArgon2Params membuat test tetap cepat; parameter produksi diuji lewat
+// vektor referensi
+var testArgon2Params = Argon2Params{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestArgon2idReferenceVector(t *testing.T) {
+	// Vektor dari implementasi referensi argon2 (password "password", salt
+	// "somesalt")
+	const hash = "$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc"
+
+	hasher := &argon2idHasher{params: testArgon2Params}
+	ok, err := hasher.Verify("password", hash)
+	if err != nil || !ok {
+		t.Fatalf("Verify(reference) = %v, %v; want true", ok, err)
+	}
+	if ok, _ := hasher.Verify("Password", hash); ok {
+		t.Error("Verify accepted a wrong password")
+	}
+}
+
+func TestArgon2idHashRoundTrip(t *testing.T) {
+	hasher := &argon2idHasher{params: testArgon2Params}
+
+	hash, err := hasher.Hash("s3cret-Passw0rd!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Errorf("unexpected hash format %q", hash)
+	}
+	if !hasher.Matches(hash) {
+		t.Error("Matches = false for own hash")
+	}
+
+	other, err := hasher.Hash("s3cret-Passw0rd!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == hash {
+		t.Error("two hashes of the same password share a salt")
+	}
+
+	tests := []struct {
+		password string
+		want     bool
+	}{
+		{"s3cret-Passw0rd!", true},
+		{"s3cret-Passw0rd", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		ok, err := hasher.Verify(tt.password, hash)
+		if err != nil {
+			t.Fatalf("Verify(%q): %v", tt.password, err)
+		}
+		if ok != tt.want {
+			t.Errorf("Verify(%q) = %v, want %v", tt.password, ok, tt.want)
+		}
+	}
+}
+
+func TestDecodeArgon2idHashRejectsMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		hash string
+	}{
+		{"too few parts", "$argon2id$v=19$m=64,t=1,p=1$c29tZXNhbHQ"},
+		{"wrong algorithm", "$argon2i$v=19$m=64,t=1,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc"},
+		{"unsupported version", "$argon2id$v=16$m=64,t=1,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc"},
+		{"bad parameters", "$argon2id$v=19$m=x,t=1,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc"},
+		{"zero parallelism", "$argon2id$v=19$m=64,t=1,p=0$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc"},
+		{"zero iterations", "$argon2id$v=19$m=64,t=0,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc"},
+		{"memory below 8*p", "$argon2id$v=19$m=8,t=1,p=2$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc"},
+		{"bad salt", "$argon2id$v=19$m=64,t=1,p=1$!!!$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc"},
+		{"bad key", "$argon2id$v=19$m=64,t=1,p=1$c29tZXNhbHQ$!!!"},
+		{"empty key", "$argon2id$v=19$m=64,t=1,p=1$c29tZXNhbHQ$"},
+	}
+
+	hasher := &argon2idHasher{params: testArgon2Params}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, _, err := decodeArgon2idHash(tt.hash); err == nil {
+				t.Fatal("decodeArgon2idHash accepted a malformed hash")
+			}
+			if ok, err := hasher.Verify("password", tt.hash); ok || err == nil {
+				t.Errorf("Verify = %v, %v; want false with error", ok, err)
+			}
+			if !hasher.NeedsRehash(tt.hash) {
+				t.Error("NeedsRehash = false for a malformed hash")
+			}
+		})
+	}
+}
+
+func TestArgon2idNeedsRehash(t *testing.T) {
+	hash, err := (&argon2idHasher{params: testArgon2Params}).Hash("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(p *Argon2Params)
+		want   bool
+	}{
+		{"same parameters", func(p *Argon2Params) {}, false},
+		{"memory changed", func(p *Argon2Params) { p.Memory = 128 }, true},
+		{"iterations changed", func(p *Argon2Params) { p.Iterations = 2 }, true},
+		{"parallelism changed", func(p *Argon2Params) { p.Parallelism = 2 }, true},
+		{"key length changed", func(p *Argon2Params) { p.KeyLength = 64 }, true},
+		{"salt length changed", func(p *Argon2Params) { p.SaltLength = 32 }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := testArgon2Params
+			tt.modify(&params)
+			if got := (&argon2idHasher{params: params}).NeedsRehash(hash); got != tt.want {
+				t.Errorf("NeedsRehash = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckPasswordAndRehashAcrossAlgorithms(t *testing.T) {
+	bcryptHash, err := (&bcryptHasher{cost: bcrypt.MinCost}).Hash("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	argonHash, err := (&argon2idHasher{params: DefaultArgon2Params}).Hash("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		password   string
+		hash       string
+		wantMatch  bool
+		wantRehash bool
+	}{
+		{"bcrypt hash", "password", bcryptHash, true, true},
+		{"bcrypt wrong password", "wrong", bcryptHash, false, true},
+		{"argon2id hash with default parameters", "password", argonHash, true, false},
+		{"argon2id wrong password", "wrong", argonHash, false, false},
+		{"unknown format", "password", "plaintext", false, true},
+		{"empty hash", "password", "", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CheckPassword(tt.password, tt.hash); got != tt.wantMatch {
+				t.Errorf("CheckPassword = %v, want %v", got, tt.wantMatch)
+			}
+			if got := PasswordNeedsRehash(tt.hash); got != tt.wantRehash {
+				t.Errorf("PasswordNeedsRehash = %v, want %v", got, tt.wantRehash)
+			}
+		})
+	}
+}
+
+func TestBcryptNeedsRehash(t *testing.T) {
+	hash, err := (&bcryptHasher{cost: bcrypt.MinCost}).Hash("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if (&bcryptHasher{cost: bcrypt.MinCost}).NeedsRehash(hash) {
+		t.Error("NeedsRehash = true for the same cost")
+	}
+	if !(&bcryptHasher{cost: bcrypt.MinCost + 1}).NeedsRehash(hash) {
+		t.Error("NeedsRehash = false for a different cost")
+	}
+	if !(&bcryptHasher{cost: bcrypt.MinCost}).NeedsRehash("$2a$garbage") {
+		t.Error("NeedsRehash = false for a malformed hash")
+	}
+}
+
+func TestInitPasswordHasher(t *testing.T) {
+	valid := configs.Config{
+		PasswordHashAlgorithm: PasswordHashArgon2id,
+		Argon2Memory:          64,
+		Argon2Iterations:      1,
+		Argon2Parallelism:     1,
+		BcryptCost:            bcrypt.MinCost,
+	}
+
+	tests := []struct {
+		name       string
+		modify     func(cfg *configs.Config)
+		wantErr    bool
+		wantPrefix string
+	}{
+		{name: "argon2id", modify: func(cfg *configs.Config) {}, wantPrefix: "$argon2id$"},
+		{name: "bcrypt", modify: func(cfg *configs.Config) { cfg.PasswordHashAlgorithm = PasswordHashBcrypt }, wantPrefix: "$2a$"},
+		{name: "unknown algorithm", modify: func(cfg *configs.Config) { cfg.PasswordHashAlgorithm = "md5" }, wantErr: true},
+		{name: "zero parallelism", modify: func(cfg *configs.Config) { cfg.Argon2Parallelism = 0 }, wantErr: true},
+		{name: "parallelism above 255", modify: func(cfg *configs.Config) { cfg.Argon2Parallelism = 256; cfg.Argon2Memory = 8 * 256 }, wantErr: true},
+		{name: "zero iterations", modify: func(cfg *configs.Config) { cfg.Argon2Iterations = 0 }, wantErr: true},
+		{name: "memory below 8*p", modify: func(cfg *configs.Config) { cfg.Argon2Parallelism = 16 }, wantErr: true},
+		{name: "bcrypt cost too low", modify: func(cfg *configs.Config) { cfg.BcryptCost = bcrypt.MinCost - 1 }, wantErr: true},
+		{name: "bcrypt cost too high", modify: func(cfg *configs.Config) { cfg.BcryptCost = bcrypt.MaxCost + 1 }, wantErr: true},
+	}
+
+	current, hashers := currentPasswordHasher()
+	t.Cleanup(func() {
+		passwordHasherMu.Lock()
+		passwordHasher, passwordHashers = current, hashers
+		passwordHasherMu.Unlock()
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			tt.modify(&cfg)
+			err := InitPasswordHasher(&cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("InitPasswordHasher err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			hash, err := HashPassword("password")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(hash, tt.wantPrefix) {
+				t.Errorf("hash %q does not start with %q", hash, tt.wantPrefix)
+			}
+			if !CheckPassword("password", hash) || PasswordNeedsRehash(hash) {
+				t.Error("fresh hash does not verify or needs a rehash")
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type TokenStatus int
+
+const (
+	TokenActive TokenStatus = iota
+	TokenBlacklisted
+	TokenSessionRevoked
+)
+
+// SessionKey adalah key hash Redis yang menyimpan metadata session user
+func SessionKey(userID uint, sessionID string) string {
+	return fmt.Sprintf("user:%d:session:%s", userID, sessionID)
+}
+
+// UserSessionsKey adalah sorted set berisi ID session aktif milik user dengan
+// score last_seen_at (unix), dipakai sebagai index agar tidak perlu KEYS/SCAN.
+func UserSessionsKey(userID uint) string {
+	return fmt.Sprintf("user:%d:sessions", userID)
+}
+
+// UserImpersonationsKey adalah set berisi ID session impersonasi atas user.
+// Session ini sengaja tidak masuk UserSessionsKey agar tidak muncul di daftar
+// perangkat user, tetapi tetap ikut dicabut saat semua session user dicabut.
+func UserImpersonationsKey(userID uint) string {
+	return fmt.Sprintf("user:%d:impersonations", userID)
+}
+
+// ImpersonatorSessionsKey adalah set berisi "<target ID>:<session ID>" untuk
+// setiap impersonasi yang dimulai oleh actor, agar ikut dicabut saat semua
+// session actor dicabut.
+func ImpersonatorSessionsKey(actorID uint) string {
+	return fmt.Sprintf("user:%d:impersonating", actorID)
+}
+
+// BlacklistKey adalah key blacklist untuk access token dengan jti tertentu.
+// Blacklist dan daftar token per session hanya menyimpan hash jti, bukan JWT
+// mentah.
+func BlacklistKey(tokenID string) string {
+	return "blacklist:" + HashToken(tokenID)
+}
+
+// checkTokenScript memeriksa blacklist dan keberadaan session dalam satu round
+// trip, lalu memperbarui last_seen_at jika token masih aktif. Session yang
+// sudah dicabut tidak dibuat ulang karena HSET hanya dijalankan jika key ada.
+//
+// KEYS[1] = blacklist:<sha256(jti)>, KEYS[2] = user:<id>:session:<sid>,
+// KEYS[3] = user:<id>:sessions
+// ARGV[1] = waktu sekarang (unix), ARGV[2] = session ID, ARGV[3] = "1" jika
+// last_seen_at perlu diperbarui
+var checkTokenScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return 1
+end
+if redis.call('EXISTS', KEYS[2]) == 0 then
+	return 2
+end
+if ARGV[3] == '1' then
+	redis.call('HSET', KEYS[2], 'last_seen_at', ARGV[1])
+	redis.call('ZADD', KEYS[3], 'XX', ARGV[1], ARGV[2])
+end
+return 0
+`)
+
+// CheckTokenStatus memastikan access token belum di-blacklist dan session
+// pemiliknya masih aktif. Jika touch bernilai true, aktivitas terakhir session
+// ikut dicatat.
+func CheckTokenStatus(ctx context.Context, redisClient *redis.Client, claims *JWTClaims, touch bool) (TokenStatus, error) {
+	touchFlag := "0"
+	if touch {
+		touchFlag = "1"
+	}
+
+	result, err := checkTokenScript.Run(ctx, redisClient,
+		[]string{
+			BlacklistKey(claims.ID),
+			SessionKey(claims.UserID, claims.FamilyID),
+			UserSessionsKey(claims.UserID),
+		},
+		time.Now().Unix(), claims.FamilyID, touchFlag,
+	).Int()
+	if err != nil {
+		return TokenActive, err
+	}
+
+	return TokenStatus(result), nil
+}
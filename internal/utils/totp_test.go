@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret adalah secret SHA1 dari lampiran B RFC 6238
+// ("12345678901234567890") dalam base32
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// Vektor RFC 6238 berisi kode 8 digit; kode 6 digit adalah 6 digit terakhirnya
+var rfc6238Vectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestTOTPCodeRFC6238(t *testing.T) {
+	for _, tt := range rfc6238Vectors {
+		got, err := TOTPCode(rfc6238Secret, tt.unix/TOTPPeriod)
+		if err != nil {
+			t.Fatalf("TOTPCode(%d): %v", tt.unix, err)
+		}
+		if got != tt.code {
+			t.Errorf("TOTPCode(%d) = %s, want %s", tt.unix, got, tt.code)
+		}
+	}
+}
+
+func TestTOTPCodeInvalidSecret(t *testing.T) {
+	if _, err := TOTPCode("not base32!", 1); err == nil {
+		t.Error("TOTPCode accepted an invalid secret")
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	const currentStep = 1111111111 / TOTPPeriod
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		now      time.Time
+		wantStep int64
+		wantOK   bool
+	}{
+		{"current step", rfc6238Secret, "050471", now, currentStep, true},
+		{"surrounding whitespace", rfc6238Secret, " 050471\n", now, currentStep, true},
+		{"lowercase secret", "gezdgnbvgy3tqojqgezdgnbvgy3tqojq", "050471", now, currentStep, true},
+		{"previous step within skew", rfc6238Secret, "050471", now.Add(TOTPPeriod * time.Second), currentStep, true},
+		{"next step within skew", rfc6238Secret, "050471", now.Add(-TOTPPeriod * time.Second), currentStep, true},
+		{"outside skew", rfc6238Secret, "050471", now.Add(2 * TOTPPeriod * time.Second), 0, false},
+		{"wrong code", rfc6238Secret, "050472", now, 0, false},
+		{"too short", rfc6238Secret, "05047", now, 0, false},
+		{"8 digit RFC code", rfc6238Secret, "14050471", now, 0, false},
+		{"invalid secret", "not base32!", "050471", now, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := ValidateTOTP(tt.secret, tt.code, tt.now)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("ValidateTOTP = (%d, %v), want (%d, %v)", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secret) != 32 {
+		t.Errorf("secret length = %d, want 32 base32 characters", len(secret))
+	}
+	if _, err := TOTPCode(secret, 0); err != nil {
+		t.Errorf("generated secret is not usable: %v", err)
+	}
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri, err := url.Parse(TOTPProvisioningURI("Ara Medika", "budi@example.com", rfc6238Secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/Ara Medika:budi@example.com" {
+		t.Errorf("unexpected URI %s", uri)
+	}
+
+	query := uri.Query()
+	want := map[string]string{
+		"secret":    rfc6238Secret,
+		"issuer":    "Ara Medika",
+		"algorithm": "SHA1",
+		"digits":    "6",
+		"period":    "30",
+	}
+	for key, value := range want {
+		if got := query.Get(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+-- migrations/002_add_mfa.up.sql
+ALTER TABLE users
+    ADD COLUMN mfa_secret TEXT,
+    ADD COLUMN mfa_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN mfa_enabled_at TIMESTAMP;
+
+CREATE TABLE user_recovery_codes (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_user_recovery_codes_user_id ON user_recovery_codes(user_id);
@@ -0,0 +1,21 @@
+-- migrations/005_create_user_invitations.up.sql
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP;
+
+-- User yang sudah ada dibuat langsung oleh admin, anggap emailnya terverifikasi
+UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL;
+
+CREATE TABLE user_invitations (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL,
+    invited_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP,
+    sent_count INTEGER NOT NULL DEFAULT 1,
+    last_sent_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_user_invitations_user_id ON user_invitations(user_id);
+CREATE INDEX idx_user_invitations_expires_at ON user_invitations(expires_at);
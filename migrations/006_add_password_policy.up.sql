@@ -0,0 +1,18 @@
+-- migrations/006_add_password_policy.up.sql
+ALTER TABLE users ADD COLUMN password_changed_at TIMESTAMP;
+
+-- Umur password lama dihitung sejak migrasi agar user tidak langsung dipaksa
+-- mengganti password
+UPDATE users SET password_changed_at = CURRENT_TIMESTAMP WHERE password_changed_at IS NULL;
+
+CREATE TABLE password_histories (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    password_hash TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_password_histories_user_id ON password_histories(user_id, created_at);
+
+INSERT INTO password_histories (user_id, password_hash)
+SELECT id, password FROM users WHERE deleted_at IS NULL;
@@ -0,0 +1,60 @@
+-- migrations/007_create_roles_permissions.up.sql
+CREATE TABLE roles (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) UNIQUE NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    is_system BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE permissions (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) UNIQUE NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE role_permissions (
+    role_id INTEGER NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    permission_id INTEGER NOT NULL REFERENCES permissions(id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+CREATE INDEX idx_role_permissions_permission_id ON role_permissions(permission_id);
+
+-- Role bawaan; is_system mencegah role ini dihapus
+INSERT INTO roles (name, description, is_system) VALUES
+    ('super_admin', 'Full access, including role management and impersonation', TRUE),
+    ('admin', 'Manages users, sessions, API keys and invitations', TRUE),
+    ('user', 'Regular staff account', TRUE);
+
+INSERT INTO permissions (name, description) VALUES
+    ('users:read', 'List and view user accounts'),
+    ('users:write', 'Create, update and unlock user accounts'),
+    ('sessions:manage', 'View and revoke sessions of other users'),
+    ('api_keys:manage', 'Create, list and revoke API keys'),
+    ('audit_logs:read', 'Read the audit trail'),
+    ('invitations:manage', 'Invite users and resend invitations'),
+    ('roles:manage', 'Manage roles and their permissions'),
+    ('patients:read', 'View patient records'),
+    ('patients:write', 'Create and update patient records');
+
+-- super_admin mendapat semua permission
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p
+WHERE r.name = 'super_admin';
+
+-- admin mendapat akses yang sama seperti sebelum permission diperkenalkan
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name IN (
+    'users:read', 'users:write', 'sessions:manage', 'api_keys:manage',
+    'audit_logs:read', 'invitations:manage'
+)
+WHERE r.name = 'admin';
+
+-- Kolom role tidak lagi memakai enum sehingga role baru cukup ditambahkan
+-- ke tabel roles
+ALTER TABLE users ALTER COLUMN role TYPE VARCHAR(50) USING role::text;
+ALTER TABLE users ADD CONSTRAINT fk_users_role FOREIGN KEY (role) REFERENCES roles(name);
+DROP TYPE role;
@@ -0,0 +1,88 @@
+-- migrations/008_restructure_menus.up.sql
+-- Tabel menus sebelumnya dibuat manual; pastikan strukturnya ada sebelum
+-- diubah
+CREATE TABLE IF NOT EXISTS menus (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(100),
+    name VARCHAR(255),
+    path VARCHAR(255),
+    icons JSON,
+    has_child BOOLEAN DEFAULT FALSE,
+    child_menus TEXT,
+    priority INTEGER DEFAULT 0,
+    can_access_by TEXT
+);
+
+ALTER TABLE menus
+    ADD COLUMN parent_id INTEGER REFERENCES menus(id) ON DELETE CASCADE,
+    ADD COLUMN created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+
+ALTER TABLE menus ALTER COLUMN icons TYPE JSONB USING COALESCE(icons::jsonb, '{}'::jsonb);
+ALTER TABLE menus ALTER COLUMN icons SET DEFAULT '{}'::jsonb;
+ALTER TABLE menus ALTER COLUMN icons SET NOT NULL;
+UPDATE menus SET path = '' WHERE path IS NULL;
+UPDATE menus SET priority = 0 WHERE priority IS NULL;
+ALTER TABLE menus ALTER COLUMN code SET NOT NULL;
+ALTER TABLE menus ALTER COLUMN name SET NOT NULL;
+ALTER TABLE menus ALTER COLUMN path SET NOT NULL;
+ALTER TABLE menus ALTER COLUMN path SET DEFAULT '';
+ALTER TABLE menus ALTER COLUMN priority SET NOT NULL;
+
+CREATE TABLE role_menus (
+    role_id INTEGER NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    menu_id INTEGER NOT NULL REFERENCES menus(id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, menu_id)
+);
+
+CREATE INDEX idx_role_menus_menu_id ON role_menus(menu_id);
+
+-- can_access_by (daftar role dipisah koma) dipindah ke role_menus
+INSERT INTO role_menus (role_id, menu_id)
+SELECT DISTINCT r.id, m.id
+FROM menus m
+CROSS JOIN LATERAL unnest(string_to_array(COALESCE(m.can_access_by, ''), ',')) AS access(role_name)
+JOIN roles r ON r.name = trim(access.role_name);
+
+-- child_menus (JSON array berisi code, name dan priority) dipindah menjadi
+-- baris menu dengan parent_id. Submenu mewarisi akses role menu induknya.
+CREATE TEMPORARY TABLE legacy_child_menus AS
+SELECT m.id AS parent_id,
+       child->>'code' AS code,
+       COALESCE(child->>'name', child->>'code') AS name,
+       COALESCE(NULLIF(child->>'priority', ''), '0')::INTEGER AS priority
+FROM menus m
+CROSS JOIN LATERAL jsonb_array_elements(m.child_menus::jsonb) AS child
+WHERE m.child_menus IS NOT NULL
+  AND left(trim(m.child_menus), 1) = '['
+  AND child->>'code' IS NOT NULL;
+
+INSERT INTO menus (parent_id, code, name, path, priority)
+SELECT parent_id, code, name, '', priority FROM legacy_child_menus;
+
+INSERT INTO role_menus (role_id, menu_id)
+SELECT rm.role_id, child.id
+FROM menus child
+JOIN role_menus rm ON rm.menu_id = child.parent_id
+WHERE child.parent_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+DROP TABLE legacy_child_menus;
+
+ALTER TABLE menus
+    DROP COLUMN has_child,
+    DROP COLUMN child_menus,
+    DROP COLUMN can_access_by;
+
+-- Kode menu unik per induk sehingga submenu dari menu berbeda boleh memakai
+-- kode yang sama
+CREATE UNIQUE INDEX idx_menus_parent_code ON menus (COALESCE(parent_id, 0), code);
+CREATE INDEX idx_menus_parent_id ON menus(parent_id);
+
+-- Pengelolaan menu hanya untuk super_admin
+INSERT INTO permissions (name, description) VALUES
+    ('menus:manage', 'Create, update, reorder and delete menus');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p
+WHERE r.name = 'super_admin' AND p.name = 'menus:manage';
@@ -0,0 +1,4 @@
+-- migrations/009_add_user_list_indexes.up.sql
+-- Index untuk urutan default (-created_at) dan paginasi keyset daftar user
+CREATE INDEX IF NOT EXISTS idx_users_created_at_id ON users (created_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_users_role ON users (role) WHERE deleted_at IS NULL;
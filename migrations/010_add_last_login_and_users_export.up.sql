@@ -0,0 +1,10 @@
+-- migrations/010_add_last_login_and_users_export.up.sql
+-- last_login_at diisi setiap login berhasil dan ikut diekspor di laporan user
+ALTER TABLE users ADD COLUMN IF NOT EXISTS last_login_at TIMESTAMP;
+
+INSERT INTO permissions (name, description) VALUES
+    ('users:export', 'Export user lists as CSV, XLSX or PDF');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p
+WHERE r.name IN ('super_admin', 'admin') AND p.name = 'users:export';
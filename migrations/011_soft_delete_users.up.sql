@@ -0,0 +1,15 @@
+-- migrations/011_soft_delete_users.up.sql
+-- Email hanya unik di antara user yang belum dihapus, sehingga alamat user yang
+-- ada di trash bisa dipakai lagi
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_not_deleted ON users (email) WHERE deleted_at IS NULL;
+
+-- Index untuk daftar trash dan purge berkala
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at_id ON users (deleted_at, id) WHERE deleted_at IS NOT NULL;
+
+INSERT INTO permissions (name, description) VALUES
+    ('users:purge', 'Permanently delete users from the trash');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p
+WHERE r.name = 'super_admin' AND p.name = 'users:purge';
@@ -0,0 +1,36 @@
+-- migrations/012_create_staff_profiles.up.sql
+-- Data profesi tenaga kesehatan, satu profil per user. Nomor STR, SIP dan NIP
+-- disimpan dalam bentuk ternormalisasi; string kosong berarti belum ada.
+-- str_expires_at kosong berarti STR berlaku seumur hidup.
+CREATE TABLE staff_profiles (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    profession VARCHAR(30) NOT NULL,
+    specialty VARCHAR(100) NOT NULL DEFAULT '',
+    str_number VARCHAR(32) NOT NULL DEFAULT '',
+    str_expires_at DATE,
+    str_alerted_at TIMESTAMP,
+    sip_number VARCHAR(100) NOT NULL DEFAULT '',
+    sip_expires_at DATE,
+    sip_alerted_at TIMESTAMP,
+    nip VARCHAR(18) NOT NULL DEFAULT '',
+    phone VARCHAR(20) NOT NULL DEFAULT '',
+    signature_image BYTEA,
+    signature_content_type VARCHAR(50) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_staff_profiles_str_number ON staff_profiles (str_number) WHERE str_number <> '';
+CREATE UNIQUE INDEX idx_staff_profiles_sip_number ON staff_profiles (sip_number) WHERE sip_number <> '';
+CREATE UNIQUE INDEX idx_staff_profiles_nip ON staff_profiles (nip) WHERE nip <> '';
+CREATE INDEX idx_staff_profiles_str_expires_at ON staff_profiles (str_expires_at) WHERE str_number <> '';
+CREATE INDEX idx_staff_profiles_sip_expires_at ON staff_profiles (sip_expires_at) WHERE sip_number <> '';
+
+INSERT INTO permissions (name, description) VALUES
+    ('staff_profiles:read', 'View staff professional profiles and licenses'),
+    ('staff_profiles:write', 'Create, update and delete staff professional profiles');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p
+WHERE r.name IN ('super_admin', 'admin') AND p.name IN ('staff_profiles:read', 'staff_profiles:write');
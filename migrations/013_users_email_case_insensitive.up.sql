@@ -0,0 +1,7 @@
+-- migrations/013_users_email_case_insensitive.up.sql
+-- Email unik tanpa membedakan huruf besar/kecil, sama seperti pengecekan
+-- email saat import dan undangan massal. Migrasi ini gagal jika masih ada dua
+-- user aktif dengan email yang hanya beda huruf besar/kecil; gabungkan atau
+-- hapus salah satunya terlebih dahulu.
+DROP INDEX IF EXISTS idx_users_email_not_deleted;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_lower_not_deleted ON users (LOWER(email)) WHERE deleted_at IS NULL;
@@ -1,38 +1,77 @@
 package validators
 
 import (
+	"regexp"
+	"strings"
+	"time"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/go-playground/validator/v10"
 )
 
 var Validate *validator.Validate
 
+// PasswordRules menentukan aturan validasi strong_password
+type PasswordRules struct {
+	MinLength  int
+	MinClasses int
+}
+
+var passwordRules = PasswordRules{MinLength: 8, MinClasses: 4}
+
+// roleExists memeriksa tag validasi role. Default-nya hanya role bawaan;
+// aplikasi menggantinya dengan pengecekan ke tabel roles.
+var roleExists = func(role string) bool {
+	return role == "super_admin" || role == "admin" || role == "user"
+}
+
 func Init() {
 	Validate = validator.New()
 	registerCustomValidations()
 }
 
+// SetPasswordRules mengganti aturan strong_password sesuai konfigurasi
+func SetPasswordRules(rules PasswordRules) {
+	passwordRules = rules
+}
+
+// SetRoleValidator mengganti pengecekan tag role, misalnya agar role yang
+// dibuat lewat API ikut dianggap valid
+func SetRoleValidator(exists func(role string) bool) {
+	roleExists = exists
+}
+
 // Custom Validation Func
 func registerCustomValidations() {
 	// Validasi custom untuk role
 	_ = Validate.RegisterValidation("role", validateRole)
 	_ = Validate.RegisterValidation("strong_password", validateStrongPassword)
+	_ = Validate.RegisterValidation("str_number", validateSTRNumber)
+	_ = Validate.RegisterValidation("sip_number", validateSIPNumber)
+	_ = Validate.RegisterValidation("nip", validateNIP)
+	_ = Validate.RegisterValidation("phone_id", validatePhoneID)
 }
 
 func validateRole(fl validator.FieldLevel) bool {
-	role := fl.Field().String()
-	return role == "super_admin" || role == "admin" || role == "user"
+	return roleExists(fl.Field().String())
 }
 
 func validateStrongPassword(fl validator.FieldLevel) bool {
 	password := fl.Field().String()
 
-	// Minimal 8 karakter, mengandung angka, huruf besar dan kecil
-	if len(password) < 8 {
+	// Default minimal 8 karakter, mengandung huruf besar, huruf kecil, angka
+	// dan simbol
+	if utf8.RuneCountInString(password) < passwordRules.MinLength {
 		return false
 	}
 
+	return PasswordClasses(password) >= passwordRules.MinClasses
+}
+
+// PasswordClasses menghitung jenis karakter (huruf besar, huruf kecil, angka,
+// simbol) yang ada di password
+func PasswordClasses(password string) int {
 	var (
 		hasUpper   = false
 		hasLower   = false
@@ -53,5 +92,82 @@ func validateStrongPassword(fl validator.FieldLevel) bool {
 		}
 	}
 
-	return hasUpper && hasLower && hasNumber && hasSpecial
+	classes := 0
+	for _, has := range []bool{hasUpper, hasLower, hasNumber, hasSpecial} {
+		if has {
+			classes++
+		}
+	}
+	return classes
+}
+
+// sipNumberPattern menerima nomor SIP yang formatnya berbeda di setiap
+// daerah, misalnya "503/0123/SIP-DU/DPMPTSP/2023"
+var sipNumberPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9./ -]{3,98}[A-Za-z0-9]$`)
+
+// phoneIDPattern adalah nomor telepon Indonesia yang sudah dinormalisasi
+var phoneIDPattern = regexp.MustCompile(`^\+62[2-9][0-9]{7,11}$`)
+
+// validateSTRNumber: STR terdiri dari 16 digit, boleh dipisah spasi, titik
+// atau tanda hubung
+func validateSTRNumber(fl validator.FieldLevel) bool {
+	return isDigits(NormalizeDigits(fl.Field().String()), 16)
+}
+
+func validateSIPNumber(fl validator.FieldLevel) bool {
+	value := strings.TrimSpace(fl.Field().String())
+	return sipNumberPattern.MatchString(value) && strings.ContainsAny(value, "0123456789")
+}
+
+// validateNIP: NIP PNS terdiri dari 18 digit, yaitu tanggal lahir
+// (YYYYMMDD), TMT pengangkatan (YYYYMM), jenis kelamin (1/2) dan nomor urut
+func validateNIP(fl validator.FieldLevel) bool {
+	nip := NormalizeDigits(fl.Field().String())
+	if !isDigits(nip, 18) {
+		return false
+	}
+	if _, err := time.Parse("20060102", nip[:8]); err != nil {
+		return false
+	}
+	if _, err := time.Parse("200601", nip[8:14]); err != nil {
+		return false
+	}
+	return nip[14] == '1' || nip[14] == '2'
+}
+
+func validatePhoneID(fl validator.FieldLevel) bool {
+	return phoneIDPattern.MatchString(NormalizePhone(fl.Field().String()))
+}
+
+// NormalizeDigits membuang spasi, titik dan tanda hubung dari nomor seperti
+// STR dan NIP
+func NormalizeDigits(value string) string {
+	return strings.NewReplacer(" ", "", ".", "", "-", "").Replace(strings.TrimSpace(value))
+}
+
+// NormalizePhone mengubah nomor telepon Indonesia ke format +62, misalnya
+// "0812-3456-7890" menjadi "+6281234567890"
+func NormalizePhone(value string) string {
+	phone := strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "").Replace(strings.TrimSpace(value))
+	switch {
+	case strings.HasPrefix(phone, "+62"):
+		return phone
+	case strings.HasPrefix(phone, "62"):
+		return "+" + phone
+	case strings.HasPrefix(phone, "0"):
+		return "+62" + phone[1:]
+	}
+	return phone
+}
+
+func isDigits(value string, length int) bool {
+	if len(value) != length {
+		return false
+	}
+	for _, char := range value {
+		if char < '0' || char > '9' {
+			return false
+		}
+	}
+	return true
 }